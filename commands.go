@@ -0,0 +1,303 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/renameio"
+	c "github.com/haproxytech/kubernetes-ingress/controller"
+	"github.com/haproxytech/kubernetes-ingress/controller/admin"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// renderTimeout bounds the time needed to get the cluster state when running commands
+const renderTimeout = 2 * time.Minute
+
+const commandsHelp = `
+Commands:
+  version                        show version
+  render                         print HAProxy configuration generated from the cluster state
+  validate                       check that the generated configuration is accepted by HAProxy
+  inspect ingress <ns>/<name>    show how an ingress is configured, as JSON
+  maintenance <operation>        run a maintenance operation of the local controller admin API:
+                                 status, resync, pause, resume, drain or undrain
+
+Commands use the kubeconfig (--kubeconfig or ~/.kube/config) and the HAProxy binary (--program)
+of the local host, files are generated in --config-dir or in a temporary directory.
+The maintenance command is run in the controller container, e.g. with "kubectl exec", it requires
+--admin-port (and --admin-address, --admin-tls-cert when set) and authenticates with the ADMIN_TOKEN
+environment variable or the ServiceAccount token.
+`
+
+var commands = map[string]func(osArgs utils.OSArgs, args []string) error{
+	"version":     versionCommand,
+	"render":      renderCommand,
+	"validate":    validateCommand,
+	"inspect":     inspectCommand,
+	"maintenance": maintenanceCommand,
+}
+
+// runCommand runs the command given as first positional argument
+// instead of the controller, to debug configuration generation locally.
+func runCommand(osArgs utils.OSArgs, args []string) error {
+	command, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command '%s'", args[0])
+	}
+	return command(osArgs, args[1:])
+}
+
+func versionCommand(osArgs utils.OSArgs, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: version")
+	}
+	printVersion(osArgs)
+	return nil
+}
+
+func renderCommand(osArgs utils.OSArgs, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: render")
+	}
+	controller, cleanup, err := render(osArgs)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(controller.Cfg.Env.MainCFGFile)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(content))
+	return nil
+}
+
+func validateCommand(osArgs utils.OSArgs, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: validate")
+	}
+	controller, cleanup, err := render(osArgs)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	var errors []string
+	for _, ingress := range controller.Introspection().List() {
+		for _, path := range ingress.Paths {
+			if path.Error != "" {
+				errors = append(errors, fmt.Sprintf("ingress '%s/%s': %s%s: %s", ingress.Namespace, ingress.Name, path.Host, path.Path, path.Error))
+			}
+		}
+	}
+	if len(errors) != 0 {
+		return fmt.Errorf("ingress paths not served:\n%s", strings.Join(errors, "\n"))
+	}
+	fmt.Println("configuration valid")
+	return nil
+}
+
+// ingressInspection is the output of "inspect ingress"
+type ingressInspection struct {
+	admin.Ingress
+	Annotations map[string]string          `json:"annotations"`
+	Backends    map[string][]admin.Backend `json:"backends"`
+}
+
+func inspectCommand(osArgs utils.OSArgs, args []string) error {
+	if len(args) != 2 || args[0] != "ingress" {
+		return fmt.Errorf("usage: inspect ingress <namespace>/<name>")
+	}
+	parts := strings.Split(args[1], "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("usage: inspect ingress <namespace>/<name>")
+	}
+	controller, cleanup, err := render(osArgs)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	ingress, ok := controller.Introspection().Ingress(parts[0], parts[1])
+	if !ok {
+		return fmt.Errorf("ingress '%s' not found", args[1])
+	}
+	inspection := ingressInspection{
+		Ingress:     ingress,
+		Annotations: ingress.Annotations,
+		Backends:    make(map[string][]admin.Backend),
+	}
+	for _, path := range ingress.Paths {
+		if backends, ok := controller.Introspection().Backends(ingress.Namespace, path.Service); ok {
+			inspection.Backends[path.Service] = backends
+		}
+	}
+	out, err := json.MarshalIndent(inspection, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// render runs a configuration sync against the cluster of the local kubeconfig,
+// cleanup removes generated files unless they are kept in --config-dir.
+// serviceAccountToken is the token used by the maintenance command when ADMIN_TOKEN is not set
+const serviceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// maintenanceOperations are the admin API requests of the maintenance operations
+var maintenanceOperations = map[string]struct {
+	method string
+	path   string
+	body   string
+}{
+	"status":  {http.MethodGet, "/maintenance", ""},
+	"resync":  {http.MethodPost, "/maintenance/resync", ""},
+	"pause":   {http.MethodPut, "/maintenance", `{"paused": true}`},
+	"resume":  {http.MethodPut, "/maintenance", `{"paused": false}`},
+	"drain":   {http.MethodPut, "/maintenance", `{"draining": true}`},
+	"undrain": {http.MethodPut, "/maintenance", `{"draining": false}`},
+}
+
+func maintenanceCommand(osArgs utils.OSArgs, args []string) error {
+	usage := fmt.Errorf("usage: maintenance <status|resync|pause|resume|drain|undrain>")
+	if len(args) != 1 {
+		return usage
+	}
+	op, ok := maintenanceOperations[args[0]]
+	if !ok {
+		return usage
+	}
+	if osArgs.AdminPort == 0 {
+		return fmt.Errorf("maintenance: --admin-port is required")
+	}
+	token := os.Getenv("ADMIN_TOKEN")
+	if token == "" {
+		content, err := ioutil.ReadFile(serviceAccountToken)
+		if err != nil {
+			return fmt.Errorf("maintenance: ADMIN_TOKEN not set: %w", err)
+		}
+		token = strings.TrimSpace(string(content))
+	}
+	client := http.Client{Timeout: 10 * time.Second}
+	scheme := "http"
+	if osArgs.AdminTLSCert != "" {
+		scheme = "https"
+		transport, err := adminTransport(osArgs.AdminTLSCert)
+		if err != nil {
+			return fmt.Errorf("maintenance: %w", err)
+		}
+		client.Transport = transport
+	}
+	host := osArgs.AdminAddress
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.FormatInt(osArgs.AdminPort, 10)), op.path)
+	req, err := http.NewRequest(op.method, url, strings.NewReader(op.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("maintenance %s: status %d: %s", args[0], resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if len(body) != 0 {
+		fmt.Print(string(body))
+	} else {
+		fmt.Printf("maintenance %s done\n", args[0])
+	}
+	return nil
+}
+
+// adminTransport trusts the certificate of the local admin API, whatever its names are,
+// by comparing it with the --admin-tls-cert file.
+func adminTransport(certFile string) (*http.Transport, error) {
+	content, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no certificate in '%s'", certFile)
+	}
+	return &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // the certificate is pinned by VerifyPeerCertificate
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], block.Bytes) {
+				return fmt.Errorf("admin API certificate does not match '%s'", certFile)
+			}
+			return nil
+		},
+	}}, nil
+}
+
+func render(osArgs utils.OSArgs) (controller *c.HAProxyController, cleanup func(), err error) {
+	logger := utils.GetLogger()
+	logger.SetLevel(osArgs.LogLevel.LogLevel)
+	cleanup = func() {}
+	osArgs.External = true
+	if osArgs.CfgDir == "" {
+		var dir string
+		if dir, err = ioutil.TempDir("", "haproxy-ingress"); err != nil {
+			return nil, cleanup, err
+		}
+		cleanup = func() { logger.Error(os.RemoveAll(dir)) }
+		osArgs.CfgDir = filepath.Join(dir, "etc")
+		osArgs.RuntimeDir = filepath.Join(dir, "run")
+	}
+	cfg := setupHAProxyEnv(osArgs)
+	cfg.Env.StateDir = filepath.Join(filepath.Dir(cfg.Env.RuntimeDir), "state")
+	setupEnvDirs(osArgs, &cfg.Env)
+	if osArgs.Program != "" {
+		cfg.Env.HAProxyBinary = osArgs.Program
+	}
+	if err = renameio.WriteFile(cfg.Env.MainCFGFile, haproxyConf, 0755); err != nil {
+		return nil, cleanup, err
+	}
+	controller = &c.HAProxyController{
+		Cfg:     cfg,
+		OSArgs:  osArgs,
+		Version: fmt.Sprintf("%s %s%s", GitTag, GitCommit, GitDirty),
+		Store:   newStore(osArgs),
+	}
+	if err = controller.Render(renderTimeout); err != nil {
+		return nil, cleanup, err
+	}
+	logger.Printf("configuration generated in %s", cfg.Env.CfgDir)
+	return controller, cleanup, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type contextKey string
+
+const userKey contextKey = "user"
+
+// reviewCacheTTL is how long the result of a token or access review is reused,
+// so that the API server is not sent reviews for every admin API request.
+const reviewCacheTTL = 30 * time.Second
+
+// reviewCacheSize is the maximum number of cached reviews
+const reviewCacheSize = 4096
+
+// review is the cached result of a TokenReview or of a SubjectAccessReview
+type review struct {
+	user    authenticationv1.UserInfo
+	ok      bool
+	expires time.Time
+}
+
+// reviewCache caches reviews by hash of the token, and path and verb for access reviews.
+type reviewCache struct {
+	mu      sync.Mutex
+	entries map[string]review
+}
+
+func (c *reviewCache) get(key string) (review, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.entries[key]
+	if !ok || time.Now().After(r.expires) {
+		return review{}, false
+	}
+	return r, true
+}
+
+func (c *reviewCache) set(key string, r review) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.entries) >= reviewCacheSize {
+		for k, cached := range c.entries {
+			if now.After(cached.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= reviewCacheSize {
+			return
+		}
+	}
+	r.expires = now.Add(reviewCacheTTL)
+	c.entries[key] = r
+}
+
+// authenticate validates request bearer token via a Kubernetes TokenReview,
+// then checks via a SubjectAccessReview that the user is allowed to access
+// the requested path with the HTTP method as verb (RBAC nonResourceURLs).
+// Review results are cached for reviewCacheTTL.
+func (s Server) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token == r.Header.Get("Authorization") {
+			writeError(w, http.StatusUnauthorized, errors.New("missing bearer token"))
+			return
+		}
+		tokenHash := sha256.Sum256([]byte(token))
+		tokenKey := hex.EncodeToString(tokenHash[:])
+		authn, err := s.tokenReview(r.Context(), tokenKey, token)
+		if err != nil {
+			logger.Errorf("admin API: token review failed: %s", err)
+			writeError(w, http.StatusInternalServerError, errors.New("unable to authenticate request"))
+			return
+		}
+		if !authn.ok {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid bearer token"))
+			return
+		}
+		user := authn.user
+		verb := strings.ToLower(r.Method)
+		authz, err := s.accessReview(r.Context(), tokenKey+" "+verb+" "+r.URL.Path, user, verb, r.URL.Path)
+		if err != nil {
+			logger.Errorf("admin API: subject access review failed: %s", err)
+			writeError(w, http.StatusInternalServerError, errors.New("unable to authorize request"))
+			return
+		}
+		if !authz.ok {
+			writeError(w, http.StatusForbidden, fmt.Errorf("user '%s' cannot %s %s", user.Username, r.Method, r.URL.Path))
+			return
+		}
+		logger.Debugf("admin API: %s %s by '%s'", r.Method, r.URL.Path, user.Username)
+		next(w, r.WithContext(context.WithValue(r.Context(), userKey, user.Username)))
+	}
+}
+
+// tokenReview returns the user authenticated by the token, via the cache or a TokenReview
+func (s Server) tokenReview(ctx context.Context, key, token string) (review, error) {
+	if cached, ok := s.reviews.get(key); ok {
+		return cached, nil
+	}
+	result, err := s.K8s.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return review{}, err
+	}
+	authn := review{user: result.Status.User, ok: result.Status.Authenticated}
+	s.reviews.set(key, authn)
+	return authn, nil
+}
+
+// accessReview returns if the user can access the path with the verb, via the cache or a SubjectAccessReview
+func (s Server) accessReview(ctx context.Context, key string, user authenticationv1.UserInfo, verb, path string) (review, error) {
+	if cached, ok := s.reviews.get(key); ok {
+		return cached, nil
+	}
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	result, err := s.K8s.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: path,
+				Verb: verb,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return review{}, err
+	}
+	authz := review{user: user, ok: result.Status.Allowed}
+	s.reviews.set(key, authz)
+	return authz, nil
+}
+
+func username(r *http.Request) string {
+	user, _ := r.Context().Value(userKey).(string)
+	return user
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/haproxytech/client-native/v2/models"
+)
+
+// Introspection holds the controller state exposed by the admin API,
+// it is replaced by the controller after each successful sync.
+type Introspection struct {
+	mu        sync.RWMutex
+	ingresses []Ingress
+	backends  map[string][]Backend
+}
+
+// Ingress is an ingress as seen by the controller store
+type Ingress struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Class     string `json:"class,omitempty"`
+	// Ignored ingresses do not match the controller IngressClass or claim hosts owned by other namespaces
+	Ignored bool          `json:"ignored"`
+	Paths   []IngressPath `json:"paths"`
+	// Annotations are the resolved annotation values,
+	// i.e. ingress annotations over ConfigMap and default values.
+	Annotations map[string]string `json:"-"`
+}
+
+// IngressPath is an ingress path and the backend serving it
+type IngressPath struct {
+	Host           string `json:"host,omitempty"`
+	Path           string `json:"path,omitempty"`
+	PathType       string `json:"pathType,omitempty"`
+	Service        string `json:"service"`
+	ServicePort    string `json:"servicePort"`
+	DefaultBackend bool   `json:"defaultBackend,omitempty"`
+	Backend        string `json:"backend,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Backend is the HAProxy backend generated for a service port
+type Backend struct {
+	Name        string          `json:"name"`
+	ServicePort string          `json:"servicePort"`
+	Config      *models.Backend `json:"config"`
+	Servers     []BackendServer `json:"servers"`
+}
+
+// BackendServer is a server slot of a backend, disabled slots have no address
+type BackendServer struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	Port    int64  `json:"port,omitempty"`
+	// Pod behind the server address
+	Pod string `json:"pod,omitempty"`
+}
+
+// Update replaces the exposed state, backends are indexed by "<namespace>/<service>"
+func (i *Introspection) Update(ingresses []Ingress, backends map[string][]Backend) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.ingresses = ingresses
+	i.backends = backends
+}
+
+// List returns the ingresses sorted by namespace and name
+func (i *Introspection) List() []Ingress {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if i.ingresses == nil {
+		return []Ingress{}
+	}
+	return i.ingresses
+}
+
+// Ingress returns the given ingress
+func (i *Introspection) Ingress(namespace, name string) (Ingress, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, ingress := range i.ingresses {
+		if ingress.Namespace == namespace && ingress.Name == name {
+			return ingress, true
+		}
+	}
+	return Ingress{}, false
+}
+
+// Backends returns the backends of the given service
+func (i *Introspection) Backends(namespace, service string) ([]Backend, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	backends, ok := i.backends[namespace+"/"+service]
+	return backends, ok
+}
+
+// ingresses handles "GET /ingresses"
+func (s Server) ingresses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	writeJSON(w, s.Introspection.List())
+}
+
+// ingressAnnotations handles "GET /ingresses/<namespace>/<name>/annotations"
+func (s Server) ingressAnnotations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/ingresses/"), "/")
+	if len(parts) != 3 || parts[2] != "annotations" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("path '%s' not found", r.URL.Path))
+		return
+	}
+	ingress, ok := s.Introspection.Ingress(parts[0], parts[1])
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("ingress '%s/%s' not found", parts[0], parts[1]))
+		return
+	}
+	writeJSON(w, ingress.Annotations)
+}
+
+// serviceBackends handles "GET /services/<namespace>/<name>/backends"
+func (s Server) serviceBackends(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/services/"), "/")
+	if len(parts) != 3 || parts[2] != "backends" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("path '%s' not found", r.URL.Path))
+		return
+	}
+	backends, ok := s.Introspection.Backends(parts[0], parts[1])
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no backend for service '%s/%s'", parts[0], parts[1]))
+		return
+	}
+	writeJSON(w, backends)
+}
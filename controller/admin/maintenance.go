@@ -0,0 +1,88 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// MaintenanceState is the state of the maintenance operations of the controller
+type MaintenanceState struct {
+	// Paused holds HAProxy configuration changes until reconciliation is resumed
+	Paused bool `json:"paused"`
+	// Draining makes healthz fail so that load balancers stop sending new traffic
+	Draining bool `json:"draining"`
+}
+
+// Maintenance is implemented by the controller to run maintenance operations
+type Maintenance interface {
+	State() MaintenanceState
+	SetState(state MaintenanceState)
+	// Resync replays a full configuration sync followed by an HAProxy reload
+	Resync()
+}
+
+// maintenance handles:
+// - "GET /maintenance"
+// - "PUT /maintenance" with body {"paused": <bool>, "draining": <bool>}, omitted fields are kept
+func (s Server) maintenance(w http.ResponseWriter, r *http.Request) {
+	if s.Maintenance == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("path '%s' not found", r.URL.Path))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, s.Maintenance.State())
+	case http.MethodPut:
+		var body struct {
+			Paused   *bool `json:"paused"`
+			Draining *bool `json:"draining"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid body"))
+			return
+		}
+		state := s.Maintenance.State()
+		if body.Paused != nil {
+			state.Paused = *body.Paused
+		}
+		if body.Draining != nil {
+			state.Draining = *body.Draining
+		}
+		s.Maintenance.SetState(state)
+		logger.Infof("admin API: maintenance state set to paused=%t draining=%t by '%s'", state.Paused, state.Draining, username(r))
+		writeJSON(w, state)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// resync handles "POST /maintenance/resync"
+func (s Server) resync(w http.ResponseWriter, r *http.Request) {
+	if s.Maintenance == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("path '%s' not found", r.URL.Path))
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	s.Maintenance.Resync()
+	logger.Infof("admin API: resync forced by '%s'", username(r))
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"net/http"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/metrics"
+)
+
+// metrics handles "GET /metrics", stick table utilization and backend
+// retries are read from the runtime API on each scrape since they change with traffic.
+func (s Server) metrics(w http.ResponseWriter, r *http.Request) {
+	tables, err := s.Client.TablesGet()
+	if err != nil {
+		logger.Error(err)
+	} else {
+		metrics.StickTableSize.Reset()
+		metrics.StickTableUsed.Reset()
+		for _, table := range tables {
+			if table.Size != nil {
+				metrics.StickTableSize.Set(int(*table.Size), table.Name)
+			}
+			if table.Used != nil {
+				metrics.StickTableUsed.Set(int(*table.Used), table.Name)
+			}
+		}
+	}
+	stats, err := s.Client.StatsGet()
+	if err != nil {
+		logger.Error(err)
+	} else {
+		metrics.BackendRetries.Reset()
+		metrics.BackendRedispatches.Reset()
+		// with several runtime APIs counters of each process are added up
+		retries := make(map[string]int)
+		redispatches := make(map[string]int)
+		for _, collection := range stats {
+			for _, stat := range collection.Stats {
+				if stat.Type != "backend" || stat.Stats == nil {
+					continue
+				}
+				if stat.Stats.Wretr != nil {
+					retries[stat.Name] += int(*stat.Stats.Wretr)
+				}
+				if stat.Stats.Wredis != nil {
+					redispatches[stat.Name] += int(*stat.Stats.Wredis)
+				}
+			}
+		}
+		for backend, n := range retries {
+			metrics.BackendRetries.Set(n, backend)
+		}
+		for backend, n := range redispatches {
+			metrics.BackendRedispatches.Set(n, backend)
+		}
+	}
+	metrics.Handler(w, r)
+}
@@ -0,0 +1,196 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// openAPISpec documents the admin API, it must be kept in sync with the handlers
+const openAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "HAProxy Kubernetes Ingress Controller admin API",
+    "version": "1"
+  },
+  "security": [{"bearer": []}],
+  "components": {
+    "securitySchemes": {
+      "bearer": {"type": "http", "scheme": "bearer", "description": "Kubernetes token, authorized via a SubjectAccessReview on the request path"}
+    },
+    "parameters": {
+      "namespace": {"name": "namespace", "in": "path", "required": true, "schema": {"type": "string"}},
+      "name": {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}},
+      "backend": {"name": "backend", "in": "path", "required": true, "schema": {"type": "string"}},
+      "server": {"name": "server", "in": "path", "required": true, "schema": {"type": "string"}}
+    },
+    "schemas": {
+      "Error": {"type": "object", "properties": {"error": {"type": "string"}}},
+      "Ingress": {
+        "type": "object",
+        "properties": {
+          "namespace": {"type": "string"},
+          "name": {"type": "string"},
+          "class": {"type": "string"},
+          "ignored": {"type": "boolean", "description": "ingress not matching the controller IngressClass or claiming hosts owned by other namespaces"},
+          "paths": {"type": "array", "items": {"$ref": "#/components/schemas/IngressPath"}}
+        }
+      },
+      "IngressPath": {
+        "type": "object",
+        "properties": {
+          "host": {"type": "string"},
+          "path": {"type": "string"},
+          "pathType": {"type": "string"},
+          "service": {"type": "string"},
+          "servicePort": {"type": "string"},
+          "defaultBackend": {"type": "boolean"},
+          "backend": {"type": "string"},
+          "error": {"type": "string", "description": "why no backend could be resolved"}
+        }
+      },
+      "Backend": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "servicePort": {"type": "string"},
+          "config": {"type": "object", "description": "HAProxy backend configuration, as modeled by the Data Plane API"},
+          "servers": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "name": {"type": "string"},
+                "address": {"type": "string", "description": "empty for disabled server slots"},
+                "port": {"type": "integer"},
+                "pod": {"type": "string", "description": "pod behind the server address, when known"}
+              }
+            }
+          }
+        }
+      },
+      "MaintenanceState": {
+        "type": "object",
+        "properties": {
+          "paused": {"type": "boolean", "description": "HAProxy configuration changes are held until reconciliation is resumed"},
+          "draining": {"type": "boolean", "description": "healthz fails so that load balancers stop sending new traffic"}
+        }
+      }
+    }
+  },
+  "paths": {
+    "/openapi.json": {
+      "get": {"summary": "This document", "responses": {"200": {"description": "OpenAPI document"}}}
+    },
+    "/ingresses": {
+      "get": {
+        "summary": "Ingresses as seen by the controller store at the last successful sync",
+        "responses": {"200": {"description": "Ingresses", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Ingress"}}}}}}
+      }
+    },
+    "/ingresses/{namespace}/{name}/annotations": {
+      "parameters": [{"$ref": "#/components/parameters/namespace"}, {"$ref": "#/components/parameters/name"}],
+      "get": {
+        "summary": "Resolved annotation values of an ingress, i.e. ingress annotations over ConfigMap and default values",
+        "responses": {
+          "200": {"description": "Annotations", "content": {"application/json": {"schema": {"type": "object", "additionalProperties": {"type": "string"}}}}},
+          "404": {"description": "Unknown ingress", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/services/{namespace}/{name}/backends": {
+      "parameters": [{"$ref": "#/components/parameters/namespace"}, {"$ref": "#/components/parameters/name"}],
+      "get": {
+        "summary": "HAProxy backends generated for the ports of a service",
+        "responses": {
+          "200": {"description": "Backends", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Backend"}}}}},
+          "404": {"description": "No backend for the service", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/runtime/info": {
+      "get": {"summary": "HAProxy process information", "responses": {"200": {"description": "Process information"}}}
+    },
+    "/runtime/workers": {
+      "get": {"summary": "HAProxy workers, when runtime commands go through the master CLI", "responses": {"200": {"description": "Workers, current ones first"}}}
+    },
+    "/runtime/tables": {
+      "get": {"summary": "HAProxy stick tables", "responses": {"200": {"description": "Stick tables"}}}
+    },
+    "/runtime/tables/{table}": {
+      "parameters": [
+        {"name": "table", "in": "path", "required": true, "schema": {"type": "string"}},
+        {"name": "filter", "in": "query", "schema": {"type": "string"}, "example": "http_req_rate gt 10"},
+        {"name": "key", "in": "query", "schema": {"type": "string"}},
+        {"name": "offset", "in": "query", "schema": {"type": "integer", "default": 0}},
+        {"name": "limit", "in": "query", "schema": {"type": "integer", "default": 100}}
+      ],
+      "get": {"summary": "Entries of a stick table", "responses": {"200": {"description": "Stick table entries"}}}
+    },
+    "/runtime/backends/{backend}/servers": {
+      "parameters": [{"$ref": "#/components/parameters/backend"}],
+      "get": {"summary": "Runtime state of backend servers", "responses": {"200": {"description": "Servers"}}}
+    },
+    "/runtime/backends/{backend}/servers/{server}/state": {
+      "parameters": [{"$ref": "#/components/parameters/backend"}, {"$ref": "#/components/parameters/server"}],
+      "put": {
+        "summary": "Set server state",
+        "requestBody": {"content": {"application/json": {"schema": {"type": "object", "properties": {"state": {"type": "string", "enum": ["ready", "drain", "maint"]}}}}}},
+        "responses": {"200": {"description": "State set"}}
+      }
+    },
+    "/runtime/backends/{backend}/servers/{server}/weight": {
+      "parameters": [{"$ref": "#/components/parameters/backend"}, {"$ref": "#/components/parameters/server"}],
+      "put": {
+        "summary": "Set server weight",
+        "requestBody": {"content": {"application/json": {"schema": {"type": "object", "properties": {"weight": {"type": "string"}}}}}},
+        "responses": {"200": {"description": "Weight set"}}
+      }
+    },
+    "/runtime/counters/clear": {
+      "post": {"summary": "Clear HAProxy counters", "responses": {"200": {"description": "Counters cleared"}}}
+    },
+    "/configuration/transactions": {
+      "get": {"summary": "Outcome of configuration transactions", "responses": {"200": {"description": "Transaction statistics"}}}
+    },
+    "/maintenance": {
+      "get": {"summary": "Maintenance state of the controller", "responses": {"200": {"description": "Maintenance state", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/MaintenanceState"}}}}}},
+      "put": {
+        "summary": "Pause reconciliation or drain the instance, omitted fields are kept",
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/MaintenanceState"}}}},
+        "responses": {"200": {"description": "Maintenance state set"}}
+      }
+    },
+    "/maintenance/resync": {
+      "post": {"summary": "Force a full configuration sync followed by an HAProxy reload", "responses": {"202": {"description": "Resync scheduled"}}}
+    },
+    "/metrics": {
+      "get": {"summary": "Controller metrics in Prometheus text format", "responses": {"200": {"description": "Metrics", "content": {"text/plain": {}}}}}
+    }
+  }
+}
+`
+
+// openAPI handles "GET /openapi.json"
+func (s Server) openAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err := w.Write([]byte(openAPISpec))
+	logger.Error(err)
+}
@@ -0,0 +1,144 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var serverWeight = regexp.MustCompile(`^[0-9]+%?$`)
+
+var serverStates = map[string]struct{}{
+	"ready": {},
+	"drain": {},
+	"maint": {},
+}
+
+// info handles "GET /runtime/info"
+func (s Server) info(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	info, err := s.Client.InfoGet()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, info)
+}
+
+// workers handles "GET /runtime/workers", only available with the master CLI
+func (s Server) workers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	workers, err := s.Client.WorkersGet()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, workers)
+}
+
+// clearCounters handles "POST /runtime/counters/clear"
+func (s Server) clearCounters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	if _, err := s.Client.ExecuteRaw("clear counters"); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	logger.Infof("admin API: counters cleared by '%s'", username(r))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// servers handles:
+// - "GET /runtime/backends/<backend>/servers"
+// - "PUT /runtime/backends/<backend>/servers/<server>/state" with body {"state": "ready|drain|maint"}
+// - "PUT /runtime/backends/<backend>/servers/<server>/weight" with body {"weight": "<weight>"}
+func (s Server) servers(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/runtime/backends/"), "/")
+	for _, part := range parts {
+		if !runtimeName.MatchString(part) {
+			writeError(w, http.StatusNotFound, fmt.Errorf("path '%s' not found", r.URL.Path))
+			return
+		}
+	}
+	switch {
+	case len(parts) == 2 && parts[1] == "servers":
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		servers, err := s.Client.BackendServersStateGet(parts[0])
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, servers)
+	case len(parts) == 4 && parts[1] == "servers":
+		if r.Method != http.MethodPut {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		s.serverUpdate(w, r, parts[0], parts[2], parts[3])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("path '%s' not found", r.URL.Path))
+	}
+}
+
+func (s Server) serverUpdate(w http.ResponseWriter, r *http.Request, backend, server, attribute string) {
+	var body struct {
+		State  string `json:"state"`
+		Weight string `json:"weight"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var err error
+	switch attribute {
+	case "state":
+		if _, ok := serverStates[body.State]; !ok {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid state '%s'", body.State))
+			return
+		}
+		err = s.Client.SetServerState(backend, server, body.State)
+	case "weight":
+		if !serverWeight.MatchString(body.Weight) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid weight '%s'", body.Weight))
+			return
+		}
+		err = s.Client.SetServerWeight(backend, server, body.Weight)
+	default:
+		writeError(w, http.StatusNotFound, errors.New("server attribute not found"))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	logger.Infof("admin API: server '%s/%s' %s updated by '%s'", backend, server, attribute, username(r))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+var logger = utils.GetLogger()
+
+// Server exposes a subset of HAProxy runtime API and of the controller state over HTTP,
+// requests are authenticated and authorized against Kubernetes.
+type Server struct {
+	Address string
+	Port    int64
+	// TLSCert and TLSKey are the files the API is served with over HTTPS, plain HTTP when empty
+	TLSCert string
+	TLSKey  string
+	Client  api.HAProxyClient
+	K8s     kubernetes.Interface
+	// Introspection is the controller state served by the API
+	Introspection *Introspection
+	// Maintenance runs the maintenance operations of the API
+	Maintenance Maintenance
+	reviews     *reviewCache
+}
+
+func (s Server) Run() error {
+	s.reviews = &reviewCache{entries: make(map[string]review)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/runtime/tables", s.authenticate(s.tables))
+	mux.HandleFunc("/runtime/tables/", s.authenticate(s.tableEntries))
+	mux.HandleFunc("/runtime/info", s.authenticate(s.info))
+	mux.HandleFunc("/runtime/workers", s.authenticate(s.workers))
+	mux.HandleFunc("/runtime/counters/clear", s.authenticate(s.clearCounters))
+	mux.HandleFunc("/runtime/backends/", s.authenticate(s.servers))
+	mux.HandleFunc("/configuration/transactions", s.authenticate(s.transactions))
+	mux.HandleFunc("/maintenance", s.authenticate(s.maintenance))
+	mux.HandleFunc("/maintenance/resync", s.authenticate(s.resync))
+	mux.HandleFunc("/metrics", s.authenticate(s.metrics))
+	mux.HandleFunc("/ingresses", s.authenticate(s.ingresses))
+	mux.HandleFunc("/ingresses/", s.authenticate(s.ingressAnnotations))
+	mux.HandleFunc("/services/", s.authenticate(s.serviceBackends))
+	mux.HandleFunc("/openapi.json", s.authenticate(s.openAPI))
+	addr := net.JoinHostPort(s.Address, strconv.FormatInt(s.Port, 10))
+	if s.TLSCert != "" || s.TLSKey != "" {
+		return http.ListenAndServeTLS(addr, s.TLSCert, s.TLSKey, mux)
+	}
+	return http.ListenAndServe(addr, mux)
+}
+
+func writeJSON(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	logger.Error(json.NewEncoder(w).Encode(data))
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	logger.Error(json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}))
+}
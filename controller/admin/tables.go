@@ -0,0 +1,119 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+)
+
+// Filter on stick table data, ex: "http_req_rate gt 10"
+var tableFilter = regexp.MustCompile(`^[a-z0-9_]+ (eq|ne|le|lt|ge|gt) [0-9]+$`)
+
+var runtimeName = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+type tableEntries struct {
+	Total   int                      `json:"total"`
+	Offset  int                      `json:"offset"`
+	Limit   int                      `json:"limit"`
+	Entries models.StickTableEntries `json:"entries"`
+}
+
+// tables handles "GET /runtime/tables"
+func (s Server) tables(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	tables, err := s.Client.TablesGet()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, tables)
+}
+
+// tableEntries handles "GET /runtime/tables/<name>?filter=<filter>&key=<key>&offset=<n>&limit=<n>"
+func (s Server) tableEntries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/runtime/tables/")
+	if !runtimeName.MatchString(name) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid table name '%s'", name))
+		return
+	}
+	query := r.URL.Query()
+	var filter []string
+	if f := query.Get("filter"); f != "" {
+		if !tableFilter.MatchString(f) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid filter '%s'", f))
+			return
+		}
+		filter = []string{f}
+	}
+	key := query.Get("key")
+	if strings.ContainsAny(key, " \t\n;") {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid key '%s'", key))
+		return
+	}
+	offset, err := queryInt(query.Get("offset"), 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit, err := queryInt(query.Get("limit"), 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entries, err := s.Client.TableEntriesGet(name, filter, key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result := tableEntries{
+		Total:   len(entries),
+		Offset:  offset,
+		Limit:   limit,
+		Entries: models.StickTableEntries{},
+	}
+	if offset < len(entries) {
+		end := offset + limit
+		if end > len(entries) {
+			end = len(entries)
+		}
+		result.Entries = entries[offset:end]
+	}
+	writeJSON(w, result)
+}
+
+func queryInt(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0, errors.New("pagination parameters should be positive integers")
+	}
+	return i, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// alertMinRequests is the minimum number of requests of a check period for the error rate to be evaluated
+const alertMinRequests = 20
+
+// backendCounters are the cumulative counters of a backend at the previous alert check
+type backendCounters struct {
+	requests int64
+	errors   int64
+}
+
+// alert is the state of an alert of an ingress backend, sent to the "alert-webhook"
+type alert struct {
+	Name      string  `json:"name"`
+	Firing    bool    `json:"firing"`
+	Namespace string  `json:"namespace"`
+	Ingress   string  `json:"ingress"`
+	Service   string  `json:"service"`
+	Backend   string  `json:"backend"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+}
+
+// checkAlerts compares, every "alert-check-period", the error rate and the queue of backends with the
+// "alert-error-rate" and "alert-queue" thresholds of the ingresses using them. Breaches and recoveries
+// are reported with Events on the ingresses and, if set, to the "alert-webhook".
+func (c *HAProxyController) checkAlerts() {
+	if c.OSArgs.AlertCheckPeriod == 0 || !c.ready || time.Since(c.lastAlertCheck) < c.OSArgs.AlertCheckPeriod {
+		return
+	}
+	c.lastAlertCheck = time.Now()
+	stats, err := c.Client.StatsGet()
+	if err != nil {
+		logger.Errorf("alerts: %s", err)
+		return
+	}
+	labels := c.backendLabels()
+	counters := make(map[string]backendCounters)
+	firing := make(map[string]struct{})
+	for _, collection := range stats {
+		for _, stat := range collection.Stats {
+			backend := labels[stat.Name]
+			if stat.Type != "backend" || stat.Stats == nil || backend == nil {
+				continue
+			}
+			s := stat.Stats
+			current := backendCounters{requests: statValue(s.ReqTot), errors: statValue(s.Hrsp5xx)}
+			previous, ok := c.alertCounters[stat.Name]
+			counters[stat.Name] = current
+			// counters are reset by reloads
+			errorRate := -1.0
+			if requests := current.requests - previous.requests; ok && requests >= alertMinRequests && current.errors >= previous.errors {
+				errorRate = float64(current.errors-previous.errors) * 100 / float64(requests)
+			}
+			for _, ingress := range backend.ingresses {
+				c.checkAlert(firing, "error-rate", stat.Name, backend, ingress, errorRate)
+				c.checkAlert(firing, "queue", stat.Name, backend, ingress, float64(statValue(s.Qcur)))
+			}
+		}
+	}
+	// alerts of removed ingresses or backends are forgotten
+	c.alertCounters = counters
+	c.alertsFiring = firing
+}
+
+// checkAlert reports the breach or the recovery of an alert threshold of an ingress, negative values are not evaluated
+func (c *HAProxyController) checkAlert(firing map[string]struct{}, name, backendName string, backend *backendLabels, ingress *store.Ingress, v float64) {
+	annotation := "alert-" + name
+	input := annotations.GetValue(annotation, ingress.Annotations, c.Store.ConfigMaps.Main.Annotations)
+	if input == "" {
+		return
+	}
+	key := strings.Join([]string{ingress.Namespace, ingress.Name, backendName, name}, "/")
+	_, wasFiring := c.alertsFiring[key]
+	if v < 0 {
+		// the state is kept until the next evaluation
+		if wasFiring {
+			firing[key] = struct{}{}
+		}
+		return
+	}
+	threshold, err := strconv.ParseFloat(strings.TrimSuffix(input, "%"), 64)
+	if err != nil || threshold < 0 {
+		err = fmt.Errorf("incorrect value '%s'", input)
+		logger.Errorf("ingress %s/%s: annotation '%s': %s", ingress.Namespace, ingress.Name, annotation, err)
+		annotations.InvalidAnnotationEvent(ingress.Ref(), annotation, err)
+		return
+	}
+	isFiring := v > threshold
+	if isFiring {
+		firing[key] = struct{}{}
+	}
+	if isFiring == wasFiring {
+		return
+	}
+	a := alert{
+		Name:      name,
+		Firing:    isFiring,
+		Namespace: ingress.Namespace,
+		Ingress:   ingress.Name,
+		Service:   backend.service,
+		Backend:   backendName,
+		Value:     v,
+		Threshold: threshold,
+	}
+	if isFiring {
+		message := fmt.Sprintf("backend '%s' of service '%s': %s %g exceeds %g", backendName, backend.service, name, v, threshold)
+		logger.Warningf("ingress %s/%s: %s", ingress.Namespace, ingress.Name, message)
+		utils.WarningEvent(ingress.Ref(), "BackendAlert", message)
+	} else {
+		message := fmt.Sprintf("backend '%s' of service '%s': %s %g back under %g", backendName, backend.service, name, v, threshold)
+		logger.Infof("ingress %s/%s: %s", ingress.Namespace, ingress.Name, message)
+		utils.NormalEvent(ingress.Ref(), "BackendAlertResolved", message)
+	}
+	if c.OSArgs.AlertWebhook != "" {
+		go postJSON(c.OSArgs.AlertWebhook, a)
+	}
+}
+
+// statValue returns a stat value, 0 when not reported by HAProxy
+func statValue(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
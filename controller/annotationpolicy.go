@@ -0,0 +1,131 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// enforceAnnotationPolicies removes the annotations of ingresses and services violating AnnotationPolicies,
+// they are reported with an "AnnotationDenied" Warning Event. Denied annotations are kept aside and checked
+// again at each sync, so that policy changes apply to existing resources.
+func (c *HAProxyController) enforceAnnotationPolicies() {
+	for _, ns := range c.Store.Namespaces {
+		if !ns.Relevant {
+			continue
+		}
+		policies := c.namespacePolicies(ns.Name)
+		for _, ingress := range ns.Ingresses {
+			ingress.Annotations, ingress.DeniedAnnotations = enforcePolicies(policies, ingress.Annotations, ingress.DeniedAnnotations, ingress.Ref())
+		}
+		for _, service := range ns.Services {
+			service.Annotations, service.DeniedAnnotations = enforcePolicies(policies, service.Annotations, service.DeniedAnnotations, service.Ref())
+		}
+	}
+}
+
+// namespacePolicies returns the AnnotationPolicies applying to the namespace
+func (c *HAProxyController) namespacePolicies(namespace string) (policies []*store.AnnotationPolicy) {
+	for _, policy := range c.Store.CR.AnnotationPolicies {
+		if _, ok := policy.Namespaces[namespace]; ok || len(policy.Namespaces) == 0 {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// weightedRouteDenied checks if the weighted routing of a BlueGreen or TrafficSplit CR is denied by
+// the AnnotationPolicies of its namespace. It is equivalent to a canary ingress receiving weight percent
+// of the requests, so it is subject to the "canary" and "canary-weight" annotation policies, and reported
+// with an "AnnotationDenied" Warning Event. All requests are then routed to the main backend of the CR.
+func (c *HAProxyController) weightedRouteDenied(ref utils.ResourceRef, weight int64) bool {
+	policies := c.namespacePolicies(ref.Namespace)
+	err := checkPolicies(policies, "canary", "true")
+	if err == nil {
+		err = checkPolicies(policies, "canary-weight", strconv.FormatInt(weight, 10))
+	}
+	if err == nil {
+		return false
+	}
+	msg := fmt.Sprintf("weighted routing denied: %s", err)
+	logger.Warningf("%s '%s/%s': %s", ref.Kind, ref.Namespace, ref.Name, msg)
+	utils.WarningEvent(ref, "AnnotationDenied", msg)
+	return true
+}
+
+// enforcePolicies returns the allowed and the denied annotations of a resource
+func enforcePolicies(policies []*store.AnnotationPolicy, annotations, denied map[string]string, ref utils.ResourceRef) (map[string]string, map[string]string) {
+	if len(policies) == 0 && len(denied) == 0 {
+		return annotations, nil
+	}
+	allowed := make(map[string]string, len(annotations)+len(denied))
+	var newDenied map[string]string
+	for _, m := range []map[string]string{annotations, denied} {
+		for name, value := range m {
+			err := checkPolicies(policies, name, value)
+			if err == nil {
+				allowed[name] = value
+				continue
+			}
+			if newDenied == nil {
+				newDenied = make(map[string]string)
+			}
+			newDenied[name] = value
+			if previous, ok := denied[name]; !ok || previous != value {
+				logger.Warningf("%s '%s/%s': annotation '%s' denied: %s", ref.Kind, ref.Namespace, ref.Name, name, err)
+				utils.WarningEvent(ref, "AnnotationDenied", fmt.Sprintf("annotation '%s' denied: %s", name, err))
+			}
+		}
+	}
+	return allowed, newDenied
+}
+
+// checkPolicies returns an error when an annotation value violates one of the policies
+func checkPolicies(policies []*store.AnnotationPolicy, name, value string) error {
+	for _, policy := range policies {
+		if _, ok := policy.Forbidden[name]; ok {
+			return fmt.Errorf("forbidden by AnnotationPolicy '%s'", policy.Name)
+		}
+		limit, ok := policy.Limits[name]
+		if !ok {
+			continue
+		}
+		var v, max *int64
+		var err error
+		switch {
+		case limit.MaxTime != nil:
+			v, err = utils.ParseTime(value)
+			max = limit.MaxTime
+		case limit.MaxSize != nil:
+			v, err = utils.ParseSize(value)
+			max = limit.MaxSize
+		default:
+			var i int64
+			i, err = utils.ParseInt(value)
+			v, max = &i, limit.MaxValue
+		}
+		if err != nil {
+			return fmt.Errorf("value '%s' bounded by AnnotationPolicy '%s' is invalid: %w", value, policy.Name, err)
+		}
+		if *v > *max {
+			return fmt.Errorf("value '%s' exceeds the maximum of AnnotationPolicy '%s'", value, policy.Name)
+		}
+	}
+	return nil
+}
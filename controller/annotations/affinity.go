@@ -0,0 +1,95 @@
+package annotations
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+const (
+	affinitySourceIP     = "source-ip"
+	affinityStickOn      = "stick on src"
+	affinityTableSize    = int64(102400)
+	affinityTableTimeout = int64(30 * 60 * 1000)
+)
+
+// Affinity makes clients stick to the same backend server based on their source IP address,
+// for services where cookie persistence is not possible. The stick table is set in the backend
+// and the stick rule is added to the backend config snippet since it is not handled by the API.
+type Affinity struct {
+	backend *models.Backend
+	timeout int64
+}
+
+type AffinityAnn struct {
+	name   string
+	parent *Affinity
+}
+
+func NewAffinity(b *models.Backend) *Affinity {
+	return &Affinity{backend: b}
+}
+
+func (p *Affinity) NewAnnotation(n string) AffinityAnn {
+	return AffinityAnn{
+		name:   n,
+		parent: p,
+	}
+}
+
+func (a AffinityAnn) GetName() string {
+	return a.name
+}
+
+// "affinity-timeout" is processed before "affinity"
+func (a AffinityAnn) Process(input string) error {
+	switch a.name {
+	case "affinity-timeout":
+		a.parent.timeout = affinityTableTimeout
+		if input == "" {
+			return nil
+		}
+		timeout, err := utils.ParseTime(input)
+		if err != nil {
+			return err
+		}
+		a.parent.timeout = *timeout
+	case "affinity":
+		var enabled bool
+		switch input {
+		case "", "none":
+		case affinitySourceIP:
+			enabled = true
+		default:
+			a.setStickOn(false)
+			return fmt.Errorf("unknown affinity '%s'", input)
+		}
+		a.setStickOn(enabled)
+		if !enabled {
+			a.parent.backend.StickTable = nil
+			return nil
+		}
+		a.parent.backend.StickTable = &models.BackendStickTable{
+			Type:   "ipv6",
+			Size:   utils.PtrInt64(affinityTableSize),
+			Expire: utils.PtrInt64(a.parent.timeout),
+		}
+	default:
+		return fmt.Errorf("unknown affinity annotation '%s'", a.name)
+	}
+	return nil
+}
+
+func (a AffinityAnn) setStickOn(enabled bool) {
+	data, ok := cfgSnippet.backends[a.parent.backend.Name]
+	if !ok {
+		data = &cfgData{}
+		cfgSnippet.backends[a.parent.backend.Name] = data
+	}
+	if data.stickOn != enabled {
+		data.stickOn = enabled
+		data.toUpdate = true
+	}
+}
@@ -1,6 +1,8 @@
 package annotations
 
 import (
+	"fmt"
+
 	"github.com/haproxytech/client-native/v2/models"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/annotations/global"
@@ -8,6 +10,7 @@ import (
 	"github.com/haproxytech/kubernetes-ingress/controller/annotations/service"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 type Annotation interface {
@@ -15,25 +18,37 @@ type Annotation interface {
 	Process(value string) error
 }
 
-func GetGlobalAnnotations(g *models.Global, l *models.LogTargets) []Annotation {
+func GetGlobalAnnotations(g *models.Global, l *models.LogTargets, r map[string][]string) []Annotation {
 	return []Annotation{
 		NewGlobalCfgSnippet("global-config-snippet"),
 		NewFrontendCfgSnippet("frontend-config-snippet", "http"),
 		NewFrontendCfgSnippet("frontend-config-snippet", "https"),
 		NewFrontendCfgSnippet("stats-config-snippet", "stats"),
 		global.NewSyslogServers("syslog-server", g, l),
+		global.NewSyslogRings("syslog-rings", r),
 		global.NewNbthread("nbthread", g),
+		global.NewCPUMap("cpu-map", g),
 		global.NewMaxconn("maxconn", g),
+		NewGlobalTune("tune.bufsize", 1024, 1048576),
+		NewGlobalTune("tune.maxrewrite", 0, 524288),
+		NewGlobalTune("tune.ssl.cachesize", 0, 10000000),
+		NewGlobalTune("tune.h2.initial-window-size", 65535, 2147483647),
 		global.NewHardStopAfter("hard-stop-after", g),
+		// Order is important: the module is validated against the data file
+		NewDeviceDetection("device-detection-data-file"),
+		NewDeviceDetection("device-detection-properties"),
+		NewDeviceDetection("device-detection"),
 	}
 }
 
 func GetDefaultsAnnotations(d *models.Defaults) []Annotation {
+	accessLogFormat := global.NewAccessLogFormat(d)
 	return []Annotation{
 		global.NewOption("http-server-close", d),
 		global.NewOption("http-keep-alive", d),
 		global.NewOption("dontlognull", d),
 		global.NewOption("logasap", d),
+		global.NewOption("log-separate-errors", d),
 		global.NewTimeout("timeout-http-request", d),
 		global.NewTimeout("timeout-connect", d),
 		global.NewTimeout("timeout-client", d),
@@ -44,16 +59,24 @@ func GetDefaultsAnnotations(d *models.Defaults) []Annotation {
 		global.NewTimeout("timeout-tunnel", d),
 		global.NewTimeout("timeout-http-keep-alive", d),
 		global.NewLogFormat("log-format", d),
+		// JSON access logs preset replaces log-format
+		accessLogFormat.NewAnnotation("syslog-format"),
+		accessLogFormat.NewAnnotation("syslog-format-fields"),
 	}
 }
 
 func GetFrontendAnnotations(i store.Ingress, r *haproxy.Rules, m haproxy.Maps, k store.K8s) []Annotation {
-	reqRateLimit := ingress.NewReqRateLimit(r)
+	reqRateLimit := ingress.NewReqRateLimit(r, i, k)
+	reqSpikeArrest := ingress.NewReqSpikeArrest(r)
 	httpsRedirect := ingress.NewHTTPSRedirect(r, i)
 	hostRedirect := ingress.NewHostRedirect(r)
 	reqAuth := ingress.NewReqAuth(r, i, k)
+	reqOIDC := ingress.NewReqOIDC(r, i, k)
 	reqCapture := ingress.NewReqCapture(r)
 	resSetCORS := ingress.NewResSetCORS(r)
+	resSetCache := ingress.NewResSetCache(r)
+	resSetSecurityHdr := ingress.NewResSetSecurityHdr(r)
+	resSetCSP := ingress.NewResSetCSP(r, i, k)
 	return []Annotation{
 		// Simple annoations
 		ingress.NewBlackList("blacklist", r, m),
@@ -63,6 +86,17 @@ func GetFrontendAnnotations(i store.Ingress, r *haproxy.Rules, m haproxy.Maps, k
 		ingress.NewReqPathRewrite("path-rewrite", r),
 		ingress.NewReqSetHdr("request-set-header", r),
 		ingress.NewResSetHdr("response-set-header", r),
+		ingress.NewResRewriteLocation("response-location-rewrite", r),
+		ingress.NewResRewriteCookie("response-cookie-rewrite", r),
+		ingress.NewReqConnLimit("conn-limit-per-ip", r, i),
+		ingress.NewReqSetPriority("request-priority", r),
+		ingress.NewStaticResponse("static-response", r, i, k),
+		ingress.NewReqSNICheck("sni-host-check", r),
+		ingress.NewResLogSample("log-sample-normal", r),
+		ingress.NewResSetAltSvc("alt-svc", r),
+		ingress.NewReqLoadShed("load-shed", r),
+		ingress.NewResSetStatus("response-status-rewrite", r, m, i, k),
+		ingress.NewReqDeviceHdr("device-detection-headers", r, deviceDetection.module, deviceDetection.properties),
 		// Annotation factory for related annotations
 		httpsRedirect.NewAnnotation("ssl-redirect"),
 		httpsRedirect.NewAnnotation("ssl-redirect-port"),
@@ -70,32 +104,83 @@ func GetFrontendAnnotations(i store.Ingress, r *haproxy.Rules, m haproxy.Maps, k
 		hostRedirect.NewAnnotation("request-redirect"),
 		hostRedirect.NewAnnotation("request-redirect-code"),
 		reqRateLimit.NewAnnotation("rate-limit-requests"),
+		reqRateLimit.NewAnnotation("rate-limit-key"),
 		reqRateLimit.NewAnnotation("rate-limit-period"),
 		reqRateLimit.NewAnnotation("rate-limit-size"),
+		reqRateLimit.NewAnnotation("rate-limit-expire"),
 		reqRateLimit.NewAnnotation("rate-limit-status-code"),
+		reqRateLimit.NewAnnotation("rate-limit-map"),
+		reqSpikeArrest.NewAnnotation("spike-arrest-requests"),
+		reqSpikeArrest.NewAnnotation("spike-arrest-period"),
+		reqSpikeArrest.NewAnnotation("spike-arrest-delay"),
 		reqAuth.NewAnnotation("auth-type"),
 		reqAuth.NewAnnotation("auth-realm"),
 		reqAuth.NewAnnotation("auth-secret"),
+		reqAuth.NewAnnotation("auth-exclude-paths"),
+		reqOIDC.NewAnnotation("oidc-issuer"),
+		reqOIDC.NewAnnotation("oidc-authorization-endpoint"),
+		reqOIDC.NewAnnotation("oidc-token-endpoint"),
+		reqOIDC.NewAnnotation("oidc-secret"),
+		reqOIDC.NewAnnotation("oidc-redirect-path"),
+		reqOIDC.NewAnnotation("oidc-redirect-host"),
+		reqOIDC.NewAnnotation("oidc-cookie-name"),
+		reqOIDC.NewAnnotation("oidc-cookie-max-age"),
 		reqCapture.NewAnnotation("request-capture"),
 		reqCapture.NewAnnotation("request-capture-len"),
 		resSetCORS.NewAnnotation("cors-allow-origin"),
 		resSetCORS.NewAnnotation("cors-allow-method"),
 		resSetCORS.NewAnnotation("cors-allow-headers"),
 		resSetCORS.NewAnnotation("cors-max-age"),
+		resSetCache.NewAnnotation("cache-control"),
+		resSetCache.NewAnnotation("cache-max-age"),
+		resSetSecurityHdr.NewAnnotation("security-header-x-content-type-options"),
+		resSetSecurityHdr.NewAnnotation("security-header-x-frame-options"),
+		resSetSecurityHdr.NewAnnotation("security-header-referrer-policy"),
+		resSetSecurityHdr.NewAnnotation("security-header-permissions-policy"),
+		resSetSecurityHdr.NewAnnotation("security-header-cross-origin-opener-policy"),
+		resSetSecurityHdr.NewAnnotation("security-header-cross-origin-embedder-policy"),
+		resSetSecurityHdr.NewAnnotation("security-headers"),
+		resSetCSP.NewAnnotation("content-security-policy-report-only"),
+		resSetCSP.NewAnnotation("content-security-policy"),
 	}
 }
 
-func GetBackendAnnotations(b *models.Backend) []Annotation {
+func GetBackendAnnotations(b *models.Backend, k store.K8s, namespace string) []Annotation {
+	affinity := NewAffinity(b)
+	hmac := NewHMAC(b, k, namespace)
+	contentScan := NewContentScan(b)
+	slo := NewSLO(b)
 	annotations := []Annotation{
 		NewBackendCfgSnippet("backend-config-snippet", b.Name),
+		NewTransparentProxy("transparent-proxy", b.Name),
+		NewSPOEFilter("spoe-filter", b.Name),
+		affinity.NewAnnotation("affinity-timeout"),
+		affinity.NewAnnotation("affinity"),
 		service.NewAbortOnClose("abortonclose", b),
 		service.NewTimeoutCheck("timeout-check", b),
+		service.NewTimeout("timeout-queue", b),
+		service.NewTimeout("timeout-server", b),
+		service.NewTimeout("timeout-tunnel", b),
 		service.NewLoadBalance("load-balance", b),
+		service.NewHashType("hash-type", b),
 	}
 	if b.Mode == "http" {
 		annotations = append(annotations,
 			service.NewCheckHTTP("check-http", b),
 			service.NewForwardedFor("forwarded-for", b),
+			service.NewHTTPBufferRequest("http-buffer-request", b),
+			hmac.NewAnnotation("hmac-header"),
+			hmac.NewAnnotation("hmac-algorithm"),
+			hmac.NewAnnotation("hmac-secret"),
+			contentScan.NewAnnotation("content-scan-body"),
+			contentScan.NewAnnotation("content-scan-timeout"),
+			contentScan.NewAnnotation("content-scan-fail-mode"),
+			contentScan.NewAnnotation("content-scan-deny-status"),
+			contentScan.NewAnnotation("content-scan"),
+			slo.NewAnnotation("slo-queue"),
+			slo.NewAnnotation("slo-response-time"),
+			// Order is important: hash-key overrides load-balance
+			service.NewHashKey("hash-key", b),
 		)
 	}
 	return annotations
@@ -105,14 +190,22 @@ func GetServerAnnotations(s *models.Server, k8sStore store.K8s, certs *haproxy.C
 	return []Annotation{
 		service.NewCheck("check", s),
 		service.NewCheckInter("check-interval", s),
+		service.NewAgentCheck("agent-check-port", s),
+		service.NewAgentCheck("agent-check-interval", s),
+		service.NewAgentCheck("agent-check-send", s),
 		service.NewCookie("cookie-persistence", nil, s),
 		service.NewMaxconn("pod-maxconn", s),
+		service.NewMaxconnAlias("server-maxconn", s),
+		service.NewMaxqueue("maxqueue", s),
 		service.NewSendProxy("send-proxy-protocol", s),
 		// Order is important for ssl annotations so they don't conflict
 		service.NewSSL("server-ssl", s),
 		service.NewCrt("server-crt", k8sStore, certs, s),
 		service.NewCA("server-ca", k8sStore, certs, s),
 		service.NewProto("server-proto", s),
+		// after ssl annotations which reset the verify option
+		service.NewCheckSSL("check-ssl", s),
+		service.NewCheckSSL("check-sni", s),
 	}
 }
 
@@ -127,33 +220,90 @@ func GetValue(annotationName string, annotations ...map[string]string) string {
 	return defaultValues[annotationName]
 }
 
+// Resolve returns the values GetValue would return for all the annotations
+// set in the given maps or having a default value.
+func Resolve(annotations ...map[string]string) map[string]string {
+	resolved := make(map[string]string, len(defaultValues))
+	for name, value := range defaultValues {
+		resolved[name] = value
+	}
+	for i := len(annotations) - 1; i >= 0; i-- {
+		for name, value := range annotations[i] {
+			resolved[name] = value
+		}
+	}
+	return resolved
+}
+
+// Process processes the annotation value, an invalid value is replaced
+// by the annotation default value, as if the annotation was not set.
+func Process(a Annotation, value string) error {
+	err := a.Process(value)
+	if err == nil {
+		return nil
+	}
+	if defaultValue, ok := defaultValues[a.GetName()]; ok && defaultValue != value && a.Process(defaultValue) == nil {
+		return fmt.Errorf("%w, default value '%s' used", err, defaultValue)
+	}
+	return err
+}
+
+// InvalidAnnotationEvent records a warning Event about the resource setting an invalid annotation
+func InvalidAnnotationEvent(ref utils.ResourceRef, name string, err error) {
+	utils.WarningEvent(ref, "InvalidAnnotation", fmt.Sprintf("annotation '%s': %s", name, err))
+}
+
+// secretDir is the directory of the secrets HAProxy loads from files
+var secretDir string
+
+// SetSecretDir sets the directory OIDC client secrets, HMAC keys and secrets of
+// annotation values are written to, so that they are not part of the configuration
+func SetSecretDir(dir string) {
+	secretDir = dir
+	ingress.SetSecretDir(dir)
+}
+
+// SetPatternDir sets the directory of the pattern files referenced by annotations
+func SetPatternDir(dir string) {
+	ingress.SetPatternDir(dir)
+}
+
 func SetDefaultValue(annotation, value string) {
 	defaultValues[annotation] = value
 }
 
 var defaultValues = map[string]string{
-	"auth-realm":             "Protected Content",
-	"check":                  "true",
-	"cors-allow-origin":      "*",
-	"cors-allow-methods":     "*",
-	"cors-allow-headers":     "*",
-	"cors-max-age":           "5s",
-	"cookie-indirect":        "true",
-	"cookie-nocache":         "true",
-	"cookie-type":            "insert",
-	"forwarded-for":          "true",
-	"load-balance":           "roundrobin",
-	"rate-limit-size":        "100k",
-	"rate-limit-period":      "1s",
-	"rate-limit-status-code": "403",
-	"request-capture-len":    "128",
-	"ssl-redirect-code":      "302",
-	"request-redirect-code":  "302",
-	"ssl-redirect-port":      "443",
-	"ssl-passthrough":        "false",
-	"server-ssl":             "false",
-	"scale-server-slots":     "42",
-	"syslog-server":          "address:127.0.0.1, facility: local0, level: notice",
-	"client-crt-optional":    "false",
-	"tls-alpn":               "h2,http/1.1",
+	"auth-realm":               "Protected Content",
+	"backend-server-state":     "ready",
+	"check":                    "true",
+	"cors-allow-origin":        "*",
+	"cors-allow-methods":       "*",
+	"cors-allow-headers":       "*",
+	"cors-max-age":             "5s",
+	"cookie-indirect":          "true",
+	"cookie-nocache":           "true",
+	"cookie-type":              "insert",
+	"forwarded-for":            "true",
+	"load-balance":             "roundrobin",
+	"rate-limit-key":           "src",
+	"rate-limit-size":          "100k",
+	"rate-limit-period":        "1s",
+	"rate-limit-status-code":   "403",
+	"request-capture-len":      "128",
+	"ssl-redirect-code":        "302",
+	"request-redirect-code":    "302",
+	"ssl-redirect-port":        "443",
+	"ssl-passthrough":          "false",
+	"server-ssl":               "false",
+	"scale-server-slots":       "42",
+	"syslog-server":            "address:127.0.0.1, facility: local0, level: notice",
+	"client-crt-optional":      "false",
+	"tls-alpn":                 "h2,http/1.1",
+	"strict-sni":               "false",
+	"hmac-algorithm":           "sha256",
+	"hmac-header":              "X-Hub-Signature-256",
+	"content-scan-body":        "request",
+	"content-scan-timeout":     "10s",
+	"content-scan-fail-mode":   "open",
+	"content-scan-deny-status": "403",
 }
@@ -16,6 +16,20 @@ type CfgSnippet struct {
 type cfgData struct {
 	value    []string
 	toUpdate bool
+	// transparent adds the source directive of transparent proxying to backend snippets
+	transparent bool
+	// spoe filters added to backend snippets
+	spoe []string
+	// stickOn adds the source IP stick rule of affinity to backend snippets
+	stickOn bool
+	// hmac rules validating request signatures added to backend snippets
+	hmac []string
+	// contentScan rules sending bodies to an SPOE agent added to backend snippets
+	contentScan []string
+	// slo rules shedding the requests of non-critical routes added to backend snippets
+	slo []string
+	// tune options added to the global snippet
+	tune map[string]int64
 }
 
 var cfgSnippet struct {
@@ -26,7 +40,7 @@ var cfgSnippet struct {
 
 //nolint: gochecknoinits
 func init() {
-	cfgSnippet.global = &cfgData{}
+	cfgSnippet.global = &cfgData{tune: make(map[string]int64)}
 	cfgSnippet.frontends = make(map[string]*cfgData)
 	cfgSnippet.backends = make(map[string]*cfgData)
 }
@@ -80,11 +94,26 @@ func (a *CfgSnippet) Process(input string) error {
 	return nil
 }
 
+// ResendCfgSnippets makes the next updates set all config snippets again,
+// e.g. when snippets were reverted in the configuration behind the controller back.
+func ResendCfgSnippets() {
+	cfgSnippet.global.toUpdate = true
+	for _, data := range cfgSnippet.frontends {
+		data.toUpdate = true
+	}
+	for _, data := range cfgSnippet.backends {
+		data.toUpdate = true
+	}
+}
+
 func UpdateGlobalCfgSnippet(api api.HAProxyClient) (updated bool, err error) {
 	if !cfgSnippet.global.toUpdate {
 		return
 	}
-	err = api.GlobalCfgSnippet(cfgSnippet.global.value)
+	value := cfgSnippet.global.value
+	value = append(value[:len(value):len(value)], tuneLines()...)
+	value = append(value, deviceDetectionLines()...)
+	err = api.GlobalCfgSnippet(value)
 	if err != nil {
 		return
 	}
@@ -120,7 +149,26 @@ func UpdateBackendCfgSnippet(api api.HAProxyClient, backend string) (updated boo
 	if !data.toUpdate {
 		return
 	}
-	err = api.BackendCfgSnippetSet(backend, data.value)
+	value := data.value
+	if data.transparent {
+		value = append(value[:len(value):len(value)], transparentProxySource)
+	}
+	if len(data.spoe) != 0 {
+		value = append(value[:len(value):len(value)], data.spoe...)
+	}
+	if data.stickOn {
+		value = append(value[:len(value):len(value)], affinityStickOn)
+	}
+	if len(data.hmac) != 0 {
+		value = append(value[:len(value):len(value)], data.hmac...)
+	}
+	if len(data.contentScan) != 0 {
+		value = append(value[:len(value):len(value)], data.contentScan...)
+	}
+	if len(data.slo) != 0 {
+		value = append(value[:len(value):len(value)], data.slo...)
+	}
+	err = api.BackendCfgSnippetSet(backend, value)
 	if err != nil {
 		return
 	}
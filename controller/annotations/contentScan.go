@@ -0,0 +1,146 @@
+package annotations
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// the engine name is the prefix of the verdict variable, HAProxy variable names do not allow dashes
+var contentScanEngine = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ContentScan sends the request and/or response bodies of a backend to an SPOE agent,
+// such as an ICAP gateway of an antivirus or a DLP service, and denies the messages the
+// agent sets a "block" verdict for. The rules are added to the backend config snippet.
+type ContentScan struct {
+	backend    *models.Backend
+	body       string
+	timeout    int64
+	failClosed bool
+	denyStatus int64
+}
+
+type ContentScanAnn struct {
+	name   string
+	parent *ContentScan
+}
+
+func NewContentScan(b *models.Backend) *ContentScan {
+	return &ContentScan{backend: b}
+}
+
+func (p *ContentScan) NewAnnotation(n string) ContentScanAnn {
+	return ContentScanAnn{
+		name:   n,
+		parent: p,
+	}
+}
+
+func (a ContentScanAnn) GetName() string {
+	return a.name
+}
+
+// "content-scan" is processed last, the other annotations set its options.
+// Example:
+//
+//	content-scan: engine:av, config:/etc/haproxy/spoe/av.conf
+func (a ContentScanAnn) Process(input string) (err error) {
+	switch a.name {
+	case "content-scan-body":
+		switch input {
+		case "request", "response", "both":
+			a.parent.body = input
+		default:
+			return fmt.Errorf("incorrect value '%s', expected request, response or both", input)
+		}
+	case "content-scan-timeout":
+		var timeout *int64
+		if timeout, err = utils.ParseTime(input); err != nil {
+			return
+		}
+		a.parent.timeout = *timeout
+	case "content-scan-fail-mode":
+		switch input {
+		case "open", "closed":
+			a.parent.failClosed = input == "closed"
+		default:
+			return fmt.Errorf("incorrect value '%s', expected open or closed", input)
+		}
+	case "content-scan-deny-status":
+		var status int64
+		if status, err = utils.ParseInt(input); err != nil {
+			return
+		}
+		if status < 200 || status > 599 {
+			return fmt.Errorf("incorrect status code '%s'", input)
+		}
+		a.parent.denyStatus = status
+	case "content-scan":
+		var rules []string
+		defer func() {
+			if err != nil {
+				rules = nil
+			}
+			a.setRules(rules)
+		}()
+		input = strings.Join(strings.Fields(input), "")
+		if input == "" {
+			return
+		}
+		if a.parent.body == "" || a.parent.timeout == 0 || a.parent.denyStatus == 0 {
+			return fmt.Errorf("invalid content-scan-body, content-scan-timeout or content-scan-deny-status annotation")
+		}
+		var engine, config string
+		if engine, config, err = parseSPOEFilter(input); err != nil {
+			return
+		}
+		if !contentScanEngine.MatchString(engine) {
+			return fmt.Errorf("incorrect engine '%s', only letters, digits and underscores are allowed", engine)
+		}
+		rules = []string{fmt.Sprintf("filter spoe engine %s config %s", engine, config)}
+		if a.parent.body != "response" {
+			rules = append(rules, a.parent.phaseRules("http-request", "scan-request", engine)...)
+		}
+		if a.parent.body != "request" {
+			// the verdict of the request scan must not stand for the response one
+			rules = append(rules, fmt.Sprintf("http-response unset-var(txn.%s.verdict)", engine))
+			rules = append(rules, a.parent.phaseRules("http-response", "scan-response", engine)...)
+		}
+	default:
+		err = fmt.Errorf("unknown content-scan annotation '%s'", a.name)
+	}
+	return
+}
+
+// phaseRules waits for the body of the request or response, sends the SPOE group of the
+// phase to the agent and enforces its verdict. When the agent is unreachable or times out,
+// the verdict is not set and the message is only denied in closed fail mode.
+func (p *ContentScan) phaseRules(directive, group, engine string) []string {
+	verdict := fmt.Sprintf("var(txn.%s.verdict)", engine)
+	rules := []string{
+		fmt.Sprintf("%s wait-for-body time %dms", directive, p.timeout),
+		fmt.Sprintf("%s send-spoe-group %s %s", directive, engine, group),
+		fmt.Sprintf("%s deny deny_status %d if { %s -m str block }", directive, p.denyStatus, verdict),
+	}
+	if p.failClosed {
+		rules = append(rules, fmt.Sprintf("%s deny deny_status %d unless { %s -m found }", directive, p.denyStatus, verdict))
+	}
+	return rules
+}
+
+func (a ContentScanAnn) setRules(rules []string) {
+	data, ok := cfgSnippet.backends[a.parent.backend.Name]
+	if !ok {
+		data = &cfgData{}
+		cfgSnippet.backends[a.parent.backend.Name] = data
+	}
+	if !reflect.DeepEqual(data.contentScan, rules) {
+		data.contentScan = rules
+		data.toUpdate = true
+	}
+}
@@ -0,0 +1,162 @@
+package global
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+)
+
+// accessLogField is a field of JSON access logs, numeric fields are not quoted
+type accessLogField struct {
+	name    string
+	format  string
+	numeric bool
+}
+
+// accessLogFields are the standard fields of JSON access logs
+var accessLogFields = []accessLogField{
+	{name: "time", format: "%tr"},
+	{name: "client_ip", format: "%ci"},
+	{name: "client_port", format: "%cp", numeric: true},
+	{name: "frontend", format: "%ft"},
+	{name: "backend", format: "%b"},
+	{name: "server", format: "%s"},
+	{name: "method", format: "%HM"},
+	{name: "host", format: "%[var(txn.host)]"},
+	{name: "path", format: "%[var(txn.path)]"},
+	{name: "query", format: "%HQ"},
+	{name: "protocol", format: "%HV"},
+	{name: "status", format: "%ST", numeric: true},
+	{name: "bytes", format: "%B", numeric: true},
+	{name: "time_request", format: "%TR", numeric: true},
+	{name: "time_queue", format: "%Tw", numeric: true},
+	{name: "time_connect", format: "%Tc", numeric: true},
+	{name: "time_response", format: "%Tr", numeric: true},
+	{name: "time_active", format: "%Ta", numeric: true},
+	{name: "time_total", format: "%Tt", numeric: true},
+	{name: "termination_state", format: "%tsc"},
+	{name: "request_id", format: "%ID"},
+}
+
+var accessLogFieldName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+type AccessLogFormat struct {
+	defaults *models.Defaults
+	json     bool
+}
+
+type AccessLogFormatAnn struct {
+	name   string
+	parent *AccessLogFormat
+}
+
+func NewAccessLogFormat(d *models.Defaults) *AccessLogFormat {
+	return &AccessLogFormat{defaults: d}
+}
+
+func (p *AccessLogFormat) NewAnnotation(n string) AccessLogFormatAnn {
+	return AccessLogFormatAnn{
+		name:   n,
+		parent: p,
+	}
+}
+
+func (a AccessLogFormatAnn) GetName() string {
+	return a.name
+}
+
+// Process sets the log format of the JSON access logs preset, replacing log-format.
+// Fields are customized with one "name: format" line per field to add or replace,
+// and "-name" lines for standard fields to remove.
+// Example:
+//
+//	syslog-format: json
+//	syslog-format-fields: |
+//	  -query
+//	  user_agent: %[capture.req.hdr(0)]
+func (a AccessLogFormatAnn) Process(input string) error {
+	switch a.name {
+	case "syslog-format":
+		switch input {
+		case "", "text":
+			a.parent.json = false
+		case "json":
+			a.parent.json = true
+			a.parent.defaults.LogFormat = jsonLogFormat(accessLogFields)
+		default:
+			return fmt.Errorf("unknown syslog format '%s', expected 'text' or 'json'", input)
+		}
+	case "syslog-format-fields":
+		if !a.parent.json || input == "" {
+			return nil
+		}
+		fields, err := customizeFields(input)
+		if err != nil {
+			return err
+		}
+		a.parent.defaults.LogFormat = jsonLogFormat(fields)
+	default:
+		return fmt.Errorf("unknown access log annotation '%s'", a.name)
+	}
+	return nil
+}
+
+func customizeFields(input string) ([]accessLogField, error) {
+	fields := make([]accessLogField, len(accessLogFields))
+	copy(fields, accessLogFields)
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "-") {
+			name := strings.TrimSpace(line[1:])
+			removed := fields[:0]
+			for _, field := range fields {
+				if field.name != name {
+					removed = append(removed, field)
+				}
+			}
+			fields = removed
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("incorrect field '%s': 'name: format' or '-name' expected", line)
+		}
+		field := accessLogField{name: strings.TrimSpace(parts[0]), format: strings.TrimSpace(parts[1])}
+		if !accessLogFieldName.MatchString(field.name) {
+			return nil, fmt.Errorf("incorrect field name '%s'", field.name)
+		}
+		if field.format == "" || strings.ContainsAny(field.format, `'"`) {
+			return nil, fmt.Errorf("incorrect format '%s' of field '%s'", field.format, field.name)
+		}
+		replaced := false
+		for i := range fields {
+			if fields[i].name == field.name {
+				fields[i] = field
+				replaced = true
+			}
+		}
+		if !replaced {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// jsonLogFormat returns the log format of JSON access logs,
+// the +E flag escaping double quotes and backslashes of the logged values.
+func jsonLogFormat(fields []accessLogField) string {
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := `"` + field.format + `"`
+		if field.numeric {
+			value = field.format
+		}
+		values = append(values, fmt.Sprintf(`"%s":%s`, field.name, value))
+	}
+	return "'%{+E}o{" + strings.Join(values, ",") + "}'"
+}
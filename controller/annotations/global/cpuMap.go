@@ -0,0 +1,58 @@
+package global
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+)
+
+var (
+	cpuMapProcess = regexp.MustCompile(`^(auto:)?(all|odd|even|[0-9]+(-[0-9]+)?)(/(all|odd|even|[0-9]+(-[0-9]+)?))?$`)
+	cpuMapCPUSet  = regexp.MustCompile(`^[0-9]+(-[0-9]+)?$`)
+)
+
+type CPUMap struct {
+	name   string
+	global *models.Global
+}
+
+func NewCPUMap(n string, g *models.Global) *CPUMap {
+	return &CPUMap{name: n, global: g}
+}
+
+func (a *CPUMap) GetName() string {
+	return a.name
+}
+
+// Process parses one "<process/thread> <cpu-set>..." mapping per line
+func (a *CPUMap) Process(input string) error {
+	a.global.CPUMaps = nil
+	if input == "" {
+		return nil
+	}
+	var cpuMaps []*models.CPUMap
+	for _, line := range strings.Split(strings.Trim(input, "\n"), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return fmt.Errorf("incorrect cpu-map '%s', expected format is '<process/thread> <cpu-set>'", line)
+		}
+		if !cpuMapProcess.MatchString(fields[0]) {
+			return fmt.Errorf("incorrect process/thread set '%s'", fields[0])
+		}
+		for _, cpu := range fields[1:] {
+			if !cpuMapCPUSet.MatchString(cpu) {
+				return fmt.Errorf("incorrect cpu set '%s'", cpu)
+			}
+		}
+		process := fields[0]
+		cpuSet := strings.Join(fields[1:], " ")
+		cpuMaps = append(cpuMaps, &models.CPUMap{
+			Process: &process,
+			CPUSet:  &cpuSet,
+		})
+	}
+	a.global.CPUMaps = cpuMaps
+	return nil
+}
@@ -1,6 +1,7 @@
 package global
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/haproxytech/client-native/v2/models"
@@ -28,6 +29,9 @@ func (a *Maxconn) Process(input string) error {
 	if err != nil {
 		return err
 	}
+	if v < 1 {
+		return fmt.Errorf("invalid value '%d', maxconn must be greater than 0", v)
+	}
 	a.global.Maxconn = int64(v)
 	return nil
 }
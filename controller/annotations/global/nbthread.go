@@ -1,6 +1,7 @@
 package global
 
 import (
+	"fmt"
 	"runtime"
 	"strconv"
 
@@ -30,6 +31,9 @@ func (a *Nbthread) Process(input string) error {
 	if err != nil {
 		return err
 	}
+	if v < 1 {
+		return fmt.Errorf("invalid value '%d', at least 1 thread is required", v)
+	}
 	maxProcs := runtime.GOMAXPROCS(0)
 	if v > maxProcs {
 		v = maxProcs
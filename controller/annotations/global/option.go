@@ -33,6 +33,8 @@ func (a *Option) Process(input string) error {
 			a.defaults.Dontlognull = ""
 		case "logasap":
 			a.defaults.Logasap = ""
+		case "log-separate-errors":
+			a.defaults.LogSeparateErrors = ""
 		default:
 			return errors.New("unknown param")
 		}
@@ -53,6 +55,8 @@ func (a *Option) Process(input string) error {
 			a.defaults.Dontlognull = "enabled"
 		case "logasap":
 			a.defaults.Logasap = "enabled"
+		case "log-separate-errors":
+			a.defaults.LogSeparateErrors = "enabled"
 		default:
 			return errors.New("unknown param")
 		}
@@ -64,6 +68,8 @@ func (a *Option) Process(input string) error {
 			a.defaults.Dontlognull = "disabled"
 		case "logasap":
 			a.defaults.Logasap = "disabled"
+		case "log-separate-errors":
+			a.defaults.LogSeparateErrors = "disabled"
 		default:
 			return errors.New("unknown param")
 		}
@@ -0,0 +1,105 @@
+package global
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ringName    = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	ringAddress = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+	ringFormats = map[string]struct{}{
+		"iso": {}, "local": {}, "raw": {}, "rfc3164": {}, "rfc5424": {}, "short": {}, "priority": {}, "timed": {},
+	}
+)
+
+type SyslogRings struct {
+	name  string
+	rings map[string][]string
+}
+
+func NewSyslogRings(n string, r map[string][]string) *SyslogRings {
+	return &SyslogRings{name: n, rings: r}
+}
+
+func (a *SyslogRings) GetName() string {
+	return a.name
+}
+
+// Input is one ring buffer per line, used as log target with the "ring@<name>" address in syslog-server.
+// A ring buffer keeps logs in memory, readable with the "show events" runtime command,
+// and forwards them to the optional syslog server over TCP without blocking HAProxy.
+// Example:
+//
+//	syslog-rings: |
+//	  name:logbuffer, size:32764, format:rfc5424, server:10.0.0.10, port:6514
+func (a *SyslogRings) Process(input string) error {
+	for name := range a.rings {
+		delete(a.rings, name)
+	}
+	for _, ringLine := range strings.Split(input, "\n") {
+		// strip spaces
+		ringLine = strings.Join(strings.Fields(ringLine), "")
+		if ringLine == "" {
+			continue
+		}
+		ringParams := make(map[string]string)
+		for _, param := range strings.Split(ringLine, ",") {
+			if param == "" {
+				continue
+			}
+			parts := strings.Split(param, ":")
+			// param should be key: value
+			if len(parts) != 2 {
+				return fmt.Errorf("incorrect ring param: '%s' in '%s'", param, ringLine)
+			}
+			ringParams[strings.ToLower(parts[0])] = parts[1]
+		}
+		name, ok := ringParams["name"]
+		if !ok || !ringName.MatchString(name) {
+			return fmt.Errorf("incorrect ring line: missing or invalid name param in '%s'", ringLine)
+		}
+		var lines []string
+		for _, k := range []string{"format", "maxlen", "size", "server", "port"} {
+			v, ok := ringParams[k]
+			if !ok {
+				continue
+			}
+			switch k {
+			case "format":
+				if _, ok = ringFormats[v]; !ok {
+					return fmt.Errorf("unknown ring format: '%s' in '%s'", v, ringLine)
+				}
+				lines = append(lines, "format "+v)
+			case "maxlen", "size":
+				if _, err := strconv.ParseUint(v, 10, 32); err != nil {
+					return fmt.Errorf("incorrect ring %s: '%s' in '%s'", k, v, ringLine)
+				}
+				lines = append(lines, k+" "+v)
+			case "server":
+				if !ringAddress.MatchString(v) {
+					return fmt.Errorf("incorrect ring server: '%s' in '%s'", v, ringLine)
+				}
+				port := "514"
+				if p, ok := ringParams["port"]; ok {
+					if _, err := strconv.ParseUint(p, 10, 16); err != nil {
+						return fmt.Errorf("incorrect ring port: '%s' in '%s'", p, ringLine)
+					}
+					port = p
+				}
+				lines = append(lines, "timeout connect 5s", "timeout server 10s", fmt.Sprintf("server syslog %s:%s", v, port))
+			}
+		}
+		for k := range ringParams {
+			switch k {
+			case "name", "format", "maxlen", "size", "server", "port":
+			default:
+				return fmt.Errorf("unknown ring param: '%s' in '%s'", k, ringLine)
+			}
+		}
+		a.rings[name] = lines
+	}
+	return nil
+}
@@ -31,6 +31,8 @@ func (a *SyslogServers) GetName() string {
 //  syslog-server: |
 //    address:127.0.0.1, port:514, facility:local0
 //    address:192.168.1.1, port:514, facility:local1
+//    address:ring@logbuffer, facility:local2
+// A "stdout" line is a shortcut for container logging with "address:stdout, format:raw, facility:daemon"
 func (a *SyslogServers) Process(input string) error {
 	a.stdout = false
 	for _, syslogLine := range strings.Split(input, "\n") {
@@ -39,6 +41,9 @@ func (a *SyslogServers) Process(input string) error {
 		}
 		// strip spaces
 		syslogLine = strings.Join(strings.Fields(syslogLine), "")
+		if syslogLine == "stdout" {
+			syslogLine = "address:stdout,format:raw,facility:daemon"
+		}
 		// parse log params
 		logParams := make(map[string]string)
 		for _, param := range strings.Split(syslogLine, ",") {
@@ -67,7 +72,7 @@ func (a *SyslogServers) Process(input string) error {
 					a.stdout = true
 				}
 			case "port":
-				if logParams["address"] != "stdout" {
+				if logParams["address"] != "stdout" && !strings.HasPrefix(logParams["address"], "ring@") {
 					logTarget.Address += ":" + v
 				}
 			case "length":
@@ -0,0 +1,106 @@
+package annotations
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	DEVICE_DETECTION_51DEGREES   = "51degrees"
+	DEVICE_DETECTION_DEVICEATLAS = "deviceatlas"
+)
+
+var deviceProperty = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// deviceDetection holds the configuration of the device detection module,
+// it is shared with the frontend annotations exposing device properties as request headers.
+var deviceDetection struct {
+	module     string
+	dataFile   string
+	properties []string
+}
+
+// DeviceDetection configures the 51Degrees or DeviceAtlas module of HAProxy.
+// Module directives are added to the global config snippet since they are not handled by the API.
+type DeviceDetection struct {
+	name string
+}
+
+func NewDeviceDetection(n string) *DeviceDetection {
+	return &DeviceDetection{name: n}
+}
+
+func (a *DeviceDetection) GetName() string {
+	return a.name
+}
+
+// "device-detection" is processed last so the data file and properties are known when it is validated.
+func (a *DeviceDetection) Process(input string) error {
+	switch a.name {
+	case "device-detection-data-file":
+		if input != "" && !strings.HasPrefix(input, "/") {
+			a.update(&deviceDetection.dataFile, "")
+			return fmt.Errorf("data file '%s' must be an absolute path", input)
+		}
+		a.update(&deviceDetection.dataFile, input)
+	case "device-detection-properties":
+		properties := strings.FieldsFunc(input, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\n'
+		})
+		for _, property := range properties {
+			if !deviceProperty.MatchString(property) {
+				a.updateProperties(nil)
+				return fmt.Errorf("incorrect device property '%s'", property)
+			}
+		}
+		a.updateProperties(properties)
+	case "device-detection":
+		switch input {
+		case "":
+		case DEVICE_DETECTION_51DEGREES, DEVICE_DETECTION_DEVICEATLAS:
+			if deviceDetection.dataFile == "" {
+				a.update(&deviceDetection.module, "")
+				return fmt.Errorf("device-detection-data-file is required by the %s module", input)
+			}
+		default:
+			a.update(&deviceDetection.module, "")
+			return fmt.Errorf("unknown device detection module '%s'", input)
+		}
+		a.update(&deviceDetection.module, input)
+	default:
+		return fmt.Errorf("unknown device detection annotation '%s'", a.name)
+	}
+	return nil
+}
+
+func (a *DeviceDetection) update(field *string, value string) {
+	if *field != value {
+		*field = value
+		cfgSnippet.global.toUpdate = true
+	}
+}
+
+func (a *DeviceDetection) updateProperties(properties []string) {
+	if !reflect.DeepEqual(deviceDetection.properties, properties) {
+		deviceDetection.properties = properties
+		cfgSnippet.global.toUpdate = true
+	}
+}
+
+// deviceDetectionLines returns the global config snippet lines of the device detection module
+func deviceDetectionLines() []string {
+	switch deviceDetection.module {
+	case DEVICE_DETECTION_51DEGREES:
+		lines := []string{"51degrees-data-file " + deviceDetection.dataFile}
+		if len(deviceDetection.properties) != 0 {
+			lines = append(lines, "51degrees-property-name-list "+strings.Join(deviceDetection.properties, " "))
+		}
+		return lines
+	case DEVICE_DETECTION_DEVICEATLAS:
+		return []string{"deviceatlas-json-file " + deviceDetection.dataFile}
+	default:
+		return nil
+	}
+}
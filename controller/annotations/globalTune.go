@@ -0,0 +1,82 @@
+package annotations
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// HAProxy default buffer size, used to validate tune.maxrewrite when tune.bufsize is not set
+const defaultBufsize = 16384
+
+// GlobalTune sets a tune option of the global section within the provided bounds.
+// Tune options are added to the global config snippet since most of them are not handled by the API.
+type GlobalTune struct {
+	name string
+	min  int64
+	max  int64
+}
+
+func NewGlobalTune(n string, min, max int64) *GlobalTune {
+	return &GlobalTune{name: n, min: min, max: max}
+}
+
+func (a *GlobalTune) GetName() string {
+	return a.name
+}
+
+func (a *GlobalTune) Process(input string) error {
+	if input == "" {
+		a.unset()
+		return nil
+	}
+	value, err := a.parse(input)
+	if err != nil {
+		// Invalid values are not applied
+		a.unset()
+		return err
+	}
+	if current, ok := cfgSnippet.global.tune[a.name]; !ok || current != value {
+		cfgSnippet.global.tune[a.name] = value
+		cfgSnippet.global.toUpdate = true
+	}
+	return nil
+}
+
+func (a *GlobalTune) parse(input string) (int64, error) {
+	value, err := strconv.ParseInt(input, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if value < a.min || value > a.max {
+		return 0, fmt.Errorf("value '%d' out of bounds [%d-%d]", value, a.min, a.max)
+	}
+	// tune.bufsize is processed before tune.maxrewrite
+	if a.name == "tune.maxrewrite" {
+		bufsize, ok := cfgSnippet.global.tune["tune.bufsize"]
+		if !ok {
+			bufsize = defaultBufsize
+		}
+		if value >= bufsize/2 {
+			return 0, fmt.Errorf("value '%d' must be lower than half of tune.bufsize (%d)", value, bufsize)
+		}
+	}
+	return value, nil
+}
+
+func (a *GlobalTune) unset() {
+	if _, ok := cfgSnippet.global.tune[a.name]; ok {
+		delete(cfgSnippet.global.tune, a.name)
+		cfgSnippet.global.toUpdate = true
+	}
+}
+
+// tuneLines returns the global config snippet lines of the tune options
+func tuneLines() []string {
+	lines := make([]string, 0, len(cfgSnippet.global.tune))
+	for name, value := range cfgSnippet.global.tune {
+		lines = append(lines, fmt.Sprintf("%s %d", name, value))
+	}
+	sort.Strings(lines)
+	return lines
+}
@@ -0,0 +1,159 @@
+package annotations
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+
+	"github.com/google/renameio"
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+var hmacHeader = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// HMAC rejects the requests of a backend whose body signature, such as the X-Hub-Signature-256
+// header of GitHub webhooks, does not match the HMAC computed with the key of a secret.
+// The body is buffered and the rules are added to the backend config snippet,
+// the key is loaded by HAProxy from a map file of the secret directory.
+type HMAC struct {
+	backend   *models.Backend
+	k8s       store.K8s
+	namespace string
+	header    string
+	algorithm string
+}
+
+type HMACAnn struct {
+	name   string
+	parent *HMAC
+}
+
+func NewHMAC(b *models.Backend, k store.K8s, namespace string) *HMAC {
+	return &HMAC{backend: b, k8s: k, namespace: namespace}
+}
+
+func (p *HMAC) NewAnnotation(n string) HMACAnn {
+	return HMACAnn{
+		name:   n,
+		parent: p,
+	}
+}
+
+func (a HMACAnn) GetName() string {
+	return a.name
+}
+
+// "hmac-header" and "hmac-algorithm" are processed before "hmac-secret"
+func (a HMACAnn) Process(input string) (err error) {
+	switch a.name {
+	case "hmac-header":
+		if !hmacHeader.MatchString(input) {
+			return fmt.Errorf("incorrect header name '%s'", input)
+		}
+		a.parent.header = input
+	case "hmac-algorithm":
+		switch input {
+		case "sha1", "sha256", "sha512":
+			a.parent.algorithm = input
+		default:
+			return fmt.Errorf("unknown algorithm '%s'", input)
+		}
+	case "hmac-secret":
+		var rules []string
+		var keyUpdated bool
+		defer func() {
+			if err != nil {
+				rules = nil
+			}
+			if rules == nil {
+				if errKey := a.removeKey(); errKey != nil && err == nil {
+					err = errKey
+				}
+			}
+			a.setRules(rules, keyUpdated)
+		}()
+		if input == "" {
+			return
+		}
+		if a.parent.header == "" || a.parent.algorithm == "" {
+			return fmt.Errorf("invalid hmac-header or hmac-algorithm annotation")
+		}
+		var secret *store.Secret
+		secret, err = a.parent.k8s.FetchSecret(input, a.parent.namespace)
+		if err != nil {
+			return
+		}
+		key, ok := secret.Data["secret"]
+		if secret.Status == store.DELETED || !ok || len(key) == 0 {
+			return fmt.Errorf("secret '%s': 'secret' key not found", input)
+		}
+		var keyFile string
+		keyFile, keyUpdated, err = a.writeKey(key)
+		if err != nil {
+			return
+		}
+		// the signature may be prefixed by the algorithm, as in "sha256=<hex>"
+		rules = []string{
+			"option http-buffer-request",
+			fmt.Sprintf("http-request set-var(txn.hmac_key) str(key),map(%s)", keyFile),
+			fmt.Sprintf("http-request set-var(txn.hmac_expected) req.body,hmac(%s,txn.hmac_key),hex,lower", a.parent.algorithm),
+			fmt.Sprintf("http-request deny deny_status 401 unless { req.hdr(%s),regsub(^[a-z0-9]+=,),lower,secure_memcmp(txn.hmac_expected) }", a.parent.header),
+		}
+	default:
+		err = fmt.Errorf("unknown hmac annotation '%s'", a.name)
+	}
+	return
+}
+
+// keyFile returns the map file of the backend HMAC key
+func (a HMACAnn) keyFile() string {
+	return filepath.Join(secretDir, "hmac-"+a.parent.backend.Name+".map")
+}
+
+// writeKey writes the key to the backend key file, readable by the controller user only,
+// updated is true when the file content changed and HAProxy needs a reload to load it.
+func (a HMACAnn) writeKey(key []byte) (file string, updated bool, err error) {
+	if secretDir == "" {
+		return "", false, fmt.Errorf("HMAC key directory not set")
+	}
+	file = a.keyFile()
+	content := []byte("key " + base64.StdEncoding.EncodeToString(key) + "\n")
+	current, err := ioutil.ReadFile(file)
+	if err == nil && bytes.Equal(current, content) {
+		return file, false, nil
+	}
+	if err = renameio.WriteFile(file, content, 0600); err != nil {
+		return "", false, err
+	}
+	return file, true, nil
+}
+
+// removeKey removes the backend key file, if any
+func (a HMACAnn) removeKey() error {
+	if secretDir == "" {
+		return nil
+	}
+	if err := os.Remove(a.keyFile()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (a HMACAnn) setRules(rules []string, keyUpdated bool) {
+	data, ok := cfgSnippet.backends[a.parent.backend.Name]
+	if !ok {
+		data = &cfgData{}
+		cfgSnippet.backends[a.parent.backend.Name] = data
+	}
+	if keyUpdated || !reflect.DeepEqual(data.hmac, rules) {
+		data.hmac = rules
+		data.toUpdate = true
+	}
+}
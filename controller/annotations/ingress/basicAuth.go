@@ -57,6 +57,17 @@ func (a ReqAuthAnn) Process(input string) (err error) {
 			return
 		}
 		a.parent.authRule.AuthRealm = strings.ReplaceAll(input, " ", "-")
+	case "auth-exclude-paths":
+		if a.parent.authRule == nil {
+			return
+		}
+		paths := strings.Fields(strings.ReplaceAll(input, ",", " "))
+		for _, path := range paths {
+			if path[0] != '/' {
+				return fmt.Errorf("incorrect path '%s' in auth-exclude-paths annotation", path)
+			}
+		}
+		a.parent.authRule.ExcludePaths = paths
 	case "auth-secret":
 		if a.parent.authRule == nil {
 			return
@@ -0,0 +1,44 @@
+package ingress
+
+import (
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+type ReqConnLimit struct {
+	name    string
+	rules   *haproxy.Rules
+	ingress store.Ingress
+}
+
+func NewReqConnLimit(n string, rules *haproxy.Rules, i store.Ingress) *ReqConnLimit {
+	return &ReqConnLimit{name: n, rules: rules, ingress: i}
+}
+
+func (a *ReqConnLimit) GetName() string {
+	return a.name
+}
+
+func (a *ReqConnLimit) Process(input string) (err error) {
+	if input == "" {
+		return
+	}
+	var value int64
+	value, err = utils.ParseInt(input)
+	if err != nil {
+		return
+	}
+	// Connections are tracked once per frontend when accepted,
+	// the limit applies to the requests of the route.
+	tableName := "ConnLimit"
+	a.rules.Add(&rules.ReqConnTrack{
+		TableName: tableName,
+	})
+	a.rules.Add(&rules.ReqConnLimit{
+		TableName: tableName,
+		ConnLimit: value,
+	})
+	return
+}
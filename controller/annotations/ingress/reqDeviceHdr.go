@@ -0,0 +1,54 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// DeviceHdr exposes the properties of the device detection module as request headers,
+// one "X-Device-<property>" header per property.
+type DeviceHdr struct {
+	name       string
+	rules      *haproxy.Rules
+	module     string
+	properties []string
+}
+
+func NewReqDeviceHdr(n string, rules *haproxy.Rules, module string, properties []string) *DeviceHdr {
+	return &DeviceHdr{name: n, rules: rules, module: module, properties: properties}
+}
+
+func (a *DeviceHdr) GetName() string {
+	return a.name
+}
+
+func (a *DeviceHdr) Process(input string) (err error) {
+	if input == "" {
+		return
+	}
+	enabled, err := utils.GetBoolValue(input, a.name)
+	if err != nil || !enabled {
+		return
+	}
+	if a.module == "" {
+		return fmt.Errorf("device detection is not enabled, see device-detection ConfigMap option")
+	}
+	if len(a.properties) == 0 {
+		return fmt.Errorf("no device property configured, see device-detection-properties ConfigMap option")
+	}
+	fetch := "51d.all"
+	if a.module == "deviceatlas" {
+		fetch = "da-csv-fetch"
+	}
+	for _, property := range a.properties {
+		a.rules.Add(&rules.SetHdr{
+			HdrName:   "X-Device-" + strings.ReplaceAll(property, ".", "-"),
+			HdrFormat: fmt.Sprintf("%%[%s(%s)]", fetch, property),
+		})
+	}
+	return
+}
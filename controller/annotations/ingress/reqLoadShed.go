@@ -0,0 +1,38 @@
+package ingress
+
+import (
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+type ReqLoadShed struct {
+	name  string
+	rules *haproxy.Rules
+}
+
+func NewReqLoadShed(n string, rules *haproxy.Rules) *ReqLoadShed {
+	return &ReqLoadShed{name: n, rules: rules}
+}
+
+func (a *ReqLoadShed) GetName() string {
+	return a.name
+}
+
+// Process marks the requests of non-critical routes with the "txn.load_shed" variable,
+// the backends breaching their "slo-response-time" or "slo-queue" deny them with a 503.
+func (a *ReqLoadShed) Process(input string) error {
+	if input == "" {
+		return nil
+	}
+	enabled, err := utils.GetBoolValue(input, a.name)
+	if err != nil || !enabled {
+		return err
+	}
+	a.rules.Add(&rules.ReqSetVar{
+		Name:       "load_shed",
+		Scope:      "txn",
+		Expression: "bool(1)",
+	})
+	return nil
+}
@@ -0,0 +1,160 @@
+package ingress
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/renameio"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// secretDir is the directory of the secrets HAProxy loads from files
+var secretDir string
+
+// SetSecretDir sets the directory OIDC client secrets are written to, so that they are not part of the configuration
+func SetSecretDir(dir string) {
+	secretDir = dir
+}
+
+type ReqOIDC struct {
+	oidc    *rules.ReqOIDC
+	rules   *haproxy.Rules
+	k8s     store.K8s
+	ingress store.Ingress
+}
+
+type ReqOIDCAnn struct {
+	name   string
+	parent *ReqOIDC
+}
+
+func NewReqOIDC(rules *haproxy.Rules, i store.Ingress, k store.K8s) *ReqOIDC {
+	return &ReqOIDC{rules: rules, ingress: i, k8s: k}
+}
+
+func (p *ReqOIDC) NewAnnotation(n string) ReqOIDCAnn {
+	return ReqOIDCAnn{name: n, parent: p}
+}
+
+func (a ReqOIDCAnn) GetName() string {
+	return a.name
+}
+
+// Process enables OIDC authentication with "oidc-issuer", the other annotations set its options.
+// Values are passed as arguments of a Lua action so quotes and spaces are not allowed.
+// The client secret is written to a file of the secret directory HAProxy loads it from.
+func (a ReqOIDCAnn) Process(input string) (err error) {
+	if input == "" {
+		return
+	}
+	if a.name != "oidc-issuer" && a.parent.oidc == nil {
+		return
+	}
+	if strings.ContainsAny(input, " \t\n\"'") {
+		return fmt.Errorf("incorrect value '%s' in %s annotation", input, a.name)
+	}
+	switch a.name {
+	case "oidc-issuer":
+		if !strings.HasPrefix(input, "https://") {
+			return fmt.Errorf("incorrect issuer '%s', an https URL is required", input)
+		}
+		a.parent.oidc = &rules.ReqOIDC{
+			Issuer:        input,
+			RedirectPath:  "/oauth2/callback",
+			RedirectHosts: oidcIngressHosts(a.parent.ingress),
+			CookieName:    "haproxy_oidc",
+			CookieMaxAge:  3600,
+		}
+		a.parent.rules.Add(a.parent.oidc)
+	case "oidc-authorization-endpoint":
+		if !strings.HasPrefix(input, "https://") {
+			return fmt.Errorf("incorrect endpoint '%s', an https URL is required", input)
+		}
+		a.parent.oidc.AuthEndpoint = input
+	case "oidc-token-endpoint":
+		if !strings.HasPrefix(input, "https://") || !strings.Contains(strings.TrimPrefix(input, "https://"), "/") {
+			return fmt.Errorf("incorrect endpoint '%s', an https URL is required", input)
+		}
+		a.parent.oidc.TokenEndpoint = input
+	case "oidc-secret":
+		var secret *store.Secret
+		secret, err = a.parent.k8s.FetchSecret(input, a.parent.ingress.Namespace)
+		if err != nil {
+			return err
+		}
+		if secret.Status == store.DELETED {
+			return
+		}
+		clientID, clientSecret := string(secret.Data["client-id"]), string(secret.Data["client-secret"])
+		if clientID == "" || clientSecret == "" || strings.ContainsAny(clientID+clientSecret, " \t\n\"'") {
+			return fmt.Errorf("secret '%s': valid 'client-id' and 'client-secret' keys are required", input)
+		}
+		var file string
+		if file, err = writeClientSecret(secret, clientSecret); err != nil {
+			return
+		}
+		a.parent.oidc.ClientID = clientID
+		a.parent.oidc.ClientSecretFile = file
+		// the rule is updated, and HAProxy reloaded, when the secret changes
+		a.parent.oidc.ClientSecretHash = utils.Hash([]byte(clientSecret))
+	case "oidc-redirect-path":
+		if input[0] != '/' {
+			return fmt.Errorf("incorrect path '%s' in oidc-redirect-path annotation", input)
+		}
+		a.parent.oidc.RedirectPath = input
+	case "oidc-redirect-host":
+		if strings.ContainsAny(input, "/:,") {
+			return fmt.Errorf("incorrect host '%s' in oidc-redirect-host annotation", input)
+		}
+		a.parent.oidc.RedirectHosts = []string{strings.ToLower(input)}
+	case "oidc-cookie-name":
+		a.parent.oidc.CookieName = input
+	case "oidc-cookie-max-age":
+		var maxAge *int64
+		if maxAge, err = utils.ParseTime(input); err != nil {
+			return
+		}
+		a.parent.oidc.CookieMaxAge = *maxAge / 1000
+	default:
+		err = fmt.Errorf("unknown oidc annotation '%s'", a.name)
+	}
+	return
+}
+
+// oidcIngressHosts returns the sorted hosts of the ingress, allowed in the redirect URI
+func oidcIngressHosts(ingress store.Ingress) []string {
+	hosts := make([]string, 0, len(ingress.Rules))
+	for _, rule := range ingress.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, strings.ToLower(rule.Host))
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// writeClientSecret writes the client secret to a map file of the secret directory,
+// readable by the controller user only, and returns its path.
+func writeClientSecret(secret *store.Secret, clientSecret string) (string, error) {
+	if secretDir == "" {
+		return "", fmt.Errorf("OIDC secret directory not set")
+	}
+	file := filepath.Join(secretDir, fmt.Sprintf("oidc-%s_%s.map", secret.Namespace, secret.Name))
+	content := []byte(rules.OIDC_SECRET_KEY + " " + clientSecret + "\n")
+	current, err := ioutil.ReadFile(file)
+	if err == nil && bytes.Equal(current, content) {
+		return file, nil
+	}
+	if err = renameio.WriteFile(file, content, 0600); err != nil {
+		return "", err
+	}
+	return file, nil
+}
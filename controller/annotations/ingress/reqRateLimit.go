@@ -2,17 +2,22 @@ package ingress
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
 	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 type ReqRateLimit struct {
-	limit *rules.ReqRateLimit
-	track *rules.ReqTrack
-	rules *haproxy.Rules
+	limit   *rules.ReqRateLimit
+	track   *rules.ReqTrack
+	rules   *haproxy.Rules
+	ingress store.Ingress
+	k8s     store.K8s
 }
 
 type ReqRateLimitAnn struct {
@@ -20,8 +25,8 @@ type ReqRateLimitAnn struct {
 	parent *ReqRateLimit
 }
 
-func NewReqRateLimit(rules *haproxy.Rules) *ReqRateLimit {
-	return &ReqRateLimit{rules: rules}
+func NewReqRateLimit(rules *haproxy.Rules, i store.Ingress, k store.K8s) *ReqRateLimit {
+	return &ReqRateLimit{rules: rules, ingress: i, k8s: k}
 }
 
 func (p *ReqRateLimit) NewAnnotation(n string) ReqRateLimitAnn {
@@ -49,13 +54,26 @@ func (a ReqRateLimitAnn) Process(input string) (err error) {
 		a.parent.track = &rules.ReqTrack{TrackKey: "src"}
 		a.parent.rules.Add(a.parent.limit)
 		a.parent.rules.Add(a.parent.track)
+	case "rate-limit-key":
+		if a.parent.limit == nil || a.parent.track == nil {
+			return
+		}
+		a.parent.track.TrackKey = input
+		a.parent.limit.TrackKey = input
 	case "rate-limit-period":
 		if a.parent.limit == nil || a.parent.track == nil {
 			return
 		}
 		var value *int64
 		value, err = utils.ParseTime(input)
+		if err != nil {
+			return err
+		}
 		tableName := fmt.Sprintf("RateLimit-%d", *value)
+		if a.parent.track.TrackKey != "src" {
+			// Tables keyed on other samples than source IP are of type string
+			tableName = fmt.Sprintf("%s-%s", tableName, utils.Hash([]byte(a.parent.track.TrackKey)))
+		}
 		a.parent.track.TablePeriod = value
 		a.parent.track.TableName = tableName
 		a.parent.limit.TableName = tableName
@@ -66,6 +84,13 @@ func (a ReqRateLimitAnn) Process(input string) (err error) {
 		var value *int64
 		value, err = utils.ParseSize(input)
 		a.parent.track.TableSize = value
+	case "rate-limit-expire":
+		if a.parent.limit == nil || a.parent.track == nil {
+			return
+		}
+		var value *int64
+		value, err = utils.ParseTime(input)
+		a.parent.track.TableExpire = value
 	case "rate-limit-status-code":
 		if a.parent.limit == nil || a.parent.track == nil {
 			return
@@ -73,6 +98,18 @@ func (a ReqRateLimitAnn) Process(input string) (err error) {
 		var value int64
 		value, err = utils.ParseInt(input)
 		a.parent.limit.DenyStatusCode = value
+		if value == http.StatusTooManyRequests && a.parent.track.TablePeriod != nil {
+			// Retry-After is in seconds, rounded up
+			a.parent.limit.RetryAfter = utils.PtrInt64((*a.parent.track.TablePeriod + 999) / 1000)
+		}
+	case "rate-limit-map":
+		if a.parent.limit == nil || a.parent.track == nil || input == "" {
+			return
+		}
+		if err = a.parent.k8s.CheckPatternFilesRef(a.parent.ingress.Namespace); err != nil {
+			return
+		}
+		a.parent.limit.LimitsMap = "patterns/" + strings.TrimPrefix(input, "patterns/")
 	default:
 		err = fmt.Errorf("unknown rate-limit annotation '%s'", a.name)
 	}
@@ -0,0 +1,34 @@
+package ingress
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+)
+
+type ReqSNICheck struct {
+	name  string
+	rules *haproxy.Rules
+}
+
+func NewReqSNICheck(n string, rules *haproxy.Rules) *ReqSNICheck {
+	return &ReqSNICheck{name: n, rules: rules}
+}
+
+func (a *ReqSNICheck) GetName() string {
+	return a.name
+}
+
+func (a *ReqSNICheck) Process(input string) (err error) {
+	switch input {
+	case "", "disabled":
+	case "log":
+		a.rules.Add(&rules.ReqSNICheck{})
+	case "reject":
+		a.rules.Add(&rules.ReqSNICheck{Reject: true})
+	default:
+		err = fmt.Errorf("%s: unknown value '%s', expected 'log', 'reject' or 'disabled'", a.name, input)
+	}
+	return
+}
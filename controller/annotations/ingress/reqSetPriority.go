@@ -0,0 +1,94 @@
+package ingress
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// Bounds of HAProxy priority class and priority offset (in milliseconds)
+const (
+	maxPriorityClass  = 2047
+	maxPriorityOffset = 524287
+)
+
+type ReqSetPriority struct {
+	name  string
+	rules *haproxy.Rules
+}
+
+func NewReqSetPriority(n string, rules *haproxy.Rules) *ReqSetPriority {
+	return &ReqSetPriority{name: n, rules: rules}
+}
+
+func (a *ReqSetPriority) GetName() string {
+	return a.name
+}
+
+// Input is one priority per line, "class" followed by an integer where lower classes are dequeued first,
+// or "offset" followed by a time subtracted from the queue time of the request, with an optional condition.
+// Example:
+//
+//	request-priority: |
+//	  class -10 if { path /healthz }
+//	  offset -2s if { req.hdr(X-Api-Tier) -m str paid }
+func (a *ReqSetPriority) Process(input string) (err error) {
+	for _, line := range strings.Split(input, "\n") {
+		params := strings.Fields(line)
+		if len(params) == 0 {
+			continue
+		}
+		var rule rules.ReqSetPriority
+		rule, err = parsePriority(params)
+		if err != nil {
+			return fmt.Errorf("incorrect value '%s' in %s annotation: %w", line, a.name, err)
+		}
+		a.rules.Add(&rule)
+	}
+	return
+}
+
+func parsePriority(params []string) (rule rules.ReqSetPriority, err error) {
+	if len(params) < 2 {
+		return rule, fmt.Errorf("class or offset followed by a value is expected")
+	}
+	switch len(params) {
+	case 2:
+	case 3:
+		return rule, fmt.Errorf("missing condition after '%s'", params[2])
+	default:
+		if params[2] != "if" {
+			return rule, fmt.Errorf("'if' expected instead of '%s'", params[2])
+		}
+		rule.CondTest = strings.Join(params[3:], " ")
+	}
+	switch params[0] {
+	case "class":
+		rule.Value, err = strconv.ParseInt(params[1], 10, 64)
+		if err != nil || rule.Value < -maxPriorityClass || rule.Value > maxPriorityClass {
+			return rule, fmt.Errorf("class must be an integer between %d and %d", -maxPriorityClass, maxPriorityClass)
+		}
+	case "offset":
+		rule.Offset = true
+		value := strings.TrimPrefix(params[1], "-")
+		var offset *int64
+		offset, err = utils.ParseTime(value)
+		if err != nil {
+			return rule, err
+		}
+		if *offset > maxPriorityOffset {
+			return rule, fmt.Errorf("offset exceeds the maximum of %dms", maxPriorityOffset)
+		}
+		rule.Value = *offset
+		if value != params[1] {
+			rule.Value = -rule.Value
+		}
+	default:
+		return rule, fmt.Errorf("unknown priority '%s', class or offset are expected", params[0])
+	}
+	return rule, nil
+}
@@ -0,0 +1,88 @@
+package ingress
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// maxSpikeArrestDelay bounds the time a request can be delayed, in milliseconds
+const maxSpikeArrestDelay = 60000
+
+type ReqSpikeArrest struct {
+	arrest *rules.ReqSpikeArrest
+	rules  *haproxy.Rules
+}
+
+type ReqSpikeArrestAnn struct {
+	name   string
+	parent *ReqSpikeArrest
+}
+
+func NewReqSpikeArrest(rules *haproxy.Rules) *ReqSpikeArrest {
+	return &ReqSpikeArrest{rules: rules}
+}
+
+func (p *ReqSpikeArrest) NewAnnotation(n string) ReqSpikeArrestAnn {
+	return ReqSpikeArrestAnn{
+		name:   n,
+		parent: p,
+	}
+}
+
+func (a ReqSpikeArrestAnn) GetName() string {
+	return a.name
+}
+
+func (a ReqSpikeArrestAnn) Process(input string) (err error) {
+	if input == "" {
+		return nil
+	}
+
+	switch a.name {
+	case "spike-arrest-requests":
+		// Enable spike arrest, by default requests over the rate per second are delayed by 500ms
+		var value int64
+		value, err = strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return
+		}
+		a.parent.arrest = &rules.ReqSpikeArrest{
+			TableName:   "SpikeArrest-1000",
+			TablePeriod: utils.PtrInt64(1000),
+			ReqsLimit:   value,
+			Delay:       500,
+		}
+		a.parent.rules.Add(a.parent.arrest)
+	case "spike-arrest-period":
+		if a.parent.arrest == nil {
+			return
+		}
+		var value *int64
+		value, err = utils.ParseTime(input)
+		if err != nil {
+			return
+		}
+		a.parent.arrest.TablePeriod = value
+		a.parent.arrest.TableName = fmt.Sprintf("SpikeArrest-%d", *value)
+	case "spike-arrest-delay":
+		if a.parent.arrest == nil {
+			return
+		}
+		var value *int64
+		value, err = utils.ParseTime(input)
+		if err != nil {
+			return
+		}
+		if *value > maxSpikeArrestDelay {
+			return fmt.Errorf("delay exceeds the maximum of %dms", maxSpikeArrestDelay)
+		}
+		a.parent.arrest.Delay = *value
+	default:
+		err = fmt.Errorf("unknown spike-arrest annotation '%s'", a.name)
+	}
+	return
+}
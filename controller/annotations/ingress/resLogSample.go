@@ -0,0 +1,38 @@
+package ingress
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+)
+
+type ResLogSample struct {
+	name  string
+	rules *haproxy.Rules
+}
+
+func NewResLogSample(n string, rules *haproxy.Rules) *ResLogSample {
+	return &ResLogSample{name: n, rules: rules}
+}
+
+func (a *ResLogSample) GetName() string {
+	return a.name
+}
+
+func (a *ResLogSample) Process(input string) (err error) {
+	if input == "" {
+		return
+	}
+	percent, err := strconv.ParseInt(strings.TrimSuffix(input, "%"), 10, 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return fmt.Errorf("%s: incorrect value '%s', a percentage between 0 and 100 is expected", a.name, input)
+	}
+	if percent == 100 {
+		return
+	}
+	a.rules.Add(&rules.ResLogSample{Percent: percent})
+	return
+}
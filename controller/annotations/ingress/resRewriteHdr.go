@@ -0,0 +1,92 @@
+package ingress
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+)
+
+// ResRewriteHdr rewrites Location and Set-Cookie response headers,
+// so backends served behind a rewritten path or host keep working.
+type ResRewriteHdr struct {
+	name   string
+	rules  *haproxy.Rules
+	cookie bool
+}
+
+func NewResRewriteLocation(n string, rules *haproxy.Rules) *ResRewriteHdr {
+	return &ResRewriteHdr{name: n, rules: rules}
+}
+
+func NewResRewriteCookie(n string, rules *haproxy.Rules) *ResRewriteHdr {
+	return &ResRewriteHdr{name: n, rules: rules, cookie: true}
+}
+
+func (a *ResRewriteHdr) GetName() string {
+	return a.name
+}
+
+// Input is one rewrite per line.
+// response-location-rewrite lines are a regex and its replacement: "^/(.*) /app/\1"
+// response-cookie-rewrite lines are the attribute, the current and the new value: "path / /app/" or "domain backend.local example.com"
+func (a *ResRewriteHdr) Process(input string) (err error) {
+	for _, line := range strings.Split(input, "\n") {
+		params := strings.Fields(line)
+		if len(params) == 0 {
+			continue
+		}
+		var rule *rules.ResReplaceHdr
+		if a.cookie {
+			rule, err = cookieRewrite(params)
+		} else {
+			rule, err = locationRewrite(params)
+		}
+		if err != nil {
+			return fmt.Errorf("incorrect value '%s' in %s annotation: %w", line, a.name, err)
+		}
+		a.rules.Add(rule)
+	}
+	return
+}
+
+func locationRewrite(params []string) (*rules.ResReplaceHdr, error) {
+	if len(params) != 2 {
+		return nil, fmt.Errorf("a regex and its replacement are expected")
+	}
+	if _, err := regexp.Compile(params[0]); err != nil {
+		return nil, err
+	}
+	return &rules.ResReplaceHdr{
+		HdrName:   "Location",
+		HdrMatch:  params[0],
+		HdrFormat: params[1],
+	}, nil
+}
+
+func cookieRewrite(params []string) (*rules.ResReplaceHdr, error) {
+	if len(params) != 3 {
+		return nil, fmt.Errorf("an attribute, its current and its new value are expected")
+	}
+	current := regexp.QuoteMeta(params[1])
+	switch strings.ToLower(params[0]) {
+	case "path":
+		// path prefix is replaced
+		return &rules.ResReplaceHdr{
+			HdrName:   "Set-Cookie",
+			HdrMatch:  `^(.*;\s*[Pp][Aa][Tt][Hh]=)` + current + `(.*)$`,
+			HdrFormat: `\1` + params[2] + `\2`,
+		}, nil
+	case "domain":
+		// whole domain is replaced
+		return &rules.ResReplaceHdr{
+			HdrName:   "Set-Cookie",
+			HdrMatch:  `^(.*;\s*[Dd][Oo][Mm][Aa][Ii][Nn]=)` + current + `(;.*|)$`,
+			HdrFormat: `\1` + params[2] + `\2`,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown attribute '%s', path or domain are expected", params[0])
+	}
+}
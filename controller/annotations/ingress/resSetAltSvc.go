@@ -0,0 +1,52 @@
+package ingress
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+)
+
+// altSvcEntry matches an alternative service of the Alt-Svc header with its parameters,
+// for example: h3=":443"; ma=86400
+var altSvcEntry = regexp.MustCompile(`^[A-Za-z0-9.-]+="[^"]*:[0-9]+"(\s*;\s*[a-z]+=[^;]+)*$`)
+
+type ResSetAltSvc struct {
+	name  string
+	rules *haproxy.Rules
+}
+
+func NewResSetAltSvc(n string, rules *haproxy.Rules) *ResSetAltSvc {
+	return &ResSetAltSvc{name: n, rules: rules}
+}
+
+func (a *ResSetAltSvc) GetName() string {
+	return a.name
+}
+
+// Process advertises the alternative services, such as HTTP/3 endpoints, in the Alt-Svc
+// header of the responses. The value is single quoted as it contains double quotes.
+func (a *ResSetAltSvc) Process(input string) error {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil
+	}
+	if strings.ContainsAny(input, "'%\n") {
+		return fmt.Errorf("incorrect value '%s' in %s annotation", input, a.name)
+	}
+	if input != "clear" {
+		for _, entry := range strings.Split(input, ",") {
+			if !altSvcEntry.MatchString(strings.TrimSpace(entry)) {
+				return fmt.Errorf("incorrect alternative service '%s' in %s annotation", entry, a.name)
+			}
+		}
+	}
+	a.rules.Add(&rules.SetHdr{
+		HdrName:   "Alt-Svc",
+		HdrFormat: "'" + input + "'",
+		Response:  true,
+	})
+	return nil
+}
@@ -113,14 +113,15 @@ func (a ResSetCORSAnn) Process(input string) (err error) {
 		if a.parent.acl == "" {
 			return
 		}
-		var duration *int64
-		duration, err = utils.ParseTime(input)
-		if err != nil {
-			return
-		}
-		maxage := *duration / 1000
-		if maxage < -1 {
-			return fmt.Errorf("invalid cors-max-age value %d", maxage)
+		// -1 disables caching
+		maxage := int64(-1)
+		if input != "-1" && input != "-1s" {
+			var duration *int64
+			duration, err = utils.ParseTime(input)
+			if err != nil {
+				return
+			}
+			maxage = *duration / 1000
 		}
 		a.parent.rules.Add(&rules.SetHdr{
 			HdrName:   "Access-Control-Max-Age",
@@ -0,0 +1,85 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+type ResSetCSP struct {
+	rules      *haproxy.Rules
+	ingress    store.Ingress
+	k8s        store.K8s
+	reportOnly bool
+}
+
+type ResSetCSPAnn struct {
+	name   string
+	parent *ResSetCSP
+}
+
+func NewResSetCSP(rules *haproxy.Rules, i store.Ingress, k store.K8s) *ResSetCSP {
+	return &ResSetCSP{rules: rules, ingress: i, k8s: k}
+}
+
+func (p *ResSetCSP) NewAnnotation(n string) ResSetCSPAnn {
+	return ResSetCSPAnn{
+		name:   n,
+		parent: p,
+	}
+}
+
+func (a ResSetCSPAnn) GetName() string {
+	return a.name
+}
+
+// "content-security-policy-report-only" is processed before "content-security-policy".
+// The policy is the annotation value or, with "patterns/<key>", the value of this key
+// in the pattern files ConfigMap, where long policies can be split over several lines.
+func (a ResSetCSPAnn) Process(input string) (err error) {
+	switch a.name {
+	case "content-security-policy-report-only":
+		a.parent.reportOnly = false
+		if input != "" {
+			a.parent.reportOnly, err = utils.GetBoolValue(input, a.name)
+		}
+	case "content-security-policy":
+		policy := input
+		if strings.HasPrefix(input, "patterns/") {
+			key := strings.TrimPrefix(input, "patterns/")
+			if err = a.parent.k8s.CheckPatternFilesRef(a.parent.ingress.Namespace); err != nil {
+				return
+			}
+			var ok bool
+			if a.parent.k8s.ConfigMaps.PatternFiles != nil {
+				policy, ok = a.parent.k8s.ConfigMaps.PatternFiles.Annotations[key]
+			}
+			if !ok {
+				return fmt.Errorf("key '%s' not found in pattern files ConfigMap", key)
+			}
+		}
+		policy = strings.Join(strings.Fields(policy), " ")
+		if policy == "" {
+			return
+		}
+		if strings.Contains(policy, "\"") {
+			return fmt.Errorf("incorrect policy '%s': double quotes are not allowed", policy)
+		}
+		header := "Content-Security-Policy"
+		if a.parent.reportOnly {
+			header = "Content-Security-Policy-Report-Only"
+		}
+		a.parent.rules.Add(&rules.SetHdr{
+			HdrName:   header,
+			HdrFormat: "\"" + strings.ReplaceAll(policy, "%", "%%") + "\"",
+			Response:  true,
+		})
+	default:
+		err = fmt.Errorf("unknown content-security-policy annotation '%s'", a.name)
+	}
+	return
+}
@@ -0,0 +1,82 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// cacheCondition prevents caching of error responses
+const cacheCondition = "{ status lt 400 }"
+
+type ResSetCache struct {
+	rules   *haproxy.Rules
+	control string
+}
+
+type ResSetCacheAnn struct {
+	name   string
+	parent *ResSetCache
+}
+
+func NewResSetCache(rules *haproxy.Rules) *ResSetCache {
+	return &ResSetCache{rules: rules}
+}
+
+func (p *ResSetCache) NewAnnotation(n string) ResSetCacheAnn {
+	return ResSetCacheAnn{
+		name:   n,
+		parent: p,
+	}
+}
+
+func (a ResSetCacheAnn) GetName() string {
+	return a.name
+}
+
+// Cache-Control and Expires headers are overridden in successful responses,
+// "cache-control" is processed before "cache-max-age" which adds the rules.
+func (a ResSetCacheAnn) Process(input string) (err error) {
+	switch a.name {
+	case "cache-control":
+		if strings.ContainsAny(input, "\"\n") {
+			return fmt.Errorf("incorrect value '%s' in cache-control annotation", input)
+		}
+		a.parent.control = strings.TrimSpace(input)
+	case "cache-max-age":
+		control := a.parent.control
+		if input != "" {
+			var duration *int64
+			duration, err = utils.ParseTime(input)
+			if err != nil {
+				return
+			}
+			maxage := *duration / 1000
+			if control == "" {
+				control = "public"
+			}
+			control = fmt.Sprintf("%s, max-age=%d", control, maxage)
+			a.parent.rules.Add(&rules.SetHdr{
+				HdrName:   "Expires",
+				HdrFormat: fmt.Sprintf("%%[date(%d),http_date]", maxage),
+				Response:  true,
+				CondTest:  cacheCondition,
+			})
+		}
+		if control == "" {
+			return
+		}
+		a.parent.rules.Add(&rules.SetHdr{
+			HdrName:   "Cache-Control",
+			HdrFormat: "\"" + control + "\"",
+			Response:  true,
+			CondTest:  cacheCondition,
+		})
+	default:
+		err = fmt.Errorf("unknown cache annotation '%s'", a.name)
+	}
+	return
+}
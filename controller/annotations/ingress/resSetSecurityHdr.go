@@ -0,0 +1,88 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+)
+
+// securityHeaders are the response headers of the "strict" preset with their value,
+// each one can be overridden by the annotation named after it.
+var securityHeaders = []struct {
+	annotation string
+	header     string
+	strict     string
+}{
+	{"security-header-x-content-type-options", "X-Content-Type-Options", "nosniff"},
+	{"security-header-x-frame-options", "X-Frame-Options", "DENY"},
+	{"security-header-referrer-policy", "Referrer-Policy", "strict-origin-when-cross-origin"},
+	{"security-header-permissions-policy", "Permissions-Policy", "camera=(), microphone=(), geolocation=(), payment=()"},
+	{"security-header-cross-origin-opener-policy", "Cross-Origin-Opener-Policy", "same-origin"},
+	{"security-header-cross-origin-embedder-policy", "Cross-Origin-Embedder-Policy", "require-corp"},
+}
+
+type ResSetSecurityHdr struct {
+	rules     *haproxy.Rules
+	overrides map[string]string
+}
+
+type ResSetSecurityHdrAnn struct {
+	name   string
+	parent *ResSetSecurityHdr
+}
+
+func NewResSetSecurityHdr(rules *haproxy.Rules) *ResSetSecurityHdr {
+	return &ResSetSecurityHdr{rules: rules, overrides: make(map[string]string)}
+}
+
+func (p *ResSetSecurityHdr) NewAnnotation(n string) ResSetSecurityHdrAnn {
+	return ResSetSecurityHdrAnn{
+		name:   n,
+		parent: p,
+	}
+}
+
+func (a ResSetSecurityHdrAnn) GetName() string {
+	return a.name
+}
+
+// Overrides are set with the header value, "none" removing the header from the preset.
+// They are processed before "security-headers" which adds the rules.
+func (a ResSetSecurityHdrAnn) Process(input string) error {
+	if a.name != "security-headers" {
+		if strings.ContainsAny(input, "\"\n") {
+			return fmt.Errorf("incorrect value '%s' in %s annotation", input, a.name)
+		}
+		if input = strings.TrimSpace(input); input != "" {
+			a.parent.overrides[a.name] = input
+		}
+		return nil
+	}
+	var preset bool
+	switch input {
+	case "", "none":
+	case "strict":
+		preset = true
+	default:
+		return fmt.Errorf("unknown security-headers preset '%s'", input)
+	}
+	for _, h := range securityHeaders {
+		value, ok := a.parent.overrides[h.annotation]
+		switch {
+		case ok && value == "none":
+			continue
+		case !ok && !preset:
+			continue
+		case !ok:
+			value = h.strict
+		}
+		a.parent.rules.Add(&rules.SetHdr{
+			HdrName:   h.header,
+			HdrFormat: "\"" + value + "\"",
+			Response:  true,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package ingress
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+var contentType = regexp.MustCompile(`^[a-z]+/[a-z0-9.+-]+$`)
+
+type ResSetStatus struct {
+	name    string
+	rules   *haproxy.Rules
+	maps    haproxy.Maps
+	ingress store.Ingress
+	k8s     store.K8s
+}
+
+func NewResSetStatus(n string, rules *haproxy.Rules, m haproxy.Maps, i store.Ingress, k store.K8s) *ResSetStatus {
+	return &ResSetStatus{name: n, rules: rules, maps: m, ingress: i, k8s: k}
+}
+
+func (a *ResSetStatus) GetName() string {
+	return a.name
+}
+
+// Process parses one rewrite per line in the following format:
+// "<backend-status> <status> [<content-type> patterns/<file>]",
+// the response body being replaced by the pattern file when provided.
+// Pattern files are listed in the "status-reply" map for HAProxy to read them when loading its configuration.
+func (a *ResSetStatus) Process(input string) error {
+	for _, line := range strings.Split(input, "\n") {
+		params := strings.Fields(line)
+		if len(params) == 0 {
+			continue
+		}
+		if len(params) != 2 && len(params) != 4 {
+			return fmt.Errorf("incorrect value '%s', expected '<backend-status> <status> [<content-type> patterns/<file>]'", line)
+		}
+		var codes [2]int64
+		for i := range codes {
+			code, err := strconv.ParseInt(params[i], 10, 64)
+			if err != nil || code < 100 || code > 599 {
+				return fmt.Errorf("invalid status code '%s'", params[i])
+			}
+			codes[i] = code
+		}
+		rule := &rules.ResSetStatus{BackendStatus: codes[0], Status: codes[1]}
+		if len(params) == 4 {
+			if !contentType.MatchString(params[2]) {
+				return fmt.Errorf("invalid content type '%s'", params[2])
+			}
+			file, err := patternFile(params[3])
+			if err != nil {
+				return err
+			}
+			if err = a.k8s.CheckPatternFilesRef(a.ingress.Namespace); err != nil {
+				return err
+			}
+			rule.ContentType = params[2]
+			rule.File = file
+			a.maps.AppendRow(config.StatusReplyMap, file)
+		}
+		a.rules.Add(rule)
+	}
+	return nil
+}
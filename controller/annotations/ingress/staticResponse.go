@@ -0,0 +1,99 @@
+package ingress
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+// patternDir is the directory of the pattern files
+var patternDir string
+
+// SetPatternDir sets the directory pattern files referenced by annotations are resolved in
+func SetPatternDir(dir string) {
+	patternDir = dir
+}
+
+// patternFile returns the path of a pattern file referenced as "patterns/<file>",
+// file names with a path are refused so that files out of the pattern directory are not served.
+func patternFile(ref string) (string, error) {
+	name := strings.TrimPrefix(ref, "patterns/")
+	if name == ref || name == "" || name == "." || strings.Contains(name, "..") || strings.ContainsAny(name, "/\\\x00") {
+		return "", fmt.Errorf("incorrect body file '%s', expected 'patterns/<file>'", ref)
+	}
+	if patternDir == "" {
+		return "", fmt.Errorf("pattern files directory not set")
+	}
+	dir := filepath.Clean(patternDir)
+	path := filepath.Clean(filepath.Join(dir, name))
+	if filepath.Dir(path) != dir {
+		return "", fmt.Errorf("incorrect body file '%s', expected 'patterns/<file>'", ref)
+	}
+	return path, nil
+}
+
+type StaticResponse struct {
+	name    string
+	rules   *haproxy.Rules
+	ingress store.Ingress
+	k8s     store.K8s
+}
+
+func NewStaticResponse(n string, rules *haproxy.Rules, i store.Ingress, k store.K8s) *StaticResponse {
+	return &StaticResponse{name: n, rules: rules, ingress: i, k8s: k}
+}
+
+func (a *StaticResponse) GetName() string {
+	return a.name
+}
+
+// Process parses input in the following format:
+// first line is "<status-code> [<content-type>] [patterns/<file>]",
+// next lines are the response body when no pattern file is provided.
+func (a *StaticResponse) Process(input string) (err error) {
+	if input == "" {
+		return
+	}
+	if a.ingress.Name == "" {
+		return fmt.Errorf("static responses are only available at the ingress level")
+	}
+	lines := strings.SplitN(input, "\n", 2)
+	params := strings.Fields(lines[0])
+	if len(params) == 0 || len(params) > 3 {
+		return fmt.Errorf("incorrect value '%s', expected '<status-code> [<content-type>] [patterns/<file>]'", lines[0])
+	}
+	var code int64
+	code, err = strconv.ParseInt(params[0], 10, 64)
+	if err != nil || code < 200 || code > 599 {
+		return fmt.Errorf("invalid status code '%s'", params[0])
+	}
+	rule := &rules.ReqReturn{StatusCode: code, ContentType: "text/plain"}
+	if len(params) > 1 {
+		rule.ContentType = params[1]
+	}
+	switch {
+	case len(params) == 3:
+		var file string
+		if file, err = patternFile(params[2]); err != nil {
+			return
+		}
+		if err = a.k8s.CheckPatternFilesRef(a.ingress.Namespace); err != nil {
+			return
+		}
+		rule.ContentFormat = "file"
+		rule.Content = file
+	case len(lines) == 2:
+		rule.ContentFormat = "string"
+		rule.Content = strconv.Quote(lines[1])
+	default:
+		// content-type is only allowed with a body
+		rule.ContentType = ""
+	}
+	a.rules.Add(rule)
+	return
+}
@@ -0,0 +1,227 @@
+package annotations
+
+import "strings"
+
+// known are the names of the annotations processed by the controller,
+// new annotations must be added here as well as in documentation/doc.yaml.
+var known = map[string]struct{}{
+	"abortonclose":                        {},
+	"affinity":                            {},
+	"affinity-timeout":                    {},
+	"agent-check-interval":                {},
+	"agent-check-port":                    {},
+	"agent-check-send":                    {},
+	"alert-error-rate":                    {},
+	"alert-queue":                         {},
+	"alt-svc":                             {},
+	"auth-exclude-paths":                  {},
+	"auth-realm":                          {},
+	"auth-secret":                         {},
+	"auth-type":                           {},
+	"backend-config-snippet":              {},
+	"backend-name":                        {},
+	"backend-server-state":                {},
+	"blacklist":                           {},
+	"cache-control":                       {},
+	"cache-max-age":                       {},
+	"canary":                              {},
+	"canary-by-header":                    {},
+	"canary-by-header-value":              {},
+	"canary-weight":                       {},
+	"canary-weight-total":                 {},
+	"check":                               {},
+	"check-http":                          {},
+	"check-interval":                      {},
+	"check-sni":                           {},
+	"check-ssl":                           {},
+	"clean-certs":                         {},
+	"client-ca":                           {},
+	"client-crt-optional":                 {},
+	"conn-limit-per-ip":                   {},
+	"content-security-policy":             {},
+	"content-security-policy-report-only": {},
+	"cookie-persistence":                  {},
+	"cors-allow-credentials":              {},
+	"cors-allow-headers":                  {},
+	"cors-allow-methods":                  {},
+	"cors-allow-origin":                   {},
+	"cors-enable":                         {},
+	"cors-max-age":                        {},
+	"cpu-map":                             {},
+	"default-backend-per-host":            {},
+	"default-backend-response":            {},
+	"default-backend-service":             {},
+	"deny-invalid-chars":                  {},
+	"device-detection":                    {},
+	"device-detection-data-file":          {},
+	"device-detection-headers":            {},
+	"device-detection-properties":         {},
+	"dontlognull":                         {},
+	"forwarded-for":                       {},
+	"frontend-config-snippet":             {},
+	"global-config-snippet":               {},
+	"hard-stop-after":                     {},
+	"hash-key":                            {},
+	"hash-type":                           {},
+	"healthz-fail-condition":              {},
+	"healthz-service":                     {},
+	"hmac-algorithm":                      {},
+	"hmac-header":                         {},
+	"hmac-secret":                         {},
+	"http-buffer-request":                 {},
+	"http-keep-alive":                     {},
+	"http-server-close":                   {},
+	"ingress.class":                       {},
+	"internal-tls-alpn":                   {},
+	"load-balance":                        {},
+	"log-format":                          {},
+	"log-sample-normal":                   {},
+	"log-separate-errors":                 {},
+	"logasap":                             {},
+	"max-header-count":                    {},
+	"max-header-size":                     {},
+	"max-uri-length":                      {},
+	"maxconn":                             {},
+	"maxqueue":                            {},
+	"nbthread":                            {},
+	"oidc-authorization-endpoint":         {},
+	"oidc-cookie-max-age":                 {},
+	"oidc-cookie-name":                    {},
+	"oidc-issuer":                         {},
+	"oidc-redirect-host":                  {},
+	"oidc-redirect-path":                  {},
+	"oidc-secret":                         {},
+	"oidc-token-endpoint":                 {},
+	"path-rewrite":                        {},
+	"pod-maxconn":                         {},
+	"proxy-protocol":                      {},
+	"rate-limit-expire":                   {},
+	"rate-limit-key":                      {},
+	"rate-limit-map":                      {},
+	"rate-limit-period":                   {},
+	"rate-limit-requests":                 {},
+	"rate-limit-size":                     {},
+	"rate-limit-status-code":              {},
+	"request-capture":                     {},
+	"request-capture-len":                 {},
+	"request-priority":                    {},
+	"request-redirect":                    {},
+	"request-redirect-code":               {},
+	"request-set-header":                  {},
+	"response-cookie-rewrite":             {},
+	"response-location-rewrite":           {},
+	"response-set-header":                 {},
+	"response-status-rewrite":             {},
+	"route-acl":                           {},
+	"route-acl-cookie":                    {},
+	"rule-priority":                       {},
+	"scale-server-slots":                  {},
+	"security-header-cross-origin-embedder-policy": {},
+	"security-header-cross-origin-opener-policy":   {},
+	"security-header-permissions-policy":           {},
+	"security-header-referrer-policy":              {},
+	"security-header-x-content-type-options":       {},
+	"security-header-x-frame-options":              {},
+	"security-headers":                             {},
+	"send-proxy-protocol":                          {},
+	"server-ca":                                    {},
+	"server-crt":                                   {},
+	"server-maxconn":                               {},
+	"server-proto":                                 {},
+	"server-ssl":                                   {},
+	"set-host":                                     {},
+	"sni-host-check":                               {},
+	"spike-arrest-delay":                           {},
+	"spike-arrest-period":                          {},
+	"spike-arrest-requests":                        {},
+	"spoe-filter":                                  {},
+	"src-ip-header":                                {},
+	"ssl-certificate":                              {},
+	"ssl-passthrough":                              {},
+	"ssl-redirect":                                 {},
+	"ssl-redirect-code":                            {},
+	"ssl-redirect-port":                            {},
+	"static-response":                              {},
+	"stats-config-snippet":                         {},
+	"strict-sni":                                   {},
+	"syslog-format":                                {},
+	"syslog-format-fields":                         {},
+	"syslog-rings":                                 {},
+	"syslog-server":                                {},
+	"timeout-check":                                {},
+	"timeout-client":                               {},
+	"timeout-client-fin":                           {},
+	"timeout-connect":                              {},
+	"timeout-http-keep-alive":                      {},
+	"timeout-http-request":                         {},
+	"timeout-queue":                                {},
+	"timeout-server":                               {},
+	"timeout-server-fin":                           {},
+	"timeout-tunnel":                               {},
+	"tls-alpn":                                     {},
+	"tls-secret-allowed-namespaces":                {},
+	"transparent-proxy":                            {},
+	"tune.bufsize":                                 {},
+	"tune.h2.initial-window-size":                  {},
+	"tune.maxrewrite":                              {},
+	"tune.ssl.cachesize":                           {},
+	"whitelist":                                    {},
+}
+
+// Known returns true if the controller processes the annotation. Service annotations
+// can be prefixed by a port name or number, as in "http.backend-name".
+func Known(name string) bool {
+	if _, ok := known[name]; ok {
+		return true
+	}
+	if i := strings.Index(name, "."); i != -1 {
+		_, ok := known[name[i+1:]]
+		return ok
+	}
+	return false
+}
+
+// Suggest returns the known annotation closest to an unknown one, such as "timeout-server"
+// for "timout-server", or an empty string if none is close enough.
+func Suggest(name string) string {
+	suggestion, distance := "", 3
+	for k := range known {
+		d := editDistance(name, k)
+		// ties are broken by name so that the suggestion is stable
+		if d < distance || (d == distance && suggestion != "" && k < suggestion) {
+			suggestion, distance = k, d
+		}
+	}
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
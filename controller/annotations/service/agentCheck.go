@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// AgentCheck configures the agent-check of servers, an agent listening on the given port
+// reports the weight and state of the server so applications can adjust them dynamically.
+type AgentCheck struct {
+	name   string
+	server *models.Server
+}
+
+func NewAgentCheck(n string, s *models.Server) *AgentCheck {
+	return &AgentCheck{name: n, server: s}
+}
+
+func (a *AgentCheck) GetName() string {
+	return a.name
+}
+
+func (a *AgentCheck) Process(input string) error {
+	switch a.name {
+	case "agent-check-port":
+		if input == "" {
+			a.server.AgentCheck = ""
+			a.server.AgentPort = nil
+			return nil
+		}
+		port, err := strconv.ParseInt(input, 10, 64)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("incorrect port '%s'", input)
+		}
+		a.server.AgentCheck = "enabled"
+		a.server.AgentPort = &port
+	case "agent-check-interval":
+		if input == "" {
+			a.server.AgentInter = nil
+			return nil
+		}
+		value, err := utils.ParseTime(input)
+		if err != nil {
+			return err
+		}
+		a.server.AgentInter = value
+	case "agent-check-send":
+		if strings.ContainsAny(input, " \t\"") {
+			return fmt.Errorf("incorrect value '%s', spaces and quotes are not allowed", input)
+		}
+		a.server.AgentSend = input
+	default:
+		return fmt.Errorf("unknown agent-check annotation '%s'", a.name)
+	}
+	return nil
+}
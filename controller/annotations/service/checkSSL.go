@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// CheckSSL performs the health checks of servers over TLS, so the TLS endpoints of
+// ssl-passthrough backends are checked even though their traffic is not offloaded.
+type CheckSSL struct {
+	name   string
+	server *models.Server
+}
+
+func NewCheckSSL(n string, s *models.Server) *CheckSSL {
+	return &CheckSSL{name: n, server: s}
+}
+
+func (a *CheckSSL) GetName() string {
+	return a.name
+}
+
+func (a *CheckSSL) Process(input string) error {
+	switch a.name {
+	case "check-ssl":
+		var enabled bool
+		var err error
+		if input != "" {
+			enabled, err = utils.GetBoolValue(input, "check-ssl")
+			if err != nil {
+				return err
+			}
+		}
+		if !enabled {
+			a.server.CheckSsl = ""
+			if a.server.Ssl == "" {
+				a.server.Verify = ""
+			}
+			return nil
+		}
+		a.server.CheckSsl = "enabled"
+		// without server-ssl or server-ca, certificates are not verified as for server-ssl
+		if a.server.Verify == "" {
+			a.server.Verify = "none"
+		}
+	case "check-sni":
+		if strings.ContainsAny(input, " \t\"") {
+			return fmt.Errorf("incorrect value '%s', spaces and quotes are not allowed", input)
+		}
+		a.server.CheckSni = input
+	default:
+		return fmt.Errorf("unknown check-ssl annotation '%s'", a.name)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+)
+
+type HashKey struct {
+	name    string
+	backend *models.Backend
+}
+
+func NewHashKey(n string, b *models.Backend) *HashKey {
+	return &HashKey{name: n, backend: b}
+}
+
+func (a *HashKey) GetName() string {
+	return a.name
+}
+
+// Input is the source of the key used to pick the server, it overrides the load-balance algorithm
+// and uses consistent hashing unless hash-type is set so keys keep their server on scale events.
+// ex: "header:X-Image-Id", "url-param:image" or "path:2" for the two first path components
+func (a *HashKey) Process(input string) error {
+	if input == "" {
+		return nil
+	}
+	parts := strings.SplitN(input, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("hash-key: incorrect value '%s'", input)
+	}
+	var balance *models.Balance
+	switch parts[0] {
+	case "header":
+		algorithm := "hdr"
+		balance = &models.Balance{Algorithm: &algorithm, HdrName: parts[1]}
+	case "url-param":
+		algorithm := "url_param"
+		balance = &models.Balance{Algorithm: &algorithm, URLParam: parts[1]}
+	case "path":
+		depth, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || depth < 1 {
+			return fmt.Errorf("hash-key: incorrect path depth '%s'", parts[1])
+		}
+		algorithm := "uri"
+		balance = &models.Balance{Algorithm: &algorithm, URIPathOnly: true, URIDepth: depth}
+	default:
+		return fmt.Errorf("hash-key: unknown key source '%s', header, url-param or path are expected", parts[0])
+	}
+	if err := balance.Validate(nil); err != nil {
+		return fmt.Errorf("hash-key: %w", err)
+	}
+	a.backend.Balance = balance
+	if a.backend.HashType == nil {
+		a.backend.HashType = &models.BackendHashType{Method: "consistent"}
+	}
+	return nil
+}
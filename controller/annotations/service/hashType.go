@@ -0,0 +1,46 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+)
+
+type HashType struct {
+	name    string
+	backend *models.Backend
+}
+
+func NewHashType(n string, b *models.Backend) *HashType {
+	return &HashType{name: n, backend: b}
+}
+
+func (a *HashType) GetName() string {
+	return a.name
+}
+
+// Input is the hash method followed by the optional hash function and modifier,
+// ex: "consistent sdbm avalanche"
+func (a *HashType) Process(input string) error {
+	if input == "" {
+		a.backend.HashType = nil
+		return nil
+	}
+	params := strings.Fields(input)
+	if len(params) > 3 {
+		return fmt.Errorf("hash-type: incorrect value '%s'", input)
+	}
+	hashType := &models.BackendHashType{Method: params[0]}
+	if len(params) > 1 {
+		hashType.Function = params[1]
+	}
+	if len(params) > 2 {
+		hashType.Modifier = params[2]
+	}
+	if err := hashType.Validate(nil); err != nil {
+		return fmt.Errorf("hash-type: %w", err)
+	}
+	a.backend.HashType = hashType
+	return nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+type HTTPBufferRequest struct {
+	name    string
+	backend *models.Backend
+}
+
+func NewHTTPBufferRequest(n string, b *models.Backend) *HTTPBufferRequest {
+	return &HTTPBufferRequest{name: n, backend: b}
+}
+
+func (a *HTTPBufferRequest) GetName() string {
+	return a.name
+}
+
+func (a *HTTPBufferRequest) Process(input string) error {
+	if input == "" {
+		a.backend.HTTPBufferRequest = ""
+		return nil
+	}
+	enabled, err := utils.GetBoolValue(input, "http-buffer-request")
+	if err != nil {
+		return err
+	}
+	if enabled {
+		a.backend.HTTPBufferRequest = "enabled"
+	} else {
+		a.backend.HTTPBufferRequest = "disabled"
+	}
+	return nil
+}
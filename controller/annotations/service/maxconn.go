@@ -9,19 +9,27 @@ import (
 type Maxconn struct {
 	name   string
 	server *models.Server
+	// alias keeps the value of the annotation processed before when not set
+	alias bool
 }
 
 func NewMaxconn(n string, s *models.Server) *Maxconn {
 	return &Maxconn{name: n, server: s}
 }
 
+func NewMaxconnAlias(n string, s *models.Server) *Maxconn {
+	return &Maxconn{name: n, server: s, alias: true}
+}
+
 func (a *Maxconn) GetName() string {
 	return a.name
 }
 
 func (a *Maxconn) Process(input string) error {
 	if input == "" {
-		a.server.Maxconn = nil
+		if !a.alias {
+			a.server.Maxconn = nil
+		}
 		return nil
 	}
 	v, err := strconv.ParseInt(input, 10, 64)
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/haproxytech/client-native/v2/models"
+)
+
+type Maxqueue struct {
+	name   string
+	server *models.Server
+}
+
+func NewMaxqueue(n string, s *models.Server) *Maxqueue {
+	return &Maxqueue{name: n, server: s}
+}
+
+func (a *Maxqueue) GetName() string {
+	return a.name
+}
+
+func (a *Maxqueue) Process(input string) error {
+	if input == "" {
+		a.server.Maxqueue = nil
+		return nil
+	}
+	v, err := strconv.ParseInt(input, 10, 64)
+	if err != nil {
+		return err
+	}
+	if v < 0 {
+		return fmt.Errorf("incorrect value '%s', a positive integer is expected", input)
+	}
+	a.server.Maxqueue = &v
+	return nil
+}
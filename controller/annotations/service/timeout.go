@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// Timeout sets a timeout of the backend, overriding the one of the defaults section
+type Timeout struct {
+	name    string
+	backend *models.Backend
+}
+
+func NewTimeout(n string, b *models.Backend) *Timeout {
+	return &Timeout{name: n, backend: b}
+}
+
+func (a *Timeout) GetName() string {
+	return a.name
+}
+
+func (a *Timeout) Process(input string) error {
+	var timeout *int64
+	if input != "" {
+		var err error
+		if timeout, err = utils.ParseTime(input); err != nil {
+			return err
+		}
+	}
+	switch a.name {
+	case "timeout-queue":
+		a.backend.QueueTimeout = timeout
+	case "timeout-server":
+		a.backend.ServerTimeout = timeout
+	case "timeout-tunnel":
+		a.backend.TunnelTimeout = timeout
+	default:
+		return fmt.Errorf("unknown timeout annotation '%s'", a.name)
+	}
+	return nil
+}
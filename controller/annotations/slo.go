@@ -0,0 +1,116 @@
+package annotations
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// sloStaleness is the time, in seconds, after which the response time of a backend without
+// responses is ignored, so non-critical routes are not shed forever once their traffic is denied.
+const sloStaleness = 10
+
+var sloVarName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// SLO defines the response time and queue objectives of a backend, the requests of the routes
+// marked with "load-shed" are denied with a 503 while they are breached so that the critical
+// routes sharing the same pods keep being served. The moving average of the response time is
+// kept by HAProxy in process variables and the rules are added to the backend config snippet.
+type SLO struct {
+	backend *models.Backend
+	queue   int64
+}
+
+type SLOAnn struct {
+	name   string
+	parent *SLO
+}
+
+func NewSLO(b *models.Backend) *SLO {
+	return &SLO{backend: b}
+}
+
+func (p *SLO) NewAnnotation(n string) SLOAnn {
+	return SLOAnn{
+		name:   n,
+		parent: p,
+	}
+}
+
+func (a SLOAnn) GetName() string {
+	return a.name
+}
+
+// "slo-queue" is processed before "slo-response-time"
+func (a SLOAnn) Process(input string) (err error) {
+	switch a.name {
+	case "slo-queue":
+		a.parent.queue = 0
+		if input == "" {
+			return
+		}
+		var queue int64
+		if queue, err = utils.ParseInt(input); err != nil {
+			return
+		}
+		if queue <= 0 {
+			return fmt.Errorf("incorrect queue '%s', a positive integer is expected", input)
+		}
+		a.parent.queue = queue
+	case "slo-response-time":
+		var rules []string
+		defer func() {
+			if err != nil {
+				rules = nil
+			}
+			a.setRules(rules)
+		}()
+		var responseTime *int64
+		if input != "" {
+			if responseTime, err = utils.ParseTime(input); err != nil {
+				return
+			}
+		}
+		rules = a.parent.rules(responseTime)
+	default:
+		err = fmt.Errorf("unknown slo annotation '%s'", a.name)
+	}
+	return
+}
+
+// rules returns the rules shedding the requests marked with "txn.load_shed" when the queue of the
+// backend or the moving average of its response time, over about the last 8 responses, exceed the SLO.
+func (p *SLO) rules(responseTime *int64) (rules []string) {
+	if p.queue != 0 {
+		rules = append(rules, fmt.Sprintf("http-request deny deny_status 503 if { var(txn.load_shed) -m bool } { queue gt %d }", p.queue))
+	}
+	if responseTime == nil {
+		return
+	}
+	avg := fmt.Sprintf("proc.slo_%s_time", sloVarName.ReplaceAllString(p.backend.Name, "_"))
+	updated := fmt.Sprintf("proc.slo_%s_updated", sloVarName.ReplaceAllString(p.backend.Name, "_"))
+	return append(rules,
+		fmt.Sprintf("http-request deny deny_status 503 if { var(txn.load_shed) -m bool } { var(%s) gt %d } { date,sub(%s) lt %d }", avg, *responseTime, updated, sloStaleness),
+		"http-request set-var(txn.slo_start) date(0,ms)",
+		"http-response set-var(txn.slo_time) date(0,ms),sub(txn.slo_start)",
+		fmt.Sprintf("http-response set-var(%s) var(txn.slo_time) unless { var(%s) -m found }", avg, avg),
+		fmt.Sprintf("http-response set-var(%s) var(%s),mul(7),add(txn.slo_time),div(8)", avg, avg),
+		fmt.Sprintf("http-response set-var(%s) date", updated),
+	)
+}
+
+func (a SLOAnn) setRules(rules []string) {
+	data, ok := cfgSnippet.backends[a.parent.backend.Name]
+	if !ok {
+		data = &cfgData{}
+		cfgSnippet.backends[a.parent.backend.Name] = data
+	}
+	if !reflect.DeepEqual(data.slo, rules) {
+		data.slo = rules
+		data.toUpdate = true
+	}
+}
@@ -0,0 +1,82 @@
+package annotations
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var spoeEngine = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// SPOEFilter attaches SPOE filters to the backend of a service so an agent only
+// processes the traffic of that service, the filter directive is not handled by
+// the API and is added to the backend config snippet.
+type SPOEFilter struct {
+	name    string
+	backend string
+}
+
+func NewSPOEFilter(n string, b string) *SPOEFilter {
+	return &SPOEFilter{name: n, backend: b}
+}
+
+func (a *SPOEFilter) GetName() string {
+	return a.name
+}
+
+// Input is one filter per line with the engine name and the path of the SPOE configuration file.
+// Example:
+//
+//	spoe-filter: |
+//	  engine:fraud, config:/etc/haproxy/spoe/fraud.conf
+func (a *SPOEFilter) Process(input string) error {
+	var filters []string
+	for _, filterLine := range strings.Split(input, "\n") {
+		// strip spaces
+		filterLine = strings.Join(strings.Fields(filterLine), "")
+		if filterLine == "" {
+			continue
+		}
+		engine, config, err := parseSPOEFilter(filterLine)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, fmt.Sprintf("filter spoe engine %s config %s", engine, config))
+	}
+	data, ok := cfgSnippet.backends[a.backend]
+	if !ok {
+		data = &cfgData{}
+		cfgSnippet.backends[a.backend] = data
+	}
+	if !reflect.DeepEqual(data.spoe, filters) {
+		data.spoe = filters
+		data.toUpdate = true
+	}
+	return nil
+}
+
+// parseSPOEFilter returns the engine and config params of a filter line stripped of spaces
+func parseSPOEFilter(filterLine string) (engine, config string, err error) {
+	params := make(map[string]string)
+	for _, param := range strings.Split(filterLine, ",") {
+		if param == "" {
+			continue
+		}
+		parts := strings.SplitN(param, ":", 2)
+		// param should be key: value
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("incorrect spoe filter param: '%s' in '%s'", param, filterLine)
+		}
+		params[strings.ToLower(parts[0])] = parts[1]
+	}
+	engine, ok := params["engine"]
+	if !ok || !spoeEngine.MatchString(engine) {
+		return "", "", fmt.Errorf("incorrect spoe filter: missing or invalid engine param in '%s'", filterLine)
+	}
+	config, ok = params["config"]
+	if !ok || !strings.HasPrefix(config, "/") {
+		return "", "", fmt.Errorf("incorrect spoe filter: missing or relative config param in '%s'", filterLine)
+	}
+	return engine, config, nil
+}
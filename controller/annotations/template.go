@@ -0,0 +1,148 @@
+package annotations
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/renameio"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+// templateRef matches the references annotation values are expanded with:
+// $NAMESPACE, $INGRESS_NAME, $SERVICE_NAME and ${secret:<name>/<key>}
+var templateRef = regexp.MustCompile(`\$(NAMESPACE|INGRESS_NAME|SERVICE_NAME)\b|\$\{secret:([^}]*)\}`)
+
+var secretKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// secretFormatAnnotations are the annotations whose values are HAProxy log-format strings,
+// the only ones secret references are allowed in.
+var secretFormatAnnotations = map[string]struct{}{
+	"request-set-header":  {},
+	"response-set-header": {},
+}
+
+// Template holds the values of the references of annotation values, so that generic manifests,
+// such as the ones of Helm charts, can set environment specific values.
+// Secrets are read in the namespace of the ingress or service, their values are never written to
+// the configuration: they are written to map files of the secret directory that log-format
+// annotations look up at runtime.
+type Template struct {
+	Namespace string
+	Ingress   string
+	Service   string
+	K8s       store.K8s
+}
+
+// GetValue returns the value of an annotation, as GetValue, with its references expanded
+func (t Template) GetValue(annotationName string, annotations ...map[string]string) (string, error) {
+	value := GetValue(annotationName, annotations...)
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+	var err error
+	expanded := templateRef.ReplaceAllStringFunc(value, func(ref string) string {
+		v, errRef := t.resolve(annotationName, templateRef.FindStringSubmatch(ref))
+		if errRef != nil && err == nil {
+			err = errRef
+		}
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
+
+func (t Template) resolve(annotationName string, match []string) (string, error) {
+	var value string
+	switch match[1] {
+	case "NAMESPACE":
+		value = t.Namespace
+	case "INGRESS_NAME":
+		value = t.Ingress
+	case "SERVICE_NAME":
+		value = t.Service
+	default:
+		return t.secretValue(annotationName, match[2])
+	}
+	if value == "" {
+		return "", fmt.Errorf("$%s is not available for this resource", match[1])
+	}
+	return value, nil
+}
+
+// secretValue returns the log-format expression looking up the value of a "<name>/<key>" secret reference
+func (t Template) secretValue(annotationName, ref string) (string, error) {
+	if _, ok := secretFormatAnnotations[annotationName]; !ok {
+		return "", fmt.Errorf("secret reference '%s' is only allowed in request-set-header and response-set-header", ref)
+	}
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("incorrect secret reference '%s', '<name>/<key>' expected", ref)
+	}
+	if t.Namespace == "" {
+		return "", fmt.Errorf("secret reference '%s' is not available for this resource", ref)
+	}
+	if !secretKey.MatchString(parts[1]) {
+		return "", fmt.Errorf("incorrect secret key '%s'", parts[1])
+	}
+	secret, err := t.K8s.FetchSecret(parts[0], t.Namespace)
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[parts[1]]
+	if secret.Status == store.DELETED || !ok {
+		return "", fmt.Errorf("secret '%s': '%s' key not found", parts[0], parts[1])
+	}
+	// values are written to a map file
+	if strings.ContainsAny(string(value), "\r\n\x00") {
+		return "", fmt.Errorf("secret '%s': '%s' key value must fit on a single line", parts[0], parts[1])
+	}
+	file := filepath.Join(secretDir, fmt.Sprintf("template-%s_%s.map", secret.Namespace, secret.Name))
+	entry, err := writeSecretEntry(file, parts[1], string(value))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%%[str(%s),map(%s)]", entry, file), nil
+}
+
+// writeSecretEntry writes the value of a secret key to the map file, readable by the controller user only,
+// and returns its map key: the secret key suffixed by a random version changed with the value, so that
+// the configuration changes and HAProxy reloads the map when the value changes.
+func writeSecretEntry(file, key, value string) (string, error) {
+	if secretDir == "" {
+		return "", fmt.Errorf("secret directory not set")
+	}
+	current, _ := ioutil.ReadFile(file)
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(current))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.SplitN(line, " ", 2)
+		if !strings.HasPrefix(fields[0], key+".") {
+			lines = append(lines, line)
+			continue
+		}
+		if len(fields) == 2 && fields[1] == value {
+			return fields[0], nil
+		}
+	}
+	version := make([]byte, 4)
+	if _, err := rand.Read(version); err != nil {
+		return "", err
+	}
+	entry := key + "." + hex.EncodeToString(version)
+	lines = append(lines, entry+" "+value)
+	if err := renameio.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return entry, nil
+}
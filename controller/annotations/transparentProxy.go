@@ -0,0 +1,41 @@
+package annotations
+
+import (
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+const transparentProxySource = "source 0.0.0.0 usesrc clientip"
+
+// TransparentProxy makes backend connections to servers use the client IP address as source,
+// it is added to the backend config snippet since the source directive is not handled by the API.
+type TransparentProxy struct {
+	name    string
+	backend string
+}
+
+func NewTransparentProxy(n string, b string) *TransparentProxy {
+	return &TransparentProxy{name: n, backend: b}
+}
+
+func (a *TransparentProxy) GetName() string {
+	return a.name
+}
+
+func (a *TransparentProxy) Process(input string) (err error) {
+	var enabled bool
+	if input != "" {
+		if enabled, err = utils.GetBoolValue(input, a.name); err != nil {
+			return err
+		}
+	}
+	data, ok := cfgSnippet.backends[a.backend]
+	if !ok {
+		data = &cfgData{}
+		cfgSnippet.backends[a.backend] = data
+	}
+	if data.transparent != enabled {
+		data.transparent = enabled
+		data.toUpdate = true
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/route"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+// handleBlueGreen routes the host/path of a BlueGreen CR to the backend of the active
+// deployment via map files, so a promotion only swaps the map entry.
+// When StandbyWeight is set, that percentage of requests is sent to the standby backend,
+// the custom route of a former standby backend is deleted by route.CustomRoutesClean.
+func (c *HAProxyController) handleBlueGreen(bg *store.BlueGreen) (reload bool, err error) {
+	if ns, ok := c.Store.Namespaces[bg.Namespace]; !ok || !ns.Relevant {
+		return
+	}
+	if c.crRouteRejected(bg.Ref(), bg.Host, bg.Active.Path) {
+		return
+	}
+	// BlueGreen CR is handled as an Ingress without annotations
+	ingress := &store.Ingress{
+		Namespace:   bg.Namespace,
+		Name:        bg.Name,
+		Annotations: map[string]string{},
+	}
+	backends := make([]string, 0, 2)
+	for _, path := range []*store.IngressPath{bg.Active, bg.Standby} {
+		var backendReload bool
+		var backendName string
+		backendReload, backendName, err = c.handleServiceBackend(ingress, path)
+		if err != nil {
+			return
+		}
+		reload = reload || backendReload
+		backends = append(backends, backendName)
+	}
+	active, standby := backends[0], backends[1]
+	err = route.AddHostPathRoute(route.Route{
+		Host:        bg.Host,
+		Path:        bg.Active,
+		BackendName: active,
+	}, c.Cfg.MapFiles)
+	if err != nil {
+		return
+	}
+	if bg.StandbyWeight == 0 || c.weightedRouteDenied(bg.Ref(), bg.StandbyWeight) {
+		return
+	}
+	routeReload, err := route.AddCustomRoute(route.Route{
+		Host:        bg.Host,
+		Path:        bg.Standby,
+		BackendName: standby,
+	}, fmt.Sprintf("rand(100) lt %d", bg.StandbyWeight), c.Client)
+	return reload || routeReload, err
+}
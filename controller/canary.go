@@ -0,0 +1,336 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// Canary rollout of risky changes:
+// with "canary-rollout", changes of the global and defaults sections and of config snippets are first
+// applied by a single replica, the canary, which claims the rollout in the coordination ConfigMap.
+// Other replicas defer them until the canary, healthy for "canary-bake-time", promotes the rollout.
+// A canary found unhealthy marks the rollout as failed, rolls the change back and the change is refused
+// by other replicas. Deferred or refused changes are replaced in the committed configuration by the
+// risky parts of the stable one, so that the other changes are still applied.
+
+//nolint:golint,stylecheck
+const (
+	ROLLOUT_BAKING   = "baking"
+	ROLLOUT_PROMOTED = "promoted"
+	ROLLOUT_FAILED   = "failed"
+)
+
+const snippetMarker = "###_config-snippet_###"
+
+type canary struct {
+	// applied is the risky hash of the committed configuration
+	applied string
+	// stable is the last committed configuration whose risky parts are not baking on this replica
+	stable string
+	// baking is set while the controller is the canary of a rollout
+	baking bool
+	// rollback is set when the risky parts baked by the controller must be replaced by the stable ones
+	rollback bool
+	// reverted is set when the risky parts of the configuration being committed were replaced
+	reverted bool
+	// pending is the risky hash deferred until the rollout allows it
+	pending string
+	// crashes are the HAProxy crashes when the rollout was claimed
+	crashes int
+}
+
+// rollout is the state of the coordination ConfigMap
+type rollout struct {
+	Hash    string
+	Canary  string
+	State   string
+	Started time.Time
+	cm      *corev1.ConfigMap
+}
+
+// riskyHash returns the hash of the global and defaults sections and of the config snippets of a configuration
+func riskyHash(config string) string {
+	h := sha256.New()
+	section, snippet := "", false
+	for _, line := range strings.Split(config, "\n") {
+		if line != "" && line[0] != ' ' && line[0] != '\t' && line[0] != '#' {
+			section = strings.Fields(line)[0]
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, snippetMarker) {
+			snippet = strings.HasSuffix(trimmed, "BEGIN")
+		}
+		if section == "global" || section == "defaults" || snippet {
+			h.Write([]byte(line + "\n"))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canaryGate returns the configuration to commit, with the risky parts of the stable configuration
+// when its risky changes are deferred or refused by the rollout. The initial configuration of the
+// controller honours failed and baking rollouts too, with the configuration HAProxy starts with as
+// the stable one, but it never claims a rollout.
+func (c *HAProxyController) canaryGate(config string) (string, error) {
+	c.canary.reverted = false
+	hash := riskyHash(config)
+	if c.OSArgs.CanaryRollout.Name == "" || (hash == c.canary.applied && !c.canary.rollback) {
+		return config, nil
+	}
+	pod := controllerPodRef().Name
+	r, err := c.getRollout()
+	if err != nil {
+		return "", fmt.Errorf("canary rollout: %w", err)
+	}
+	if c.canary.stable == "" {
+		stable, errRead := ioutil.ReadFile(c.Cfg.Env.MainCFGFile)
+		if errRead != nil {
+			return "", fmt.Errorf("canary rollout: %w", errRead)
+		}
+		c.canary.stable = string(stable)
+	}
+	switch {
+	case r.Hash == hash && r.State == ROLLOUT_FAILED:
+		c.canary.pending = ""
+		logger.Warningf("canary rollout: change %.12s failed on canary '%s', refused", hash, r.Canary)
+		return c.canaryRevert(config), nil
+	case r.Hash == hash && (r.Canary == pod || r.State == ROLLOUT_PROMOTED):
+	case r.State == ROLLOUT_BAKING && r.Canary != pod && time.Since(r.Started) < 2*c.OSArgs.CanaryBakeTime:
+		c.canary.pending = hash
+		logger.Infof("canary rollout: canary '%s' is baking change %.12s, change %.12s deferred", r.Canary, r.Hash, hash)
+		return c.canaryRevert(config), nil
+	case !c.ready:
+	default:
+		if err = c.claimRollout(r, hash, pod); err != nil {
+			if k8serrors.IsConflict(err) || k8serrors.IsAlreadyExists(err) {
+				c.canary.pending = hash
+				logger.Infof("canary rollout: rollout claimed by another replica, change %.12s deferred", hash)
+				return c.canaryRevert(config), nil
+			}
+			return "", fmt.Errorf("canary rollout: %w", err)
+		}
+		logger.Infof("canary rollout: applying change %.12s first, baking for %s", hash, c.OSArgs.CanaryBakeTime)
+		utils.NormalEvent(controllerPodRef(), "CanaryRolloutStarted", fmt.Sprintf("change %.12s applied first by this replica", hash))
+	}
+	c.canary.pending = ""
+	return config, nil
+}
+
+// canaryRevert replaces the risky parts of config by the ones of the stable configuration
+func (c *HAProxyController) canaryRevert(config string) string {
+	c.canary.rollback = false
+	c.canary.reverted = true
+	return replaceRisky(config, c.canary.stable)
+}
+
+// replaceRisky replaces the global and defaults sections and the config snippets of config by the ones of stable.
+// Snippets are inserted at the start of their section, their position is restored when the transaction is parsed.
+func replaceRisky(config, stable string) string {
+	stableSections := make(map[string][]string)
+	for _, section := range splitSections(stable) {
+		stableSections[section[0]] = section
+	}
+	var lines []string
+	for _, section := range splitSections(config) {
+		header := section[0]
+		if header == "" {
+			lines = append(lines, section[1:]...)
+			continue
+		}
+		switch strings.Fields(header)[0] {
+		case "global", "defaults":
+			lines = append(lines, stableSections[header]...)
+		default:
+			lines = append(lines, header)
+			lines = append(lines, sectionSnippets(stableSections[header])...)
+			snippet := false
+			for _, line := range section[1:] {
+				trimmed := strings.TrimSpace(line)
+				if strings.HasPrefix(trimmed, snippetMarker) {
+					snippet = strings.HasSuffix(trimmed, "BEGIN")
+					continue
+				}
+				if !snippet {
+					lines = append(lines, line)
+				}
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitSections splits a configuration in sections starting with their header line,
+// the lines before the first section are returned in a section with an empty header.
+func splitSections(config string) (sections [][]string) {
+	section := []string{""}
+	for _, line := range strings.Split(config, "\n") {
+		if line != "" && line[0] != ' ' && line[0] != '\t' && line[0] != '#' {
+			sections = append(sections, section)
+			section = nil
+		}
+		section = append(section, line)
+	}
+	return append(sections, section)
+}
+
+// sectionSnippets returns the config snippets lines of a section, markers included
+func sectionSnippets(section []string) (lines []string) {
+	snippet := false
+	for i, line := range section {
+		trimmed := strings.TrimSpace(line)
+		if i > 0 && strings.HasPrefix(trimmed, snippetMarker) {
+			snippet = strings.HasSuffix(trimmed, "BEGIN")
+			lines = append(lines, line)
+			continue
+		}
+		if snippet {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// canaryCheck bakes, every sync period, the rollout claimed by the controller and returns
+// true when a deferred change can now be applied or refused, or a failed change rolled back.
+func (c *HAProxyController) canaryCheck() bool {
+	if c.OSArgs.CanaryRollout.Name == "" || !c.ready {
+		return false
+	}
+	r, err := c.getRollout()
+	if err != nil {
+		logger.Errorf("canary rollout: %s", err)
+		return false
+	}
+	if c.canary.pending != "" {
+		if r.State == ROLLOUT_BAKING && time.Since(r.Started) < 2*c.OSArgs.CanaryBakeTime {
+			return false
+		}
+		// deferred snippets were reverted in the configuration, the controller must set them again
+		annotations.ResendCfgSnippets()
+		return true
+	}
+	pod := controllerPodRef().Name
+	if r.Canary != pod || r.State != ROLLOUT_BAKING || r.Hash != c.canary.applied {
+		return false
+	}
+	switch {
+	case c.haproxyProcess.Status() != nil || c.supervisor.crashes != c.canary.crashes || c.healthzFailed:
+		r.State = ROLLOUT_FAILED
+	case time.Since(r.Started) >= c.OSArgs.CanaryBakeTime:
+		r.State = ROLLOUT_PROMOTED
+	default:
+		return false
+	}
+	if err = c.updateRollout(r); err != nil {
+		logger.Errorf("canary rollout: %s", err)
+		return false
+	}
+	c.canary.baking = false
+	if r.State == ROLLOUT_FAILED {
+		logger.Errorf("canary rollout: change %.12s failed, rolled back and refused by other replicas", r.Hash)
+		utils.WarningEvent(controllerPodRef(), "CanaryRolloutFailed", fmt.Sprintf("change %.12s made HAProxy unhealthy, rolled back and refused by other replicas", r.Hash))
+		c.canary.rollback = true
+		c.Client.APIRevalidate()
+		return true
+	}
+	logger.Infof("canary rollout: change %.12s promoted", r.Hash)
+	utils.NormalEvent(controllerPodRef(), "CanaryRolloutPromoted", fmt.Sprintf("change %.12s healthy for %s, applied by other replicas", r.Hash, c.OSArgs.CanaryBakeTime))
+	c.canaryCommitted()
+	return false
+}
+
+// canaryCommitted records the risky hash of the committed configuration, which becomes the stable one
+// unless the controller is baking it. Restarting HAProxy is only needed when risky parts were not reverted
+// to the ones of the running configuration.
+func (c *HAProxyController) canaryCommitted() {
+	if c.OSArgs.CanaryRollout.Name == "" {
+		return
+	}
+	config, err := ioutil.ReadFile(c.Cfg.Env.MainCFGFile)
+	if err != nil {
+		logger.Errorf("canary rollout: %s", err)
+		return
+	}
+	applied := riskyHash(string(config))
+	if c.canary.reverted {
+		c.restart = applied != c.canary.applied
+		c.canary.reverted = false
+	}
+	c.canary.applied = applied
+	if !c.canary.baking {
+		c.canary.stable = string(config)
+	}
+}
+
+func (c *HAProxyController) getRollout() (r rollout, err error) {
+	cms := c.k8s.API.CoreV1().ConfigMaps(c.OSArgs.CanaryRollout.Namespace)
+	r.cm, err = cms.Get(context.Background(), c.OSArgs.CanaryRollout.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+	r.Hash, r.Canary, r.State = r.cm.Data["hash"], r.cm.Data["canary"], r.cm.Data["state"]
+	r.Started, _ = time.Parse(time.RFC3339, r.cm.Data["started"])
+	return r, nil
+}
+
+// claimRollout makes the controller the canary of a change, the update fails
+// with a conflict when another replica claimed it meanwhile.
+func (c *HAProxyController) claimRollout(r rollout, hash, pod string) error {
+	r.Hash, r.Canary, r.State, r.Started = hash, pod, ROLLOUT_BAKING, time.Now()
+	if err := c.updateRollout(r); err != nil {
+		return err
+	}
+	c.canary.crashes = c.supervisor.crashes
+	c.canary.baking = true
+	return nil
+}
+
+func (c *HAProxyController) updateRollout(r rollout) (err error) {
+	data := map[string]string{
+		"hash":    r.Hash,
+		"canary":  r.Canary,
+		"state":   r.State,
+		"started": r.Started.UTC().Format(time.RFC3339),
+	}
+	cms := c.k8s.API.CoreV1().ConfigMaps(c.OSArgs.CanaryRollout.Namespace)
+	if r.cm == nil {
+		_, err = cms.Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: c.OSArgs.CanaryRollout.Name},
+			Data:       data,
+		}, metav1.CreateOptions{})
+		return err
+	}
+	cm := r.cm.DeepCopy()
+	cm.Data = data
+	_, err = cms.Update(context.Background(), cm, metav1.UpdateOptions{})
+	return err
+}
@@ -1,6 +1,8 @@
 package configuration
 
 import (
+	"path/filepath"
+
 	"github.com/haproxytech/client-native/v2/models"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/utils"
@@ -19,6 +21,18 @@ func SetGlobal(global *models.Global, env Env) {
 		ExposeFdListeners: true,
 		Level:             "admin",
 	})
+	for _, file := range []string{SpikeArrestLuaFile, OIDCLuaFile, StatusReplyLuaFile} {
+		luaFile := filepath.Join(env.LuaDir, file)
+		luaLoaded := false
+		for _, lua := range global.LuaLoads {
+			if lua.File != nil && *lua.File == luaFile {
+				luaLoaded = true
+			}
+		}
+		if !luaLoaded {
+			global.LuaLoads = append(global.LuaLoads, &models.LuaLoad{File: &luaFile})
+		}
+	}
 	// Default values
 	if global.Daemon == "" {
 		global.Daemon = "enabled"
@@ -55,7 +69,7 @@ func SetDefaults(defaults *models.Defaults) {
 	if defaults.ConnectTimeout == nil {
 		defaults.ConnectTimeout = utils.PtrInt64(5000) // 5s
 	}
-	if defaults.ConnectTimeout == nil {
+	if defaults.QueueTimeout == nil {
 		defaults.QueueTimeout = utils.PtrInt64(5000) // 5s
 	}
 	if defaults.ClientTimeout == nil {
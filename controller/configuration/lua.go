@@ -0,0 +1,81 @@
+package configuration
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+)
+
+// SpikeArrestLuaFile is the Lua script registering the "spike_arrest" action,
+// delaying requests by the number of milliseconds given as argument.
+const SpikeArrestLuaFile = "spike-arrest.lua"
+
+const spikeArrestLua = `-- Delays requests exceeding the spike-arrest rate instead of denying them
+core.register_action("spike_arrest", { "http-req" }, function(txn, delay)
+  core.msleep(tonumber(delay))
+end, 1)
+`
+
+// StatusReplyLuaFile is the Lua script registering the "status_reply" response action,
+// replacing the response with the status, content type and file given as arguments.
+const StatusReplyLuaFile = "status-reply.lua"
+
+// StatusReplyMap lists the files of the "status_reply" action, read when HAProxy loads its configuration
+const StatusReplyMap = "status-reply"
+
+// statusReplyLua is formatted with the path of the StatusReplyMap file
+const statusReplyLua = `-- Replaces backend responses, files are read when the configuration is loaded,
+-- not while serving requests, they only change with reloads.
+local files = {}
+
+core.register_init(function()
+  local list = io.open(%s, "r")
+  if list == nil then
+    return
+  end
+  for file in list:lines() do
+    if file ~= "" and files[file] == nil then
+      local f = io.open(file, "rb")
+      if f == nil then
+        core.Warning("status_reply: unable to open " .. file)
+      else
+        files[file] = f:read("*a")
+        f:close()
+      end
+    end
+  end
+  list:close()
+end)
+
+core.register_action("status_reply", { "http-res" }, function(txn, status, content_type, file)
+  if files[file] == nil then
+    core.Warning("status_reply: file not loaded " .. file)
+    return
+  end
+  txn:done(txn:reply({
+    status = tonumber(status),
+    headers = { ["content-type"] = { content_type }, ["cache-control"] = { "no-cache" } },
+    body = files[file],
+  }))
+end, 3)
+`
+
+// luaScripts are the Lua scripts loaded by HAProxy, by file name
+func (c *ControllerCfg) luaScripts() map[string]string {
+	return map[string]string{
+		SpikeArrestLuaFile: spikeArrestLua,
+		OIDCLuaFile:        oidcLua,
+		StatusReplyLuaFile: fmt.Sprintf(statusReplyLua, strconv.Quote(filepath.Join(c.Env.MapDir, StatusReplyMap+".map"))),
+	}
+}
+
+// writeLuaScripts writes the Lua scripts loaded by HAProxy
+func (c *ControllerCfg) writeLuaScripts() error {
+	for file, script := range c.luaScripts() {
+		if err := ioutil.WriteFile(filepath.Join(c.Env.LuaDir, file), []byte(script), 0644); err != nil { //nolint:gosec
+			return err
+		}
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
@@ -37,12 +38,19 @@ type ControllerCfg struct {
 	Env             Env
 	HTTPS           bool
 	SSLPassthrough  bool
+	// InternalBinds is set when internal ingresses are exposed via dedicated binds
+	InternalBinds bool
+	// SSLPassthroughPort is set when the ssl-passthrough frontend listens on a dedicated
+	// port instead of taking over the HTTPS port
+	SSLPassthroughPort bool
 }
 
 // Directories and files required by haproxy and controller
 type Env struct {
-	HAProxyBinary   string
-	RuntimeSocket   string
+	HAProxyBinary string
+	RuntimeSocket string
+	// MasterSocket is the master CLI socket runtime commands go through, if set
+	MasterSocket    string
 	PIDFile         string
 	MainCFGFile     string
 	AuxCFGFile      string
@@ -50,13 +58,20 @@ type Env struct {
 	RuntimeDir      string
 	CertDir         string
 	FrontendCertDir string
-	BackendCertDir  string
-	CaCertDir       string
-	StateDir        string
-	MapDir          string
-	PatternDir      string
-	ErrFileDir      string
-	TransactionDir  string
+	// FrontendCrtListDir holds the certificates listed in the FrontendCrtList file
+	FrontendCrtListDir string
+	FrontendCrtList    string
+	BackendCertDir     string
+	CaCertDir          string
+	StateDir           string
+	MapDir             string
+	PatternDir         string
+	ErrFileDir         string
+	TransactionDir     string
+	LuaDir             string
+	// SecretDir holds the secrets HAProxy loads from files, such as OIDC client secrets and HMAC keys,
+	// it is only readable by the controller user and never copied.
+	SecretDir string
 }
 
 // Init initialize configuration
@@ -72,7 +87,7 @@ func (c *ControllerCfg) Init() (err error) {
 	if err := c.haproxyRulesInit(); err != nil {
 		return err
 	}
-	c.Certificates = haproxy.NewCertificates(c.Env.CaCertDir, c.Env.FrontendCertDir, c.Env.BackendCertDir)
+	c.Certificates = haproxy.NewCertificates(c.Env.CaCertDir, c.Env.FrontendCertDir, c.Env.BackendCertDir, c.Env.FrontendCrtListDir, c.Env.FrontendCrtList)
 	c.ActiveBackends = make(map[string]struct{})
 	return nil
 }
@@ -90,6 +105,12 @@ func (c *ControllerCfg) haproxyRulesInit() error {
 			ForwardedProto: true,
 		}, false, c.FrontHTTPS),
 	)
+	// Hosts of requests received on internal binds are looked up with the internal prefix
+	var externalCond, internalCond string
+	if c.InternalBinds {
+		internalCond = fmt.Sprintf("{ so_name -m beg %s }", haproxy.INTERNAL_BIND_PREFIX)
+		externalCond = "!" + internalCond
+	}
 	for _, frontend := range []string{c.FrontHTTP, c.FrontHTTPS} {
 		errors.Add(
 			// txn.base var used for logging
@@ -113,13 +134,32 @@ func (c *ControllerCfg) haproxyRulesInit() error {
 				Name:       "host_match",
 				Scope:      "txn",
 				Expression: fmt.Sprintf("var(txn.host),map(%s)", haproxy.GetMapPath(haproxy.MAP_HOST)),
+				CondTest:   externalCond,
 			}, false, frontend),
 			c.HAProxyRules.AddRule(rules.ReqSetVar{
 				Name:       "host_match",
 				Scope:      "txn",
 				Expression: fmt.Sprintf("var(txn.host),regsub(^[^.]*,,),map(%s,'')", haproxy.GetMapPath(haproxy.MAP_HOST)),
-				CondTest:   "!{ var(txn.host_match) -m found }",
+				CondTest:   strings.TrimSpace(externalCond + " !{ var(txn.host_match) -m found }"),
 			}, false, frontend),
+		)
+		if c.InternalBinds {
+			errors.Add(
+				c.HAProxyRules.AddRule(rules.ReqSetVar{
+					Name:       "host_match",
+					Scope:      "txn",
+					Expression: fmt.Sprintf("str(%s),concat(,txn.host,),map(%s)", haproxy.INTERNAL_HOST_PREFIX, haproxy.GetMapPath(haproxy.MAP_HOST)),
+					CondTest:   internalCond,
+				}, false, frontend),
+				c.HAProxyRules.AddRule(rules.ReqSetVar{
+					Name:       "host_match",
+					Scope:      "txn",
+					Expression: fmt.Sprintf("str(%[1]s),concat(,txn.host,),regsub(@[^.]*,@),map(%[2]s,'%[1]s')", haproxy.INTERNAL_HOST_PREFIX, haproxy.GetMapPath(haproxy.MAP_HOST)),
+					CondTest:   internalCond + " !{ var(txn.host_match) -m found }",
+				}, false, frontend),
+			)
+		}
+		errors.Add(
 			c.HAProxyRules.AddRule(rules.ReqSetVar{
 				Name:       "path_match",
 				Scope:      "txn",
@@ -131,6 +171,12 @@ func (c *ControllerCfg) haproxyRulesInit() error {
 				Expression: fmt.Sprintf("var(txn.host_match),concat(,txn.path,),map_beg(%s)", haproxy.GetMapPath(haproxy.MAP_PATH_PREFIX)),
 				CondTest:   "!{ var(txn.path_match) -m found }",
 			}, false, frontend),
+			c.HAProxyRules.AddRule(rules.ReqSetVar{
+				Name:       "path_match",
+				Scope:      "txn",
+				Expression: fmt.Sprintf("var(txn.host_match),map(%s)", haproxy.GetMapPath(haproxy.MAP_HOST_DEFAULT)),
+				CondTest:   "!{ var(txn.path_match) -m found }",
+			}, false, frontend),
 		)
 	}
 
@@ -165,6 +211,8 @@ func (c *ControllerCfg) envInit() (err error) {
 	c.Env.FrontendCertDir = filepath.Join(c.Env.CertDir, "frontend")
 	c.Env.BackendCertDir = filepath.Join(c.Env.CertDir, "backend")
 	c.Env.CaCertDir = filepath.Join(c.Env.CertDir, "ca")
+	c.Env.FrontendCrtListDir = filepath.Join(c.Env.CertDir, "frontend-crt-list")
+	c.Env.FrontendCrtList = filepath.Join(c.Env.CertDir, "frontend.crt-list")
 
 	if c.Env.MapDir == "" {
 		c.Env.MapDir = filepath.Join(c.Env.CfgDir, "maps")
@@ -178,10 +226,16 @@ func (c *ControllerCfg) envInit() (err error) {
 	if c.Env.TransactionDir == "" {
 		c.Env.TransactionDir = filepath.Join(c.Env.CfgDir, "transactions")
 	}
+	if c.Env.LuaDir == "" {
+		c.Env.LuaDir = filepath.Join(c.Env.CfgDir, "lua")
+	}
+	c.Env.SecretDir = filepath.Join(c.Env.CfgDir, "secrets")
 
 	for _, d := range []string{
+		c.Env.RuntimeDir,
 		c.Env.CertDir,
 		c.Env.FrontendCertDir,
+		c.Env.FrontendCrtListDir,
 		c.Env.BackendCertDir,
 		c.Env.CaCertDir,
 		c.Env.MapDir,
@@ -189,16 +243,37 @@ func (c *ControllerCfg) envInit() (err error) {
 		c.Env.StateDir,
 		c.Env.TransactionDir,
 		c.Env.PatternDir,
+		c.Env.LuaDir,
 	} {
 		err = os.MkdirAll(d, 0755)
 		if err != nil {
 			return err
 		}
 	}
+	if err = os.MkdirAll(c.Env.SecretDir, 0700); err != nil {
+		return err
+	}
+	if err = c.writeLuaScripts(); err != nil {
+		return err
+	}
 	_, err = os.Create(filepath.Join(c.Env.StateDir, "global"))
 	return err
 }
 
+// ClientFrontends returns the frontends accepting client connections.
+// When ssl-passthrough takes over the HTTPS port, the HTTPS frontend only receives
+// the connections chained by the ssl-passthrough frontend.
+func (c *ControllerCfg) ClientFrontends() []string {
+	switch {
+	case !c.SSLPassthrough:
+		return []string{c.FrontHTTP, c.FrontHTTPS}
+	case c.SSLPassthroughPort:
+		return []string{c.FrontHTTP, c.FrontHTTPS, c.FrontSSL}
+	default:
+		return []string{c.FrontHTTP, c.FrontSSL}
+	}
+}
+
 // Clean cleans all the statuses of various data that was changed
 // deletes them completely or just resets them if needed
 func (c *ControllerCfg) Clean() error {
@@ -0,0 +1,229 @@
+package configuration
+
+// OIDCLuaFile is the Lua script registering the "oidc_auth" action, an OpenID Connect
+// Relying Party using the authorization code flow. The code is exchanged at the token
+// endpoint via the internal "oidc" frontend given as first argument, the ID token is
+// then trusted without signature verification as it comes straight from the provider
+// over TLS. Sessions and pending authentication states are kept in cookies signed with
+// an HMAC of the client secret, loaded in the txn.oidc_secret variable, so they survive
+// reloads, are shared by replicas and do not grow HAProxy memory.
+const OIDCLuaFile = "oidc.lua"
+
+const oidcLua = `-- OpenID Connect Relying Party, arguments of the oidc_auth action:
+-- socket issuer authorization_endpoint token_endpoint client_id redirect_path redirect_hosts
+-- cookie_name cookie_max_age
+-- the client secret is read from the txn.oidc_secret variable
+local state_ttl = 600
+
+local function url_encode(s)
+  return (string.gsub(s, "[^%w%-%._~]", function(c)
+    return string.format("%%%02X", string.byte(c))
+  end))
+end
+
+local function random_id(txn)
+  return (string.gsub(txn.f:uuid() .. txn.f:uuid(), "-", ""))
+end
+
+local function cookie(txn, name)
+  local value = txn.f:req_cook(name)
+  if value == nil or value == "" then
+    return nil
+  end
+  return value
+end
+
+-- equal compares strings in constant time
+local function equal(a, b)
+  if #a ~= #b then
+    return false
+  end
+  local diff = 0
+  for i = 1, #a do
+    diff = diff | (string.byte(a, i) ~ string.byte(b, i))
+  end
+  return diff == 0
+end
+
+local function mac(txn, data)
+  return txn.c:ub64enc(txn.c:hmac(data, "sha256", "txn.oidc_secret"))
+end
+
+-- sign returns the cookie value of the fields, the first one being the kind of cookie
+-- and the second one its expiration date
+local function sign(txn, fields)
+  local data = txn.c:ub64enc(table.concat(fields, "\n"))
+  return data .. "." .. mac(txn, data)
+end
+
+-- verify returns the fields of a cookie value signed for this kind and not expired
+local function verify(txn, value, kind)
+  local data, signature = string.match(value or "", "^([%w_-]+)%.([%w_-]+)$")
+  if data == nil or not equal(mac(txn, data), signature) then
+    return nil
+  end
+  local payload = txn.c:ub64dec(data)
+  if payload == nil then
+    return nil
+  end
+  local fields = {}
+  for field in string.gmatch(payload .. "\n", "([^\n]*)\n") do
+    fields[#fields + 1] = field
+  end
+  if fields[1] ~= kind or (tonumber(fields[2]) or 0) < core.now().sec then
+    return nil
+  end
+  return fields
+end
+
+-- redirect_host returns the host of the redirect URI: the request host when it is one of
+-- redirect_hosts, else the first exact one. The Host header is never used as is.
+local function redirect_host(txn, redirect_hosts)
+  local host = string.lower((string.gsub(txn.f:req_hdr("host") or "", ":%d+$", "")))
+  local first
+  for allowed in string.gmatch(redirect_hosts, "[^,]+") do
+    local wildcard = string.sub(allowed, 1, 2) == "*."
+    if allowed == host or (wildcard and #host > #allowed - 1 and string.sub(host, 1 - #allowed) == string.sub(allowed, 2)) then
+      return host
+    end
+    if first == nil and not wildcard then
+      first = allowed
+    end
+  end
+  return first
+end
+
+local function json_string(json, key)
+  return string.match(json, '"' .. key .. '"%s*:%s*"([^"]*)"')
+end
+
+local function json_number(json, key)
+  return tonumber(string.match(json, '"' .. key .. '"%s*:%s*(%d+)'))
+end
+
+local function redirect(txn, location, cookies)
+  local reply = txn:reply({ status = 302 })
+  reply:add_header("location", location)
+  reply:add_header("cache-control", "no-store")
+  for _, c in ipairs(cookies) do
+    reply:add_header("set-cookie", c)
+  end
+  txn:done(reply)
+end
+
+local function deny(txn, status, reason)
+  core.Warning("oidc: " .. reason)
+  txn:done(txn:reply({ status = status, body = reason .. "\n" }))
+end
+
+-- token_request posts the form to the token endpoint through the internal frontend
+local function token_request(socket, endpoint, form)
+  local host, path = string.match(endpoint, "^https://([^/]+)(/.*)$")
+  if host == nil then
+    return nil, "invalid token endpoint " .. endpoint
+  end
+  local s = core.tcp()
+  s:settimeout(5)
+  if not s:connect(socket) then
+    return nil, "unable to connect to " .. socket
+  end
+  s:send("POST " .. path .. " HTTP/1.0\r\nHost: " .. host ..
+    "\r\nContent-Type: application/x-www-form-urlencoded\r\nAccept: application/json\r\nContent-Length: " ..
+    #form .. "\r\n\r\n" .. form)
+  local response = s:receive("*a")
+  s:close()
+  if response == nil then
+    return nil, "no response from " .. endpoint
+  end
+  local status = tonumber(string.match(response, "^HTTP/%d%.%d (%d+)"))
+  local body = string.match(response, "\r\n\r\n(.*)$")
+  if status ~= 200 or body == nil then
+    return nil, "token endpoint answered " .. tostring(status)
+  end
+  return body
+end
+
+local function callback(txn, socket, issuer, token_endpoint, client_id, client_secret, redirect_uri, cookie_name, max_age)
+  local state = txn.f:url_param("state")
+  local code = txn.f:url_param("code")
+  -- kind, expiration, state, nonce, url
+  local pending = verify(txn, cookie(txn, cookie_name .. "_state"), "state")
+  if pending == nil or pending[3] ~= state or code == nil then
+    return deny(txn, 403, "invalid or expired authentication state")
+  end
+  local body, err = token_request(socket, token_endpoint,
+    "grant_type=authorization_code&code=" .. url_encode(code) ..
+    "&redirect_uri=" .. url_encode(redirect_uri) ..
+    "&client_id=" .. url_encode(client_id) ..
+    "&client_secret=" .. url_encode(client_secret))
+  if body == nil then
+    return deny(txn, 502, err)
+  end
+  local id_token = json_string(body, "id_token")
+  local payload = id_token and string.match(id_token, "^[^.]+%.([^.]+)%.")
+  local claims = payload and txn.c:ub64dec(payload)
+  if claims == nil or claims == "" then
+    return deny(txn, 502, "no ID token in token response")
+  end
+  local exp = json_number(claims, "exp")
+  local audiences = string.match(claims, '"aud"%s*:%s*(%b[])')
+  local audience = json_string(claims, "aud")
+  if audiences ~= nil then
+    audience = string.find(audiences, '"' .. client_id .. '"', 1, true) and client_id
+  end
+  if json_string(claims, "iss") ~= issuer or audience ~= client_id or
+    exp == nil or exp < core.now().sec or json_string(claims, "nonce") ~= pending[4] then
+    return deny(txn, 403, "invalid ID token")
+  end
+  local sub = json_string(claims, "sub") or ""
+  local email = json_string(claims, "email") or ""
+  if string.find(sub .. email, "\n", 1, true) then
+    return deny(txn, 403, "invalid ID token")
+  end
+  local session = sign(txn, { "session", core.now().sec + max_age, sub, email })
+  redirect(txn, pending[5], {
+    cookie_name .. "=" .. session .. "; Path=/; Max-Age=" .. max_age .. "; HttpOnly; Secure; SameSite=Lax",
+    cookie_name .. "_state=; Path=/; Max-Age=0; HttpOnly; Secure",
+  })
+end
+
+core.register_action("oidc_auth", { "http-req" }, function(txn, socket, issuer, authorization_endpoint,
+    token_endpoint, client_id, redirect_path, redirect_hosts, cookie_name, cookie_max_age)
+  local client_secret = txn:get_var("txn.oidc_secret")
+  if client_secret == nil or client_secret == "" then
+    return deny(txn, 500, "client secret not loaded")
+  end
+  local max_age = tonumber(cookie_max_age)
+  local host = redirect_host(txn, redirect_hosts)
+  if host == nil then
+    return deny(txn, 500, "no redirect host configured")
+  end
+  local redirect_uri = "https://" .. host .. redirect_path
+  if txn.f:path() == redirect_path then
+    return callback(txn, socket, issuer, token_endpoint, client_id, client_secret, redirect_uri, cookie_name, max_age)
+  end
+  -- kind, expiration, sub, email
+  local session = verify(txn, cookie(txn, cookie_name), "session")
+  if session ~= nil then
+    -- headers sent by clients are never trusted
+    txn.http:req_del_header("x-auth-request-user")
+    txn.http:req_del_header("x-auth-request-email")
+    txn.http:req_set_header("x-auth-request-user", session[3])
+    txn.http:req_set_header("x-auth-request-email", session[4])
+    return
+  end
+  local state = random_id(txn)
+  local nonce = random_id(txn)
+  local pending = sign(txn, { "state", core.now().sec + state_ttl, state, nonce, txn.f:url() })
+  local separator = "?"
+  if string.find(authorization_endpoint, "?", 1, true) then
+    separator = "&"
+  end
+  redirect(txn, authorization_endpoint .. separator .. "response_type=code&scope=openid%20email%20profile" ..
+    "&client_id=" .. url_encode(client_id) ..
+    "&redirect_uri=" .. url_encode(redirect_uri) ..
+    "&state=" .. state .. "&nonce=" .. nonce, {
+    cookie_name .. "_state=" .. pending .. "; Path=/; Max-Age=" .. state_ttl .. "; HttpOnly; Secure; SameSite=Lax",
+  })
+end, 9)
+`
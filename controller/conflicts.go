@@ -0,0 +1,195 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
+	"github.com/haproxytech/kubernetes-ingress/controller/metrics"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// Policies applied to a host/path claimed by ingresses of different namespaces
+const (
+	CONFLICT_POLICY_OLDEST = "oldest"
+	CONFLICT_POLICY_CLASS  = "class"
+	CONFLICT_POLICY_REJECT = "reject"
+)
+
+// hostPathClaim is a host/path route claimed by an ingress or a CR, identified by its routeKey
+type hostPathClaim struct {
+	key  string
+	host string
+	path string
+}
+
+// hostPath is a host/path route of the main or internal frontends
+type hostPath struct {
+	internal bool
+	host     string
+	path     string
+}
+
+// claimant is an ingress or a BlueGreen or TrafficSplit CR claiming host/paths
+type claimant struct {
+	ref     utils.ResourceRef
+	created time.Time
+	class   bool
+}
+
+// resolveHostPathConflicts detects host/paths claimed by ingresses and BlueGreen or TrafficSplit CRs
+// of different namespaces and returns the claims which are not served according to the conflict policy,
+// along with the reason. Canary ingresses share the paths of their main ingress
+// on purpose and are not considered.
+func (c *HAProxyController) resolveHostPathConflicts() map[hostPathClaim]string {
+	claims := make(map[hostPath][]claimant)
+	for _, namespace := range c.Store.Namespaces {
+		if !namespace.Relevant {
+			continue
+		}
+		for _, ingress := range namespace.Ingresses {
+			if ingress.Status == DELETED || !c.igClassIsSupported(ingress) || c.canaryEnabled(ingress) {
+				continue
+			}
+			if _, rejected := c.hostOwnershipRejected(ingress.Ref()); rejected {
+				continue
+			}
+			internal := c.ingressInternal(ingress)
+			ingClaimant := claimant{ref: ingress.Ref(), created: ingress.Created, class: hasClass(ingress)}
+			for _, rule := range ingress.Rules {
+				for _, path := range rule.Paths {
+					if path.Status == DELETED {
+						continue
+					}
+					key := hostPath{internal: internal, host: rule.Host, path: path.Path}
+					claims[key] = append(claims[key], ingClaimant)
+				}
+			}
+		}
+	}
+	addCRClaim := func(ref utils.ResourceRef, created time.Time, host, path string) {
+		if ns, ok := c.Store.Namespaces[ref.Namespace]; !ok || !ns.Relevant {
+			return
+		}
+		if _, rejected := c.hostOwnershipRejected(ref); rejected {
+			return
+		}
+		key := hostPath{host: host, path: path}
+		claims[key] = append(claims[key], claimant{ref: ref, created: created})
+	}
+	for _, bg := range c.Store.CR.BlueGreens {
+		addCRClaim(bg.Ref(), bg.Created, bg.Host, bg.Active.Path)
+	}
+	for _, ts := range c.Store.CR.TrafficSplits {
+		if len(ts.Backends) != 0 {
+			addCRClaim(ts.Ref(), ts.Created, ts.Host, ts.Backends[0].Path.Path)
+		}
+	}
+
+	rejected := make(map[hostPathClaim]string)
+	var conflicts int
+	for key, claimants := range claims {
+		if !multipleNamespaces(claimants) {
+			continue
+		}
+		conflicts++
+		host, path := key.host, key.path
+		if host == "" {
+			host = "*"
+		}
+		var winner *claimant
+		if c.OSArgs.HostPathConflictPolicy != CONFLICT_POLICY_REJECT {
+			sort.Slice(claimants, func(i, j int) bool {
+				return c.claimPrecedes(claimants[i], claimants[j])
+			})
+			winner = &claimants[0]
+		}
+		for i, cl := range claimants {
+			if winner != nil && i == 0 {
+				continue
+			}
+			var msg string
+			if winner == nil {
+				msg = fmt.Sprintf("host/path '%s%s' rejected: claimed by resources of different namespaces", host, path)
+			} else {
+				msg = fmt.Sprintf("host/path '%s%s' ignored: claimed by %s '%s/%s' (policy '%s')", host, path, strings.ToLower(winner.ref.Kind), winner.ref.Namespace, winner.ref.Name, c.OSArgs.HostPathConflictPolicy)
+			}
+			rejected[hostPathClaim{key: routeKey(cl.ref), host: host, path: path}] = msg
+			logger.Warningf("%s '%s/%s': %s", cl.ref.Kind, cl.ref.Namespace, cl.ref.Name, msg)
+			utils.WarningEvent(cl.ref, "HostPathConflict", msg)
+		}
+	}
+	metrics.HostPathConflicts.Set(conflicts)
+	return rejected
+}
+
+// hostPathRejected checks if the host/path claim of the ingress or CR lost a conflict
+func (c *HAProxyController) hostPathRejected(ref utils.ResourceRef, host, path string) (msg string, rejected bool) {
+	if host == "" {
+		host = "*"
+	}
+	msg, rejected = c.hostPathConflicts[hostPathClaim{key: routeKey(ref), host: host, path: path}]
+	return
+}
+
+// crRouteRejected checks if the host/path of a BlueGreen or TrafficSplit CR is not owned by its namespace
+// or lost a conflict, it is then not routed.
+func (c *HAProxyController) crRouteRejected(ref utils.ResourceRef, host, path string) bool {
+	if _, rejected := c.hostOwnershipRejected(ref); rejected {
+		return true
+	}
+	_, rejected := c.hostPathRejected(ref, host, path)
+	return rejected
+}
+
+// claimPrecedes checks if the claim of a wins over the claim of b.
+// With the class policy ingresses explicitly selecting the controller, through the
+// ingress.class annotation or an IngressClass, win over ingresses without class and CRs.
+// The oldest claim wins otherwise, the namespace and name breaking ties.
+func (c *HAProxyController) claimPrecedes(a, b claimant) bool {
+	if c.OSArgs.HostPathConflictPolicy == CONFLICT_POLICY_CLASS {
+		if a.class != b.class {
+			return a.class
+		}
+	}
+	if !a.created.Equal(b.created) {
+		return a.created.Before(b.created)
+	}
+	if a.ref.Namespace != b.ref.Namespace {
+		return a.ref.Namespace < b.ref.Namespace
+	}
+	if a.ref.Name != b.ref.Name {
+		return a.ref.Name < b.ref.Name
+	}
+	return a.ref.Kind < b.ref.Kind
+}
+
+func hasClass(ingress *store.Ingress) bool {
+	return ingress.Class != "" || annotations.GetValue("ingress.class", ingress.Annotations) != ""
+}
+
+func multipleNamespaces(claimants []claimant) bool {
+	for _, cl := range claimants[1:] {
+		if cl.ref.Namespace != claimants[0].ref.Namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -15,17 +15,26 @@
 package controller
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/haproxytech/client-native/v2/models"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/watch"
 
+	"github.com/haproxytech/kubernetes-ingress/controller/admin"
+	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
 	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/process"
+	"github.com/haproxytech/kubernetes-ingress/controller/metrics"
 	"github.com/haproxytech/kubernetes-ingress/controller/route"
+	"github.com/haproxytech/kubernetes-ingress/controller/service"
 	"github.com/haproxytech/kubernetes-ingress/controller/status"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
 	"github.com/haproxytech/kubernetes-ingress/controller/utils"
@@ -41,6 +50,7 @@ type HAProxyController struct {
 	PublishService *utils.NamespaceValue
 	AuxCfgModTime  int64
 	eventChan      chan SyncDataEvent
+	endpointsChan  chan SyncDataEvent
 	statusChan     chan status.SyncIngress
 	k8s            *K8s
 	ready          bool
@@ -48,6 +58,39 @@ type HAProxyController struct {
 	restart        bool
 	updateHandlers []UpdateHandler
 	haproxyProcess process.Process
+	lastDriftCheck time.Time
+	lastStatsSync  time.Time
+	lastAlertCheck time.Time
+	// counters of backends by name and firing alerts by namespace/ingress/backend/alert, see checkAlerts
+	alertCounters map[string]backendCounters
+	alertsFiring  map[string]struct{}
+	// InternalPublishService is the PublishService of internal ingresses
+	InternalPublishService *utils.NamespaceValue
+	// Version of the controller, recorded in configuration handoff snapshots
+	Version      string
+	handoffHash  string
+	handoffSaved string
+	// reloads of the last minute and delayed reload, see "max-reloads-per-minute"
+	reloads       []time.Time
+	reloadPending bool
+	// healthzFailed is set when healthz reports the last configuration apply failure
+	healthzFailed bool
+	// introspection is the state served by the admin API
+	introspection *admin.Introspection
+	// unknownAnnotations are the unknown annotations reported, by kind/namespace/name/annotation
+	unknownAnnotations map[string]struct{}
+	// maintenance holds the operations of the admin API, nil without it
+	maintenance *maintenance
+	// hostPathConflicts are the host/path claims of ingresses not served due to a conflict
+	hostPathConflicts map[hostPathClaim]string
+	// hostOwnershipViolations are the ingresses and CRs, by routeKey, claiming hosts owned by other namespaces
+	hostOwnershipViolations map[string]string
+	// supervisor holds the configurations HAProxy ran with
+	supervisor supervisor
+	// canary is the state of risky changes rollout, see "canary-rollout"
+	canary canary
+	// readyPods are the pods, by namespace/name, whose "pod-readiness-gate" condition is set
+	readyPods map[string]struct{}
 }
 
 // Wrapping a Native-Client transaction and commit it.
@@ -75,15 +118,46 @@ func (c *HAProxyController) Start() {
 	logger.SetLevel(c.OSArgs.LogLevel.LogLevel)
 
 	// Initialize controller
+	c.Cfg.InternalBinds = c.OSArgs.InternalIngressClass != ""
+	c.Cfg.SSLPassthroughPort = c.OSArgs.SSLPassthroughBindPort != 0
+	route.InternalBinds = c.Cfg.InternalBinds
+	service.BackendNameStrategy = c.OSArgs.BackendNameStrategy
 	err = c.Cfg.Init()
 	if err != nil {
 		logger.Panic(err)
 	}
+	annotations.SetSecretDir(c.Cfg.Env.SecretDir)
+	annotations.SetPatternDir(c.Cfg.Env.PatternDir)
+	if c.OSArgs.HealthzFailOnSyncError {
+		// ACL file loaded by healthz frontend
+		logger.Panic(ioutil.WriteFile(c.healthzSyncFailedACL(), []byte("\n"), 0644)) //nolint:gosec
+	}
+	if c.OSArgs.AdminPort != 0 {
+		// ACL file loaded by healthz frontend, the instance is not drained at startup
+		logger.Panic(ioutil.WriteFile(c.healthzDrainACL(), []byte("\n"), 0644)) //nolint:gosec
+	}
 
-	c.Client, err = api.Init(c.Cfg.Env.TransactionDir, c.Cfg.Env.MainCFGFile, c.Cfg.Env.HAProxyBinary, c.Cfg.Env.RuntimeSocket)
+	if c.OSArgs.HandoffDir != "" {
+		c.handoffAdopt()
+	}
+
+	c.Client, err = api.Init(c.Cfg.Env.TransactionDir, c.Cfg.Env.MainCFGFile, c.Cfg.Env.HAProxyBinary, c.Cfg.Env.RuntimeSocket, c.Cfg.Env.MasterSocket)
 	if err != nil {
 		logger.Panic(err)
 	}
+	if c.OSArgs.CanaryRollout.Name != "" && controllerPodRef().Name == "" {
+		logger.Error("canary-rollout: POD_NAME environment variable not set, canary rollout disabled")
+		c.OSArgs.CanaryRollout = utils.NamespaceValue{}
+	}
+	if c.OSArgs.ValidateConfig || c.OSArgs.CanaryRollout.Name != "" {
+		c.Client.APISetValidator(func(config string) (string, error) {
+			config, err := c.canaryGate(config)
+			if err != nil {
+				return "", err
+			}
+			return config, c.validateStaged([]byte(config))
+		})
+	}
 
 	c.initHandlers()
 	c.haproxyStartup()
@@ -96,16 +170,16 @@ func (c *HAProxyController) Start() {
 			Name:      parts[1],
 		}
 	}
-
-	// Get K8s client
-	c.k8s, err = GetKubernetesClient(c.OSArgs.DisableServiceExternalName)
-	if c.OSArgs.External {
-		kubeconfig := filepath.Join(utils.HomeDir(), ".kube", "config")
-		if c.OSArgs.KubeConfig != "" {
-			kubeconfig = c.OSArgs.KubeConfig
+	parts = strings.Split(c.OSArgs.InternalPublishService, "/")
+	if len(parts) == 2 && c.OSArgs.InternalIngressClass != "" {
+		c.InternalPublishService = &utils.NamespaceValue{
+			Namespace: parts[0],
+			Name:      parts[1],
 		}
-		c.k8s, err = GetRemoteKubernetesClient(kubeconfig, c.OSArgs.DisableServiceExternalName)
 	}
+
+	// Get K8s client
+	c.k8s, err = c.kubernetesClient()
 	if err != nil {
 		logger.Panic(err)
 	}
@@ -115,23 +189,81 @@ func (c *HAProxyController) Start() {
 	} else {
 		logger.Printf("Running on Kubernetes version: %s %s", k8sVersion.String(), k8sVersion.Platform)
 	}
+	c.startEventRecorder()
 
-	// Monitor k8s events
 	c.eventChan = make(chan SyncDataEvent, watch.DefaultChanSize*6)
+	c.endpointsChan = make(chan SyncDataEvent, watch.DefaultChanSize*6)
+
+	// Admin API
+	if c.OSArgs.AdminPort != 0 {
+		c.introspection = &admin.Introspection{}
+		c.maintenance = &maintenance{eventChan: c.eventChan}
+		go func() {
+			logger.Error(admin.Server{
+				Address:       c.OSArgs.AdminAddress,
+				Port:          c.OSArgs.AdminPort,
+				TLSCert:       c.OSArgs.AdminTLSCert,
+				TLSKey:        c.OSArgs.AdminTLSKey,
+				Client:        c.Client,
+				K8s:           c.k8s.API,
+				Introspection: c.introspection,
+				Maintenance:   c.maintenance,
+			}.Run())
+		}()
+	}
+
+	// Monitor k8s events
 	go c.monitorChanges()
-	if c.PublishService != nil {
+	if c.PublishService != nil || c.InternalPublishService != nil {
 		// Update Ingress status
 		c.statusChan = make(chan status.SyncIngress, watch.DefaultChanSize*6)
-		go status.UpdateIngress(c.k8s.API, c.Store, c.statusChan)
+		go status.UpdateIngress(c.k8s.API, c.Store, c.statusChan, c.ingressInternal)
 	}
 }
 
+// kubernetesClient returns the in-cluster client, or the kubeconfig one in External mode
+func (c *HAProxyController) kubernetesClient() (k8s *K8s, err error) {
+	if !c.OSArgs.External {
+		k8s, err = GetKubernetesClient(c.OSArgs.DisableServiceExternalName, c.OSArgs.KubeAPIQPS, c.OSArgs.KubeAPIBurst)
+	} else {
+		kubeconfig := filepath.Join(utils.HomeDir(), ".kube", "config")
+		if c.OSArgs.KubeConfig != "" {
+			kubeconfig = c.OSArgs.KubeConfig
+		}
+		k8s, err = GetRemoteKubernetesClient(kubeconfig, c.OSArgs.DisableServiceExternalName, c.OSArgs.KubeAPIQPS, c.OSArgs.KubeAPIBurst)
+	}
+	if err != nil {
+		return
+	}
+	k8s.NotReadyServers = c.OSArgs.PodReadinessGate != ""
+	if c.OSArgs.EndpointsNodeSelector != "" {
+		if k8s.NodeSelector, err = labels.Parse(c.OSArgs.EndpointsNodeSelector); err != nil {
+			return nil, fmt.Errorf("endpoints-node-selector: %w", err)
+		}
+		logger.Printf("Only endpoints of nodes matching '%s' used", k8s.NodeSelector)
+	}
+	if !c.OSArgs.LocalNodeOnly {
+		return
+	}
+	// node name provided via the downward API, or the hostname of host-network pods
+	if k8s.LocalNode = os.Getenv("NODE_NAME"); k8s.LocalNode == "" {
+		if k8s.LocalNode, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+	logger.Printf("Only endpoints of node '%s' used when available", k8s.LocalNode)
+	return
+}
+
 // Stop handles shutting down HAProxyController
 func (c *HAProxyController) Stop() {
 	logger.Infof("Stopping Ingress Controller")
 	logger.Error(c.haproxyService("stop"))
 }
 
+// persistentSyncFailures is the number of consecutive failed syncs from which failures are logged as errors
+const persistentSyncFailures = 5
+
 // updateHAProxy is the control loop syncing HAProxy configuration
 func (c *HAProxyController) updateHAProxy() {
 	var reload bool
@@ -147,6 +279,14 @@ func (c *HAProxyController) updateHAProxy() {
 		c.Client.APIDisposeTransaction()
 	}()
 
+	if !c.ready && c.OSArgs.DelayBindsUntilReady {
+		// binds are created along with the initial configuration
+		_, err = c.httpBindHandler().Update(c.Store, &c.Cfg, c.Client)
+		logger.Error(err)
+		utils.ReloadRequired("binds_created", c.Cfg.FrontHTTP+"/"+c.Cfg.FrontHTTPS, "delay-binds-until-ready")
+		c.reload = true
+	}
+
 	reload, c.restart = c.handleGlobalConfig()
 	c.reload = c.reload || reload
 
@@ -154,6 +294,10 @@ func (c *HAProxyController) updateHAProxy() {
 		logger.Error(route.CustomRoutesReset(c.Client))
 	}
 
+	c.enforceAnnotationPolicies()
+	c.reportUnknownAnnotations()
+	c.hostOwnershipViolations = c.checkHostOwnership()
+	c.hostPathConflicts = c.resolveHostPathConflicts()
 	for _, namespace := range c.Store.Namespaces {
 		if !namespace.Relevant {
 			continue
@@ -166,14 +310,18 @@ func (c *HAProxyController) updateHAProxy() {
 				logger.Debugf("ingress '%s/%s' ignored: no matching IngressClass", ingress.Namespace, ingress.Name)
 				continue
 			}
-			if c.PublishService != nil && ingress.Status == ADDED {
+			if _, rejected := c.hostOwnershipRejected(ingress.Ref()); rejected {
+				continue
+			}
+			internal := c.ingressInternal(ingress)
+			if c.publishService(internal) != nil && ingress.Status == ADDED {
 				select {
-				case c.statusChan <- status.SyncIngress{Ingress: ingress}:
+				case c.statusChan <- status.SyncIngress{Ingress: ingress, Internal: internal}:
 				default:
 					logger.Errorf("Ingress %s/%s: unable to sync status: sync channel full", ingress.Namespace, ingress.Name)
 				}
 			}
-			if ingress.DefaultBackend != nil {
+			if ingress.DefaultBackend != nil && !c.defaultBackendPerHost(ingress) && !c.canaryEnabled(ingress) {
 				if reload, err = c.setDefaultService(ingress, []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS}); err != nil {
 					logger.Errorf("Ingress '%s/%s': default backend: %s", ingress.Namespace, ingress.Name, err)
 				} else {
@@ -182,10 +330,23 @@ func (c *HAProxyController) updateHAProxy() {
 			}
 			// Ingress secrets
 			logger.Tracef("ingress '%s/%s': processing secrets...", ingress.Namespace, ingress.Name)
+			var alpn string
+			var crtList bool
+			if len(ingress.TLS) != 0 {
+				alpn, crtList = c.ingressALPN(ingress)
+			}
 			for _, tls := range ingress.TLS {
 				if tls.Status == store.DELETED {
 					continue
 				}
+				if !c.crossNsSecretAllowed(ingress, tls.SecretName) {
+					logger.Errorf("Ingress '%s/%s': secret '%s' not allowed by its namespace", ingress.Namespace, ingress.Name, tls.SecretName)
+					continue
+				}
+				if crtList && tls.Host != "" {
+					c.handleCrtListEntry(ingress, tls, alpn)
+					continue
+				}
 				_, err = c.Cfg.Certificates.HandleTLSSecret(c.Store, haproxy.SecretCtx{
 					DefaultNS:  ingress.Namespace,
 					SecretPath: tls.SecretName,
@@ -193,6 +354,9 @@ func (c *HAProxyController) updateHAProxy() {
 				})
 				logger.Error(err)
 			}
+			if c.OSArgs.WildcardCertsNamespace != "" {
+				c.handleWildcardCerts(ingress)
+			}
 			// Ingress annotations
 			logger.Tracef("ingress '%s/%s': processing annotations...", ingress.Namespace, ingress.Name)
 			if len(ingress.Rules) == 0 {
@@ -204,6 +368,9 @@ func (c *HAProxyController) updateHAProxy() {
 			logger.Tracef("ingress '%s/%s': processing rules...", ingress.Namespace, ingress.Name)
 			for _, rule := range ingress.Rules {
 				for _, path := range rule.Paths {
+					if _, rejected := c.hostPathRejected(ingress.Ref(), rule.Host, path.Path); rejected {
+						continue
+					}
 					if reload, err = c.handleIngressPath(ingress, rule.Host, path, ruleIDs); err != nil {
 						logger.Errorf("Ingress '%s/%s': %s", ingress.Namespace, ingress.Name, err)
 					} else {
@@ -211,40 +378,93 @@ func (c *HAProxyController) updateHAProxy() {
 					}
 				}
 			}
+			if c.defaultBackendPerHost(ingress) {
+				if reload, err = c.handleHostDefaultBackend(ingress, ruleIDs); err != nil {
+					logger.Errorf("Ingress '%s/%s': host default backend: %s", ingress.Namespace, ingress.Name, err)
+				} else {
+					c.reload = c.reload || reload
+				}
+			}
+		}
+	}
+
+	for key, bg := range c.Store.CR.BlueGreens {
+		if reload, err = c.handleBlueGreen(bg); err != nil {
+			logger.Errorf("BlueGreen '%s': %s", key, err)
+		} else {
+			c.reload = c.reload || reload
+		}
+	}
+
+	for key, ts := range c.Store.CR.TrafficSplits {
+		if reload, err = c.handleTrafficSplit(ts); err != nil {
+			logger.Errorf("TrafficSplit '%s': %s", key, err)
+		} else {
+			c.reload = c.reload || reload
 		}
 	}
 
+	c.handleSNIServices()
+
+	c.reload = route.CustomRoutesClean() || c.reload
+	service.BackendNamesClean()
+
 	for _, handler := range c.updateHandlers {
 		reload, err = handler.Update(c.Store, &c.Cfg, c.Client)
 		logger.Error(err)
 		c.reload = c.reload || reload
 	}
 
+	commitStart := time.Now()
 	err = c.Client.APICommitTransaction()
 	if err != nil {
 		logger.Error("unable to Sync HAProxy configuration !!")
 		logger.Error(err)
+		stats := c.Client.APITransactionStats()
+		metrics.SyncConsecutiveFailures.Set(stats.ConsecutiveFailures)
+		if stats.ConsecutiveFailures >= persistentSyncFailures {
+			logger.Errorf("HAProxy configuration sync failed %d consecutive times, next attempt in %s", stats.ConsecutiveFailures, time.Until(stats.NextRetry).Round(time.Second))
+			if stats.ConsecutiveFailures == persistentSyncFailures {
+				utils.WarningEvent(controllerPodRef(), "SyncFailing", fmt.Sprintf("HAProxy configuration sync failed %d consecutive times: %s", stats.ConsecutiveFailures, err))
+			}
+		} else {
+			logger.Warningf("HAProxy configuration sync will be retried in %s", time.Until(stats.NextRetry).Round(time.Second))
+		}
+		c.setHealthzSyncFailed(true)
+		c.notify("config_error", utils.PendingReloadReasons(), time.Since(commitStart), err)
 		c.clean(true)
 		return
 	}
+	c.canaryCommitted()
+	metrics.SyncConsecutiveFailures.Set(0)
 
 	if !c.ready {
 		c.setToReady()
 	}
+	c.updateConfigMetrics()
+	if c.introspection != nil {
+		c.updateIntrospection()
+	}
+
+	if c.handoffSkipReload() {
+		logger.Info("configuration identical to the adopted one, HAProxy reload skipped")
+		c.reload = false
+		c.restart = false
+		utils.ReloadReasons()
+	}
 
 	switch {
 	case c.restart:
-		if err = c.haproxyService("restart"); err != nil {
-			logger.Error(err)
-		} else {
-			logger.Info("HAProxy restarted")
-		}
+		err = c.haproxyReload("restart")
+	case c.reload && !c.reloadAllowed():
+		c.delayReload()
 	case c.reload:
-		if err = c.haproxyService("reload"); err != nil {
-			logger.Error(err)
-		} else {
-			logger.Info("HAProxy reloaded")
-		}
+		err = c.haproxyReload("reload")
+	}
+	c.setHealthzSyncFailed(err != nil)
+
+	if c.OSArgs.HandoffDir != "" {
+		c.handoffSave()
 	}
 
 	c.clean(false)
@@ -258,7 +478,7 @@ func (c *HAProxyController) setToReady() {
 		return c.Client.FrontendBindEdit("healthz",
 			models.Bind{
 				Name:    "v4",
-				Address: "0.0.0.0:1042",
+				Address: fmt.Sprintf("0.0.0.0:%d", c.OSArgs.HealthzBindPort),
 			})
 	}))
 	if !c.OSArgs.DisableIPV6 {
@@ -266,7 +486,7 @@ func (c *HAProxyController) setToReady() {
 			return c.Client.FrontendBindCreate("healthz",
 				models.Bind{
 					Name:    "v6",
-					Address: ":::1042",
+					Address: fmt.Sprintf(":::%d", c.OSArgs.HealthzBindPort),
 					V4v6:    true,
 				})
 		}))
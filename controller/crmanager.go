@@ -58,6 +58,10 @@ func NewCRManager(s *store.K8s, restCfg *rest.Config, cacheResync time.Duration,
 	}
 	manager.RegisterCoreCR(NewGlobalCR())
 	manager.RegisterCoreCR(NewDefaultsCR())
+	manager.RegisterCoreCR(NewDenylistCR())
+	manager.RegisterCoreCR(NewBlueGreenCR())
+	manager.RegisterCoreCR(NewTrafficSplitCR())
+	manager.RegisterCoreCR(NewAnnotationPolicyCR())
 	return manager
 }
 
@@ -15,9 +15,15 @@
 package controller
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/haproxytech/client-native/v2/models"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 	corev1alpha1 "github.com/haproxytech/kubernetes-ingress/crs/api/core/v1alpha1"
 	informers "github.com/haproxytech/kubernetes-ingress/crs/generated/informers/externalversions"
 )
@@ -28,6 +34,18 @@ type GlobalCR struct {
 type DefaultsCR struct {
 }
 
+type DenylistCR struct {
+}
+
+type BlueGreenCR struct {
+}
+
+type TrafficSplitCR struct {
+}
+
+type AnnotationPolicyCR struct {
+}
+
 func NewGlobalCR() GlobalCR {
 	return GlobalCR{}
 }
@@ -36,6 +54,22 @@ func NewDefaultsCR() DefaultsCR {
 	return DefaultsCR{}
 }
 
+func NewDenylistCR() DenylistCR {
+	return DenylistCR{}
+}
+
+func NewBlueGreenCR() BlueGreenCR {
+	return BlueGreenCR{}
+}
+
+func NewTrafficSplitCR() TrafficSplitCR {
+	return TrafficSplitCR{}
+}
+
+func NewAnnotationPolicyCR() AnnotationPolicyCR {
+	return AnnotationPolicyCR{}
+}
+
 func (c GlobalCR) GetKind() string {
 	return "Global"
 }
@@ -67,17 +101,28 @@ func (c GlobalCR) GetInformer(eventChan chan SyncDataEvent, factory informers.Sh
 	return informer
 }
 
+// ProcessEvent records the Global CR, the configuration of the one selected by the controller is used
 func (c GlobalCR) ProcessEvent(s *store.K8s, job SyncDataEvent) bool {
+	key := job.Namespace + "/" + job.Name
 	if job.Data == nil {
-		s.CR.Global = nil
-		return true
+		delete(s.CR.Globals, key)
+	} else {
+		data, ok := job.Data.(*corev1alpha1.Global)
+		if !ok {
+			logger.Warning(CoreGroupVersion + ": type mismatch with Global kind")
+			return false
+		}
+		s.CR.Globals[key] = &store.ControllerCR{
+			Name:    key,
+			Labels:  data.GetLabels(),
+			Created: data.GetCreationTimestamp().Time,
+			Global:  data.Spec.Config,
+		}
 	}
-	data, ok := job.Data.(*corev1alpha1.Global)
-	if !ok {
-		logger.Warning(CoreGroupVersion + ": type mismatch with Global kind")
-		return false
+	s.CR.Global = nil
+	if selected := selectCR(s, c.GetKind(), s.CR.Globals); selected != nil {
+		s.CR.Global = selected.Global
 	}
-	s.CR.Global = data.Spec.Config
 	return true
 }
 
@@ -112,16 +157,355 @@ func (c DefaultsCR) GetInformer(eventChan chan SyncDataEvent, factory informers.
 	return informer
 }
 
+// ProcessEvent records the Defaults CR, the configuration of the one selected by the controller is used.
+// Namespace scoped Defaults CRs are selected the same way among the ones of each namespace.
 func (c DefaultsCR) ProcessEvent(s *store.K8s, job SyncDataEvent) bool {
+	key := job.Namespace + "/" + job.Name
+	delete(s.CR.DefaultsCRs, key)
+	delete(s.CR.NamespaceDefaultsCRs, key)
+	if job.Data != nil {
+		data, ok := job.Data.(*corev1alpha1.Defaults)
+		if !ok {
+			logger.Warning(CoreGroupVersion + ": type mismatch with Defaults kind")
+			return false
+		}
+		cr := &store.ControllerCR{
+			Name:      key,
+			Namespace: job.Namespace,
+			Labels:    data.GetLabels(),
+			Created:   data.GetCreationTimestamp().Time,
+			Defaults:  data.Spec.Config,
+		}
+		switch data.Spec.Scope {
+		case "", "global":
+			s.CR.DefaultsCRs[key] = cr
+		case "namespace":
+			s.CR.NamespaceDefaultsCRs[key] = cr
+		default:
+			logger.Warningf("Defaults CR '%s': unknown scope '%s', ignored", key, data.Spec.Scope)
+		}
+	}
+	s.CR.Defaults = nil
+	if selected := selectCR(s, c.GetKind(), s.CR.DefaultsCRs); selected != nil {
+		s.CR.Defaults = selected.Defaults
+	}
+	namespaces := make(map[string]map[string]*store.ControllerCR)
+	for name, cr := range s.CR.NamespaceDefaultsCRs {
+		if namespaces[cr.Namespace] == nil {
+			namespaces[cr.Namespace] = make(map[string]*store.ControllerCR)
+		}
+		namespaces[cr.Namespace][name] = cr
+	}
+	s.CR.NamespaceDefaults = make(map[string]*models.Defaults, len(namespaces))
+	for ns, crs := range namespaces {
+		if selected := selectCR(s, "namespace "+ns+" Defaults", crs); selected != nil && selected.Defaults != nil {
+			s.CR.NamespaceDefaults[ns] = selected.Defaults
+		}
+	}
+	return true
+}
+
+// selectCR returns the Global or Defaults CR selected by the controller, other selected CRs are reported
+func selectCR(s *store.K8s, kind string, crs map[string]*store.ControllerCR) *store.ControllerCR {
+	selected, ignored := s.CR.SelectCR(crs)
+	if len(ignored) != 0 {
+		logger.Warningf("%s CR '%s' used, other selected %s CRs ignored: %s", kind, selected.Name, kind, strings.Join(ignored, ", "))
+	}
+	return selected
+}
+
+func (c DenylistCR) GetKind() string {
+	return "Denylist"
+}
+
+func (c DenylistCR) GetInformer(eventChan chan SyncDataEvent, factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+	informer := factory.Core().V1alpha1().Denylists().Informer()
+
+	sendToChannel := func(eventChan chan SyncDataEvent, object interface{}, status store.Status) {
+		data := object.(*corev1alpha1.Denylist)
+		logger.Debugf("%s %s: %s", data.GetNamespace(), status, data.GetName())
+		if status == DELETED {
+			eventChan <- SyncDataEvent{SyncType: CUSTOM_RESOURCE, CRKind: c.GetKind(), Namespace: data.GetNamespace(), Name: data.GetName(), Data: nil}
+			return
+		}
+		eventChan <- SyncDataEvent{SyncType: CUSTOM_RESOURCE, CRKind: c.GetKind(), Namespace: data.GetNamespace(), Name: data.GetName(), Data: data}
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			sendToChannel(eventChan, obj, store.ADDED)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			sendToChannel(eventChan, newObj, store.MODIFIED)
+		},
+		DeleteFunc: func(obj interface{}) {
+			sendToChannel(eventChan, obj, store.DELETED)
+		},
+	})
+	return informer
+}
+
+func (c DenylistCR) ProcessEvent(s *store.K8s, job SyncDataEvent) bool {
+	key := job.Namespace + "/" + job.Name
+	if job.Data == nil {
+		delete(s.CR.Denylists, key)
+		return true
+	}
+	data, ok := job.Data.(*corev1alpha1.Denylist)
+	if !ok {
+		logger.Warning(CoreGroupVersion + ": type mismatch with Denylist kind")
+		return false
+	}
+	// Denylists apply to all client frontends, so they are restricted like AnnotationPolicies
+	if job.Namespace != s.ConfigMaps.Main.Namespace {
+		logger.Warningf("Denylist '%s' ignored: denylists must be in namespace '%s'", key, s.ConfigMaps.Main.Namespace)
+		return false
+	}
+	now := time.Now()
+	entries := make([]*store.DenylistEntry, 0, len(data.Spec.Entries))
+	for _, e := range data.Spec.Entries {
+		entry := &store.DenylistEntry{Address: e.Address}
+		if e.Expires != nil {
+			expires := e.Expires.Time
+			entry.Expires = &expires
+			if now.After(expires) {
+				entry.Status = store.DELETED
+			}
+		}
+		entries = append(entries, entry)
+	}
+	s.CR.Denylists[key] = entries
+	return true
+}
+
+func (c BlueGreenCR) GetKind() string {
+	return "BlueGreen"
+}
+
+func (c BlueGreenCR) GetInformer(eventChan chan SyncDataEvent, factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+	informer := factory.Core().V1alpha1().BlueGreens().Informer()
+
+	sendToChannel := func(eventChan chan SyncDataEvent, object interface{}, status store.Status) {
+		data := object.(*corev1alpha1.BlueGreen)
+		logger.Debugf("%s %s: %s", data.GetNamespace(), status, data.GetName())
+		if status == DELETED {
+			eventChan <- SyncDataEvent{SyncType: CUSTOM_RESOURCE, CRKind: c.GetKind(), Namespace: data.GetNamespace(), Name: data.GetName(), Data: nil}
+			return
+		}
+		eventChan <- SyncDataEvent{SyncType: CUSTOM_RESOURCE, CRKind: c.GetKind(), Namespace: data.GetNamespace(), Name: data.GetName(), Data: data}
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			sendToChannel(eventChan, obj, store.ADDED)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			sendToChannel(eventChan, newObj, store.MODIFIED)
+		},
+		DeleteFunc: func(obj interface{}) {
+			sendToChannel(eventChan, obj, store.DELETED)
+		},
+	})
+	return informer
+}
+
+func (c BlueGreenCR) ProcessEvent(s *store.K8s, job SyncDataEvent) bool {
+	key := job.Namespace + "/" + job.Name
+	if job.Data == nil {
+		delete(s.CR.BlueGreens, key)
+		return true
+	}
+	data, ok := job.Data.(*corev1alpha1.BlueGreen)
+	if !ok {
+		logger.Warning(CoreGroupVersion + ": type mismatch with BlueGreen kind")
+		return false
+	}
+	spec := data.Spec
+	if spec.PathType == "" {
+		spec.PathType = store.PATH_TYPE_PREFIX
+	}
+	newPath := func(svc corev1alpha1.BlueGreenService) *store.IngressPath {
+		return &store.IngressPath{
+			SvcName:       svc.Name,
+			SvcPortInt:    int64(svc.Port.IntValue()),
+			SvcPortString: svc.Port.StrVal,
+			Path:          spec.Path,
+			PathTypeMatch: spec.PathType,
+		}
+	}
+	// Active and standby are swapped in a single store update so
+	// the next sync routes the host/path to the promoted backend.
+	bg := &store.BlueGreen{
+		Namespace:     job.Namespace,
+		Name:          job.Name,
+		UID:           string(data.UID),
+		Created:       data.CreationTimestamp.Time,
+		Host:          spec.Host,
+		StandbyWeight: spec.StandbyWeight,
+	}
+	switch spec.Active {
+	case "blue":
+		bg.Active, bg.Standby = newPath(spec.Blue), newPath(spec.Green)
+	case "green":
+		bg.Active, bg.Standby = newPath(spec.Green), newPath(spec.Blue)
+	default:
+		logger.Errorf("BlueGreen '%s': unknown active deployment '%s'", key, spec.Active)
+		return false
+	}
+	s.CR.BlueGreens[key] = bg
+	return true
+}
+
+func (c TrafficSplitCR) GetKind() string {
+	return "TrafficSplit"
+}
+
+func (c TrafficSplitCR) GetInformer(eventChan chan SyncDataEvent, factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+	informer := factory.Core().V1alpha1().TrafficSplits().Informer()
+
+	sendToChannel := func(eventChan chan SyncDataEvent, object interface{}, status store.Status) {
+		data := object.(*corev1alpha1.TrafficSplit)
+		logger.Debugf("%s %s: %s", data.GetNamespace(), status, data.GetName())
+		if status == DELETED {
+			eventChan <- SyncDataEvent{SyncType: CUSTOM_RESOURCE, CRKind: c.GetKind(), Namespace: data.GetNamespace(), Name: data.GetName(), Data: nil}
+			return
+		}
+		eventChan <- SyncDataEvent{SyncType: CUSTOM_RESOURCE, CRKind: c.GetKind(), Namespace: data.GetNamespace(), Name: data.GetName(), Data: data}
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			sendToChannel(eventChan, obj, store.ADDED)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			sendToChannel(eventChan, newObj, store.MODIFIED)
+		},
+		DeleteFunc: func(obj interface{}) {
+			sendToChannel(eventChan, obj, store.DELETED)
+		},
+	})
+	return informer
+}
+
+func (c TrafficSplitCR) ProcessEvent(s *store.K8s, job SyncDataEvent) bool {
+	key := job.Namespace + "/" + job.Name
 	if job.Data == nil {
-		s.CR.Defaults = nil
+		delete(s.CR.TrafficSplits, key)
 		return true
 	}
-	data, ok := job.Data.(*corev1alpha1.Defaults)
+	data, ok := job.Data.(*corev1alpha1.TrafficSplit)
 	if !ok {
-		logger.Warning(CoreGroupVersion + ": type mismatch with Defaults kind")
+		logger.Warning(CoreGroupVersion + ": type mismatch with TrafficSplit kind")
 		return false
 	}
-	s.CR.Defaults = data.Spec.Config
+	spec := data.Spec
+	if spec.PathType == "" {
+		spec.PathType = store.PATH_TYPE_PREFIX
+	}
+	ts := &store.TrafficSplit{
+		Namespace: job.Namespace,
+		Name:      job.Name,
+		UID:       string(data.UID),
+		Created:   data.CreationTimestamp.Time,
+		Host:      spec.Host,
+		Backends:  make([]*store.TrafficSplitBackend, 0, len(spec.Backends)),
+	}
+	for _, b := range spec.Backends {
+		ts.Backends = append(ts.Backends, &store.TrafficSplitBackend{
+			Path: &store.IngressPath{
+				SvcName:       b.Name,
+				SvcPortInt:    int64(b.Port.IntValue()),
+				SvcPortString: b.Port.StrVal,
+				Path:          spec.Path,
+				PathTypeMatch: spec.PathType,
+			},
+			Weight: b.Weight,
+		})
+	}
+	s.CR.TrafficSplits[key] = ts
+	return true
+}
+
+func (c AnnotationPolicyCR) GetKind() string {
+	return "AnnotationPolicy"
+}
+
+func (c AnnotationPolicyCR) GetInformer(eventChan chan SyncDataEvent, factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+	informer := factory.Core().V1alpha1().AnnotationPolicies().Informer()
+
+	sendToChannel := func(eventChan chan SyncDataEvent, object interface{}, status store.Status) {
+		data := object.(*corev1alpha1.AnnotationPolicy)
+		logger.Debugf("%s %s: %s", data.GetNamespace(), status, data.GetName())
+		if status == DELETED {
+			eventChan <- SyncDataEvent{SyncType: CUSTOM_RESOURCE, CRKind: c.GetKind(), Namespace: data.GetNamespace(), Name: data.GetName(), Data: nil}
+			return
+		}
+		eventChan <- SyncDataEvent{SyncType: CUSTOM_RESOURCE, CRKind: c.GetKind(), Namespace: data.GetNamespace(), Name: data.GetName(), Data: data}
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			sendToChannel(eventChan, obj, store.ADDED)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			sendToChannel(eventChan, newObj, store.MODIFIED)
+		},
+		DeleteFunc: func(obj interface{}) {
+			sendToChannel(eventChan, obj, store.DELETED)
+		},
+	})
+	return informer
+}
+
+// ProcessEvent only accepts policies of the namespace of the controller ConfigMap,
+// otherwise tenants could restrict the annotations of other namespaces.
+func (c AnnotationPolicyCR) ProcessEvent(s *store.K8s, job SyncDataEvent) bool {
+	key := job.Namespace + "/" + job.Name
+	if job.Data == nil {
+		_, ok := s.CR.AnnotationPolicies[key]
+		delete(s.CR.AnnotationPolicies, key)
+		return ok
+	}
+	data, ok := job.Data.(*corev1alpha1.AnnotationPolicy)
+	if !ok {
+		logger.Warning(CoreGroupVersion + ": type mismatch with AnnotationPolicy kind")
+		return false
+	}
+	if job.Namespace != s.ConfigMaps.Main.Namespace {
+		logger.Warningf("AnnotationPolicy '%s' ignored: policies must be in namespace '%s'", key, s.ConfigMaps.Main.Namespace)
+		return false
+	}
+	policy := &store.AnnotationPolicy{
+		Name:       key,
+		Namespaces: make(map[string]struct{}, len(data.Spec.Namespaces)),
+		Forbidden:  make(map[string]struct{}, len(data.Spec.Forbidden)),
+		Limits:     make(map[string]store.AnnotationLimit, len(data.Spec.Limits)),
+	}
+	for _, ns := range data.Spec.Namespaces {
+		policy.Namespaces[ns] = struct{}{}
+	}
+	for _, name := range data.Spec.Forbidden {
+		policy.Forbidden[name] = struct{}{}
+	}
+	for _, l := range data.Spec.Limits {
+		var limit store.AnnotationLimit
+		var err error
+		switch {
+		case l.MaxTime != "":
+			limit.MaxTime, err = utils.ParseTime(l.MaxTime)
+		case l.MaxSize != "":
+			limit.MaxSize, err = utils.ParseSize(l.MaxSize)
+		case l.MaxValue != nil:
+			limit.MaxValue = l.MaxValue
+		default:
+			err = fmt.Errorf("no maximum value")
+		}
+		if err != nil {
+			logger.Errorf("AnnotationPolicy '%s': limit of annotation '%s' ignored: %s", key, l.Annotation, err)
+			continue
+		}
+		policy.Limits[l.Annotation] = limit
+	}
+	s.CR.AnnotationPolicies[key] = policy
 	return true
 }
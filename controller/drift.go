@@ -0,0 +1,115 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+// auditDrift compares, every "drift-check-period", the HAProxy runtime state with the desired one
+// in order to report (and restore if "drift-correction" is enabled) changes made outside of the controller,
+// like manual runtime socket commands or transactions left behind by a failed sync.
+func (c *HAProxyController) auditDrift() {
+	if c.OSArgs.DriftCheckPeriod == 0 || !c.ready || time.Since(c.lastDriftCheck) < c.OSArgs.DriftCheckPeriod {
+		return
+	}
+	c.lastDriftCheck = time.Now()
+	drifts := c.auditStaleTransactions()
+	for _, ns := range c.Store.Namespaces {
+		if !ns.Relevant {
+			continue
+		}
+		for _, endpoints := range ns.Endpoints {
+			for _, portEndpoints := range endpoints.Ports {
+				if portEndpoints.BackendName == "" {
+					continue
+				}
+				drifts += c.auditBackendSrvs(portEndpoints)
+			}
+		}
+	}
+	if drifts != 0 {
+		logger.Warningf("drift check: %d difference(s) found between HAProxy runtime state and controller state", drifts)
+	} else {
+		logger.Debug("drift check: no difference found between HAProxy runtime state and controller state")
+	}
+}
+
+func (c *HAProxyController) auditStaleTransactions() (drifts int) {
+	for _, id := range c.Client.APIStaleTransactions() {
+		drifts++
+		logger.Warningf("drift check: stale transaction '%s'", id)
+		if !c.OSArgs.DriftCorrection {
+			continue
+		}
+		if err := c.Client.APIDeleteTransaction(id); err != nil {
+			logger.Errorf("drift check: unable to delete stale transaction '%s': %s", id, err)
+		}
+	}
+	return drifts
+}
+
+// auditBackendSrvs compares runtime addresses and admin states of a backend servers with the endpoints
+// they are expected to point to. Servers without endpoint address are expected to be in maintenance.
+func (c *HAProxyController) auditBackendSrvs(endpoints *store.PortEndpoints) (drifts int) {
+	runtimeSrvs, err := c.Client.BackendServersStateGet(endpoints.BackendName)
+	if err != nil {
+		logger.Errorf("drift check: unable to get servers state of backend '%s': %s", endpoints.BackendName, err)
+		return 0
+	}
+	runtime := make(map[string]*models.RuntimeServer, len(runtimeSrvs))
+	for _, srv := range runtimeSrvs {
+		runtime[srv.Name] = srv
+	}
+	for _, srv := range endpoints.HAProxySrvs {
+		rSrv, ok := runtime[srv.Name]
+		if !ok {
+			continue
+		}
+		state := endpoints.SrvState(srv.Address)
+		var diffs []string
+		if rSrv.AdminState != state {
+			diffs = append(diffs, fmt.Sprintf("state '%s' instead of '%s'", rSrv.AdminState, state))
+		}
+		port := endpoints.AddrPort(srv.Address)
+		addrDrift := srv.Address != "" && (rSrv.Address != srv.Address || rSrv.Port == nil || *rSrv.Port != port)
+		if addrDrift {
+			rPort := "<none>"
+			if rSrv.Port != nil {
+				rPort = fmt.Sprint(*rSrv.Port)
+			}
+			diffs = append(diffs, fmt.Sprintf("address '%s:%s' instead of '%s:%d'", rSrv.Address, rPort, srv.Address, port))
+		}
+		if len(diffs) == 0 {
+			continue
+		}
+		drifts++
+		logger.Warningf("drift check: server '%s/%s' has %s", endpoints.BackendName, srv.Name, strings.Join(diffs, ", "))
+		if !c.OSArgs.DriftCorrection {
+			continue
+		}
+		if addrDrift {
+			logger.Error(c.Client.SetServerAddr(endpoints.BackendName, srv.Name, srv.Address, int(port)))
+		}
+		logger.Error(c.Client.SetServerState(endpoints.BackendName, srv.Name, state))
+	}
+	return drifts
+}
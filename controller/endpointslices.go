@@ -0,0 +1,165 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+// endpointSliceServiceIndex indexes EndpointSlices by "namespace/service"
+const endpointSliceServiceIndex = "service"
+
+func endpointSliceService(obj interface{}) ([]string, error) {
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", obj)
+	}
+	service := slice.Labels[discoveryv1.LabelServiceName]
+	if service == "" {
+		return nil, nil
+	}
+	return []string{slice.Namespace + "/" + service}, nil
+}
+
+// EventsEndpointSlices sends, for each EndpointSlice event, the endpoints of the slice service
+// merged from all its slices. Unlike Endpoints subsets, slices keep per pod the port a named
+// target port resolves to, so pods resolving it differently are all part of the endpoints.
+func (k *K8s) EventsEndpointSlices(channel chan SyncDataEvent, stop chan struct{}, informer cache.SharedIndexInformer) {
+	send := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		keys, err := endpointSliceService(obj)
+		if err != nil {
+			k.Logger.Errorf("%s: Invalid data from k8s api, %s", ENDPOINTS, obj)
+			return
+		}
+		if len(keys) == 0 {
+			return
+		}
+		slices, err := informer.GetIndexer().ByIndex(endpointSliceServiceIndex, keys[0])
+		if err != nil {
+			k.Logger.Error(err)
+			return
+		}
+		slice := obj.(*discoveryv1.EndpointSlice)
+		item := k.mergeEndpointSlices(slice.Namespace, slice.Labels[discoveryv1.LabelServiceName], slices)
+		if item == nil {
+			return
+		}
+		k.Logger.Tracef("%s %s: %s", ENDPOINTS, item.Status, item.Service)
+		channel <- SyncDataEvent{SyncType: ENDPOINTS, Namespace: item.Namespace, Data: item}
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    send,
+		DeleteFunc: send,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSlice, ok1 := oldObj.(*discoveryv1.EndpointSlice)
+			newSlice, ok2 := newObj.(*discoveryv1.EndpointSlice)
+			if ok1 && ok2 && oldSlice.ResourceVersion == newSlice.ResourceVersion {
+				// informer resync
+				return
+			}
+			send(newObj)
+		},
+	})
+	k.addEndpointsResync(func() {
+		for _, key := range informer.GetIndexer().ListIndexFuncValues(endpointSliceServiceIndex) {
+			if slices, err := informer.GetIndexer().ByIndex(endpointSliceServiceIndex, key); err == nil && len(slices) > 0 {
+				send(slices[0])
+			}
+		}
+	})
+	go informer.Run(stop)
+}
+
+// mergeEndpointSlices merges the EndpointSlices of a service, nil is returned for ignored services.
+// Dual stack services have a slice per IP family, only IPv4 addresses are used when there are some,
+// like Endpoints do for the primary family.
+func (k *K8s) mergeEndpointSlices(namespace, service string, slices []interface{}) *store.Endpoints {
+	if ignoredEndpoints(namespace, service) {
+		return nil
+	}
+	item := &store.Endpoints{
+		Namespace: namespace,
+		Service:   service,
+		Ports:     make(map[string]*store.PortEndpoints),
+		Status:    ADDED,
+		Pods:      make(map[string]string),
+	}
+	if len(slices) == 0 {
+		item.Status = DELETED
+		return item
+	}
+	addressType := discoveryv1.AddressTypeIPv6
+	for _, obj := range slices {
+		if obj.(*discoveryv1.EndpointSlice).AddressType == discoveryv1.AddressTypeIPv4 {
+			addressType = discoveryv1.AddressTypeIPv4
+			break
+		}
+	}
+	ports := make(map[string]int64)
+	addrPorts := make(map[string]map[string]int64)
+	nodes := make(map[string]string)
+	notReady := make(map[string]struct{})
+	for _, obj := range slices {
+		slice := obj.(*discoveryv1.EndpointSlice)
+		if slice.AddressType != addressType {
+			continue
+		}
+		for _, port := range slice.Ports {
+			if port.Port == nil {
+				continue
+			}
+			var name string
+			if port.Name != nil {
+				name = *port.Name
+			}
+			if _, ok := addrPorts[name]; !ok {
+				ports[name] = int64(*port.Port)
+				addrPorts[name] = make(map[string]int64)
+			}
+			for _, endpoint := range slice.Endpoints {
+				ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+				// pods waiting for their readiness gate
+				waiting := k.NotReadyServers && endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod"
+				if !ready && !waiting {
+					continue
+				}
+				for _, address := range endpoint.Addresses {
+					if !ready {
+						notReady[address] = struct{}{}
+					}
+					addrPorts[name][address] = int64(*port.Port)
+					if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+						item.Pods[address] = endpoint.TargetRef.Name
+					}
+					if endpoint.NodeName != nil {
+						nodes[address] = *endpoint.NodeName
+					}
+				}
+			}
+		}
+	}
+	for name, addresses := range addrPorts {
+		item.Ports[name] = k.newPortEndpoints(ports[name], addresses, nodes, notReady)
+	}
+	return item
+}
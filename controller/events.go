@@ -0,0 +1,47 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// eventComponent is the source of the Kubernetes Events recorded by the controller
+const eventComponent = "haproxy-ingress-controller"
+
+// startEventRecorder records Kubernetes Events about the resources configuring HAProxy
+func (c *HAProxyController) startEventRecorder() {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: c.k8s.API.CoreV1().Events("")})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: eventComponent})
+	utils.SetEventRecorder(func(ref utils.ResourceRef, eventType, reason, message string) {
+		if ref.Name == "" {
+			return
+		}
+		recorder.Event(&corev1.ObjectReference{
+			APIVersion: ref.APIVersion,
+			Kind:       ref.Kind,
+			Namespace:  ref.Namespace,
+			Name:       ref.Name,
+			UID:        types.UID(ref.UID),
+		}, eventType, reason, message)
+	})
+}
@@ -15,6 +15,11 @@
 package controller
 
 import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/go-test/deep"
@@ -24,7 +29,9 @@ import (
 	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
 	"github.com/haproxytech/kubernetes-ingress/controller/configuration"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 func (c *HAProxyController) handleGlobalConfig() (reload, restart bool) {
@@ -32,6 +39,8 @@ func (c *HAProxyController) handleGlobalConfig() (reload, restart bool) {
 	reload = c.defaultsCfg() || reload
 	c.handleDefaultCert()
 	reload = c.handleDefaultService() || reload
+	reload = c.handleHealthzService() || reload
+	reload = c.handleFrontendsHealthz() || reload
 	_ = c.handleIngressAnnotations(store.Ingress{})
 	return reload, restart
 }
@@ -39,6 +48,7 @@ func (c *HAProxyController) handleGlobalConfig() (reload, restart bool) {
 func (c *HAProxyController) globalCfg() (reload, restart bool) {
 	var newGlobal, global *models.Global
 	var newLg models.LogTargets
+	newRings := make(map[string][]string)
 	var err error
 	var updated []string
 	global, err = c.Client.GlobalGetConfiguration()
@@ -55,11 +65,12 @@ func (c *HAProxyController) globalCfg() (reload, restart bool) {
 	if c.Store.CR.Global != nil {
 		newGlobal = c.Store.CR.Global
 	} else {
-		for _, a := range annotations.GetGlobalAnnotations(newGlobal, &newLg) {
+		for _, a := range annotations.GetGlobalAnnotations(newGlobal, &newLg, newRings) {
 			annValue := annotations.GetValue(a.GetName(), c.Store.ConfigMaps.Main.Annotations)
-			err = a.Process(annValue)
+			err = annotations.Process(a, annValue)
 			if err != nil {
 				logger.Errorf("annotation %s: %s", a.GetName(), err)
+				annotations.InvalidAnnotationEvent(c.Store.ConfigMaps.Main.Ref(), a.GetName(), err)
 			}
 		}
 	}
@@ -68,6 +79,7 @@ func (c *HAProxyController) globalCfg() (reload, restart bool) {
 	if len(updated) != 0 {
 		logger.Error(c.Client.GlobalPushConfiguration(*newGlobal))
 		logger.Debugf("Global config updated: %s\nRestart required", updated)
+		utils.ReloadRequired("global_updated", "global", strings.Join(updated, ", "))
 		restart = true
 	}
 	updated = deep.Equal(newLg, lg)
@@ -75,18 +87,30 @@ func (c *HAProxyController) globalCfg() (reload, restart bool) {
 		c.Client.GlobalDeleteLogTargets()
 		logger.Error(c.Client.GlobalCreateLogTargets(newLg))
 		logger.Debugf("Syslog servers updated: %s\nRestart required", updated)
+		utils.ReloadRequired("log_targets_updated", "global", strings.Join(updated, ", "))
+		restart = true
+	}
+	rings, err := c.Client.RingsGet()
+	logger.Error(err)
+	updated = deep.Equal(newRings, rings)
+	if err == nil && len(updated) != 0 {
+		logger.Error(c.Client.RingsReplace(newRings))
+		logger.Debugf("Ring buffers updated: %s\nRestart required", updated)
+		utils.ReloadRequired("rings_updated", "rings", strings.Join(updated, ", "))
 		restart = true
 	}
 	updatedSnipp, errSnipp := annotations.UpdateGlobalCfgSnippet(c.Client)
 	logger.Error(errSnipp)
 	if updatedSnipp {
 		logger.Debugf("Global config-snippet updated: %s\nRestart required", updated)
+		utils.ReloadRequired("config_snippet_updated", "global", "")
 		restart = true
 	}
 	updatedSnipp, errSnipp = annotations.UpdateFrontendCfgSnippet(c.Client, "http", "https", "stats")
 	logger.Error(errSnipp)
 	if updatedSnipp {
 		logger.Debugf("Frontend config-snippet updated: %s\nReload required", updated)
+		utils.ReloadRequired("config_snippet_updated", "frontends", "")
 		reload = true
 	}
 	return
@@ -105,7 +129,10 @@ func (c *HAProxyController) defaultsCfg() (reload bool) {
 	} else {
 		for _, a := range annotations.GetDefaultsAnnotations(newDefaults) {
 			annValue := annotations.GetValue(a.GetName(), c.Store.ConfigMaps.Main.Annotations)
-			logger.Error(a.Process(annValue))
+			if err = annotations.Process(a, annValue); err != nil {
+				logger.Errorf("annotation %s: %s", a.GetName(), err)
+				annotations.InvalidAnnotationEvent(c.Store.ConfigMaps.Main.Ref(), a.GetName(), err)
+			}
 		}
 	}
 	configuration.SetDefaults(newDefaults)
@@ -117,36 +144,132 @@ func (c *HAProxyController) defaultsCfg() (reload bool) {
 		}
 		reload = true
 		logger.Debugf("Defaults config updated: %s\nReload required", updated)
+		utils.ReloadRequired("defaults_updated", "defaults", strings.Join(updated, ", "))
 	}
 	return
 }
 
 // handleDefaultService configures HAProy default backend provided via cli param "default-backend-service"
+// or ConfigMap, and the static response returned instead when "default-backend-response" is set.
 func (c *HAProxyController) handleDefaultService() (reload bool) {
-	dsvcData := annotations.GetValue("default-backend-service")
+	if code := annotations.GetValue("default-backend-response", c.Store.ConfigMaps.Main.Annotations); code != "" {
+		c.handleDefaultResponse(code)
+	}
+	dsvcData := annotations.GetValue("default-backend-service", c.Store.ConfigMaps.Main.Annotations)
 	if dsvcData == "" {
 		return
 	}
-	dsvc := strings.Split(dsvcData, "/")
+	ingress, err := c.localServiceIngress(dsvcData)
+	if err != nil {
+		logger.Errorf("default service '%s': %s", dsvcData, err)
+		return
+	}
+	if ingress == nil {
+		return
+	}
+	reload, err = c.setDefaultService(ingress, []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS})
+	if err != nil {
+		logger.Errorf("default service '%s': %s", dsvcData, err)
+		return
+	}
+	return reload
+}
 
-	if len(dsvc) != 2 {
-		logger.Errorf("default service '%s': invalid format", dsvcData)
+// handleDefaultResponse returns a static response with provided status code to requests matching no route,
+// the response body is the errorfile of that status code when provided in errorfiles ConfigMap.
+func (c *HAProxyController) handleDefaultResponse(code string) {
+	statusCode, err := strconv.ParseInt(code, 10, 64)
+	if err != nil || statusCode < 200 || statusCode > 599 {
+		logger.Errorf("default-backend-response: invalid status code '%s'", code)
 		return
 	}
-	if dsvc[0] == "" || dsvc[1] == "" {
+	rule := rules.ReqReturn{
+		StatusCode:    statusCode,
+		ContentType:   "text/plain",
+		ContentFormat: "string",
+		Content:       strconv.Quote(http.StatusText(int(statusCode))),
+		CondTest:      "!{ var(txn.path_match) -m found }",
+	}
+	if _, ok := c.Store.ConfigMaps.Errorfiles.Annotations[code]; ok {
+		rule.ContentType = ""
+		rule.ContentFormat = "errorfile"
+		rule.Content = filepath.Join(c.Cfg.Env.ErrFileDir, code)
+	}
+	for _, frontend := range []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS} {
+		logger.Error(c.Cfg.HAProxyRules.AddRule(rule, false, frontend))
+	}
+}
+
+// handleHealthzService routes healthz frontend requests to the service provided via "healthz-service"
+// ConfigMap key instead of answering them with the built-in monitor-uri.
+func (c *HAProxyController) handleHealthzService() (reload bool) {
+	frontend, err := c.Client.FrontendGet("healthz")
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	svcData := annotations.GetValue("healthz-service", c.Store.ConfigMaps.Main.Annotations)
+	if svcData == "" {
+		// Restore built-in healthz service
+		monitorURI := models.MonitorURI(c.OSArgs.HealthzPath)
+		monitorFail := c.healthzMonitorFail()
+		if frontend.MonitorURI != monitorURI || !reflect.DeepEqual(frontend.MonitorFail, monitorFail) {
+			frontend.MonitorURI = monitorURI
+			frontend.MonitorFail = monitorFail
+			frontend.DefaultBackend = ""
+			logger.Error(c.Client.FrontendEdit(frontend))
+			utils.ReloadRequired("healthz_updated", "healthz", "monitor-uri")
+			reload = true
+		}
+		return
+	}
+	ingress, err := c.localServiceIngress(svcData)
+	if err != nil {
+		logger.Errorf("healthz service '%s': %s", svcData, err)
 		return
 	}
+	if ingress == nil {
+		return
+	}
+	if frontend.MonitorURI != "" {
+		frontend.MonitorURI = ""
+		frontend.MonitorFail = nil
+		if err = c.Client.FrontendEdit(frontend); err != nil {
+			logger.Error(err)
+			return
+		}
+		utils.ReloadRequired("healthz_updated", "healthz", "healthz-service")
+		reload = true
+	}
+	ftReload, err := c.setDefaultService(ingress, []string{"healthz"})
+	if err != nil {
+		logger.Errorf("healthz service '%s': %s", svcData, err)
+	}
+	return reload || ftReload
+}
+
+// localServiceIngress returns an Ingress having as default backend
+// the service provided in "namespace/name" format.
+func (c *HAProxyController) localServiceIngress(svcData string) (*store.Ingress, error) {
+	dsvc := strings.Split(svcData, "/")
+	if len(dsvc) != 2 {
+		return nil, fmt.Errorf("invalid format")
+	}
+	if dsvc[0] == "" || dsvc[1] == "" {
+		return nil, nil
+	}
 	namespace, ok := c.Store.Namespaces[dsvc[0]]
 	if !ok {
-		logger.Errorf("default service '%s': namespace not found" + dsvc[0])
-		return
+		return nil, fmt.Errorf("namespace '%s' not found", dsvc[0])
 	}
 	service, ok := namespace.Services[dsvc[1]]
 	if !ok {
-		logger.Errorf("default service '%s': service name not found" + dsvc[1])
-		return
+		return nil, fmt.Errorf("service '%s' not found", dsvc[1])
 	}
-	ingress := &store.Ingress{
+	if len(service.Ports) == 0 {
+		return nil, fmt.Errorf("service '%s' has no ports", dsvc[1])
+	}
+	return &store.Ingress{
 		Namespace:   namespace.Name,
 		Name:        "DefaultService",
 		Annotations: map[string]string{},
@@ -155,13 +278,7 @@ func (c *HAProxyController) handleDefaultService() (reload bool) {
 			SvcPortInt:       service.Ports[0].Port,
 			IsDefaultBackend: true,
 		},
-	}
-	reload, err := c.setDefaultService(ingress, []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS})
-	if err != nil {
-		logger.Errorf("default service '%s/%s': %s", namespace.Name, service.Name, err)
-		return
-	}
-	return reload
+	}, nil
 }
 
 // handleDefaultCert configures default/fallback HAProxy certificate to use for client HTTPS requests.
@@ -32,15 +32,20 @@ func (c *HAProxyController) initHandlers() {
 	// handlers executed at reconciliation loop
 	c.updateHandlers = []UpdateHandler{
 		handler.HTTPS{
-			Enabled:  !c.OSArgs.DisableHTTPS,
-			CertDir:  c.Cfg.Env.FrontendCertDir,
-			IPv4:     !c.OSArgs.DisableIPV4,
-			AddrIPv4: c.OSArgs.IPV4BindAddr,
-			AddrIPv6: c.OSArgs.IPV6BindAddr,
-			IPv6:     !c.OSArgs.DisableIPV6,
-			Port:     c.OSArgs.HTTPSBindPort,
+			Enabled:         !c.OSArgs.DisableHTTPS,
+			CertDir:         c.Cfg.Env.FrontendCertDir,
+			IPv4:            !c.OSArgs.DisableIPV4,
+			AddrIPv4:        c.OSArgs.IPV4BindAddr,
+			AddrIPv6:        c.OSArgs.IPV6BindAddr,
+			IPv6:            !c.OSArgs.DisableIPV6,
+			Port:            c.OSArgs.HTTPSBindPort,
+			PassthroughPort: c.OSArgs.SSLPassthroughBindPort,
+			ChainAddr:       c.OSArgs.SSLPassthroughChainAddr,
+			ChainPort:       c.OSArgs.SSLPassthroughChainPort,
+			ChainProxy:      c.OSArgs.SSLPassthroughChainProxy,
 		},
 		handler.ProxyProtocol{},
+		handler.RequestLimits{},
 		handler.ErrorFile{},
 		handler.TCPServices{
 			SetDefaultService: c.setDefaultService,
@@ -51,26 +56,30 @@ func (c *HAProxyController) initHandlers() {
 			AddrIPv6:          c.OSArgs.IPV6BindAddr,
 		},
 		handler.PatternFiles{},
+		&handler.Denylist{},
 		handler.Refresh{},
 	}
 	if c.OSArgs.PprofEnabled {
-		c.updateHandlers = append(c.updateHandlers, handler.Pprof{})
+		c.updateHandlers = append(c.updateHandlers, handler.Pprof{Port: c.OSArgs.PprofPort})
 	}
 	c.updateHandlers = append(c.updateHandlers, handler.Refresh{})
 }
 
 func (c *HAProxyController) startupHandlers() error {
+	httpBind := c.httpBindHandler()
+	if c.OSArgs.DelayBindsUntilReady {
+		// binds are created by the first sync, see updateHAProxy
+		httpBind.HTTP = false
+		httpBind.HTTPS = false
+	}
 	handlers := []UpdateHandler{
-		handler.HTTPBind{
-			HTTP:      !c.OSArgs.DisableHTTP,
-			HTTPS:     !c.OSArgs.DisableHTTPS,
-			IPv4:      !c.OSArgs.DisableIPV4,
-			IPv6:      !c.OSArgs.DisableIPV6,
-			HTTPPort:  c.OSArgs.HTTPBindPort,
-			HTTPSPort: c.OSArgs.HTTPSBindPort,
-			IPv4Addr:  c.OSArgs.IPV4BindAddr,
-			IPv6Addr:  c.OSArgs.IPV6BindAddr,
-		}}
+		handler.LocalServices{
+			HealthzPort:   c.OSArgs.HealthzBindPort,
+			StatsPort:     c.OSArgs.StatsBindPort,
+			LocalPeerPort: c.OSArgs.LocalPeerPort,
+		},
+		httpBind,
+	}
 	if c.OSArgs.External {
 		handlers = append(handlers, handler.GlobalCfg{})
 	}
@@ -82,3 +91,24 @@ func (c *HAProxyController) startupHandlers() error {
 	}
 	return nil
 }
+
+func (c *HAProxyController) httpBindHandler() handler.HTTPBind {
+	httpBind := handler.HTTPBind{
+		HTTP:      !c.OSArgs.DisableHTTP,
+		HTTPS:     !c.OSArgs.DisableHTTPS,
+		IPv4:      !c.OSArgs.DisableIPV4,
+		IPv6:      !c.OSArgs.DisableIPV6,
+		HTTPPort:  c.OSArgs.HTTPBindPort,
+		HTTPSPort: c.OSArgs.HTTPSBindPort,
+		IPv4Addr:  c.OSArgs.IPV4BindAddr,
+		IPv6Addr:  c.OSArgs.IPV6BindAddr,
+		StatsPort: c.OSArgs.StatsBindPort,
+	}
+	if c.OSArgs.InternalIngressClass != "" {
+		httpBind.InternalHTTPPort = c.OSArgs.InternalHTTPBindPort
+		httpBind.InternalHTTPSPort = c.OSArgs.InternalHTTPSBindPort
+		httpBind.InternalIPv4Addr = c.OSArgs.InternalIPV4BindAddr
+		httpBind.InternalIPv6Addr = c.OSArgs.InternalIPV6BindAddr
+	}
+	return httpBind
+}
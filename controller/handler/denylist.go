@@ -0,0 +1,107 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"net"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/renameio"
+
+	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+const denylistMap = "denylist"
+
+// Denylist denies traffic from addresses listed in Denylist CRs.
+// Once the denylist file is loaded by HAProxy, updates are pushed
+// via the runtime API without reloading.
+type Denylist struct {
+	loaded  bool
+	content string
+}
+
+func (h *Denylist) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyClient) (reload bool, err error) {
+	filename := haproxy.GetMapPath(denylistMap)
+	if len(k.CR.Denylists) == 0 {
+		if h.loaded {
+			h.loaded = false
+			h.content = ""
+			logger.Error(os.Remove(filename))
+			logger.Debug("Denylist removed, reload required")
+			utils.ReloadRequired("denylist_removed", denylistMap, "")
+			reload = true
+		}
+		return
+	}
+	// Get addresses
+	unique := make(map[string]struct{})
+	for _, entries := range k.CR.Denylists {
+		for _, entry := range entries {
+			if entry.Status == store.DELETED {
+				continue
+			}
+			address := strings.TrimSpace(entry.Address)
+			if ip := net.ParseIP(address); ip == nil {
+				if _, _, err = net.ParseCIDR(address); err != nil {
+					logger.Errorf("incorrect address '%s' in Denylist", address)
+					continue
+				}
+			}
+			unique[address] = struct{}{}
+		}
+	}
+	addresses := make([]string, 0, len(unique))
+	for address := range unique {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	// Configure rules
+	for _, frontend := range cfg.ClientFrontends() {
+		err = cfg.HAProxyRules.AddRule(rules.ReqDeny{SrcIPsMap: denylistMap}, false, frontend)
+		if err != nil {
+			return
+		}
+	}
+	// Update denylist file
+	content := strings.Join(addresses, "\n")
+	if h.loaded && content == h.content {
+		return
+	}
+	if err = renameio.WriteFile(filename, []byte(content+"\n"), 0644); err != nil {
+		return
+	}
+	h.content = content
+	if !h.loaded {
+		h.loaded = true
+		logger.Debug("Denylist created, reload required")
+		utils.ReloadRequired("denylist_created", denylistMap, "")
+		return true, nil
+	}
+	if err = api.SetACLContent(filename, addresses); err != nil {
+		logger.Warningf("dynamic update of Denylist failed, reload required: %s", err)
+		utils.ReloadRequired("denylist_updated", denylistMap, err.Error())
+		return true, nil
+	}
+	logger.Debug("Denylist updated via runtime API")
+	return
+}
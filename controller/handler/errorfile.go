@@ -24,6 +24,7 @@ import (
 	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 type ErrorFile struct {
@@ -69,6 +70,7 @@ func (h ErrorFile) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProx
 		}
 		if f.updated {
 			logger.Debugf("updating errorfile for code '%s': reload required", code)
+			utils.ReloadRequired("errorfile_updated", code, "")
 			reload = true
 		}
 		c, _ := strconv.Atoi(code) // code already checked in newCode
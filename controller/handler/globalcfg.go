@@ -20,6 +20,7 @@ import (
 	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 type GlobalCfg struct {
@@ -38,6 +39,7 @@ func (h GlobalCfg) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProx
 	if err != nil {
 		return
 	}
+	utils.ReloadRequired("global_updated", "global", "startup")
 	reload = true
 	return
 }
@@ -15,9 +15,12 @@
 package handler
 
 import (
+	"fmt"
+
 	"github.com/haproxytech/client-native/v2/models"
 
 	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
 	"github.com/haproxytech/kubernetes-ingress/controller/utils"
@@ -32,6 +35,12 @@ type HTTPBind struct {
 	HTTPSPort int64
 	IPv4Addr  string
 	IPv6Addr  string
+	StatsPort int64
+	// Internal binds, disabled if port is 0
+	InternalHTTPPort  int64
+	InternalHTTPSPort int64
+	InternalIPv4Addr  string
+	InternalIPv6Addr  string
 }
 
 func (h HTTPBind) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyClient) (reload bool, err error) {
@@ -51,12 +60,14 @@ func (h HTTPBind) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxy
 		protos["v6"] = h.IPv6Addr
 
 		// IPv6 not disabled, so add v6 listening to stats frontend
-		errors.Add(api.FrontendBindCreate("stats",
-			models.Bind{
-				Name:    "v6",
-				Address: ":::1024",
-				V4v6:    false,
-			}))
+		statsBind := models.Bind{
+			Name:    "v6",
+			Address: fmt.Sprintf(":::%d", h.StatsPort),
+			V4v6:    false,
+		}
+		if err = api.FrontendBindEdit("stats", statsBind); err != nil {
+			errors.Add(api.FrontendBindCreate("stats", statsBind))
+		}
 	}
 	for ftName, ftPort := range frontends {
 		for proto, addr := range protos {
@@ -70,7 +81,42 @@ func (h HTTPBind) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxy
 			}
 		}
 	}
+	errors.Add(h.internalBinds(cfg, api))
 	err = errors.Result()
+	utils.ReloadRequired("binds_updated", cfg.FrontHTTP+"/"+cfg.FrontHTTPS, "")
 	reload = true
 	return
 }
+
+// internalBinds adds to the HTTP and HTTPS frontends the binds of internal ingresses,
+// their names are used to route requests to internal ingresses only.
+func (h HTTPBind) internalBinds(cfg *config.ControllerCfg, api api.HAProxyClient) error {
+	var errors utils.Errors
+	frontends := make(map[string]int64, 2)
+	if h.HTTP && h.InternalHTTPPort != 0 {
+		frontends[cfg.FrontHTTP] = h.InternalHTTPPort
+	}
+	if h.HTTPS && h.InternalHTTPSPort != 0 {
+		frontends[cfg.FrontHTTPS] = h.InternalHTTPSPort
+	}
+	protos := make(map[string]string, 2)
+	if h.IPv4 {
+		protos["v4"] = h.InternalIPv4Addr
+	}
+	if h.IPv6 {
+		protos["v6"] = h.InternalIPv6Addr
+	}
+	for ftName, ftPort := range frontends {
+		for proto, addr := range protos {
+			bind := models.Bind{
+				Name:    haproxy.INTERNAL_BIND_PREFIX + "-" + proto,
+				Address: addr,
+				Port:    utils.PtrInt64(ftPort),
+			}
+			if err := api.FrontendBindEdit(ftName, bind); err != nil {
+				errors.Add(api.FrontendBindCreate(ftName, bind))
+			}
+		}
+	}
+	return errors.Result()
+}
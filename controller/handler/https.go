@@ -17,6 +17,7 @@ package handler
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/haproxytech/client-native/v2/models"
 
@@ -38,21 +39,55 @@ type HTTPS struct {
 	AddrIPv6 string
 	CertDir  string
 	Alpn     string
+	// PassthroughPort is the dedicated port of the ssl-passthrough frontend.
+	// When not set, the ssl-passthrough frontend takes over the HTTPS port and
+	// chains the offloaded traffic to the HTTPS frontend via the loopback.
+	PassthroughPort int64
+	// ChainAddr and ChainPort are where the HTTPS frontend listens for the traffic
+	// chained by the ssl-passthrough frontend, ChainProxy is the PROXY protocol version
+	// used between them: "v1", "v2" or "none".
+	ChainAddr  string
+	ChainPort  int64
+	ChainProxy string
+}
+
+// passthroughBinds returns the binds of the ssl-passthrough frontend.
+func (h HTTPS) passthroughBinds() []models.Bind {
+	if h.PassthroughPort == 0 {
+		return h.bindList(false)
+	}
+	dedicated := h
+	dedicated.Port = h.PassthroughPort
+	return dedicated.bindList(false)
+}
+
+// chainPort returns the port the HTTPS frontend listens on when chained to the ssl-passthrough frontend.
+func (h HTTPS) chainPort() int64 {
+	if h.ChainPort != 0 {
+		return h.ChainPort
+	}
+	return h.Port
 }
 
 func (h HTTPS) bindList(passhthrough bool) (binds []models.Bind) {
+	port := h.Port
+	acceptProxy := false
+	if passhthrough {
+		port = h.chainPort()
+		acceptProxy = h.ChainProxy != "none"
+	}
 	if h.IPv4 {
 		binds = append(binds, models.Bind{
 			Address: func() (addr string) {
 				addr = h.AddrIPv4
 				if passhthrough {
-					addr = "127.0.0.1"
+					addr = h.ChainAddr
 				}
 				return
 			}(),
-			Port:        utils.PtrInt64(h.Port),
+			Port:        utils.PtrInt64(port),
 			Name:        "v4",
-			AcceptProxy: passhthrough,
+			AcceptProxy: acceptProxy,
 		})
 	}
 	if h.IPv6 {
@@ -64,8 +99,8 @@ func (h HTTPS) bindList(passhthrough bool) (binds []models.Bind) {
 				}
 				return
 			}(),
-			Port:        utils.PtrInt64(h.Port),
-			AcceptProxy: passhthrough,
+			Port:        utils.PtrInt64(port),
+			AcceptProxy: acceptProxy,
 			Name:        "v6",
 			V4v6:        true,
 		})
@@ -116,6 +151,7 @@ func (h HTTPS) handleClientTLSAuth(k store.K8s, cfg *config.ControllerCfg, api a
 				return false, err
 			}
 		}
+		utils.ReloadRequired("client_tls_auth_updated", cfg.FrontHTTPS, "disabled")
 		reload = true
 		return
 	}
@@ -128,10 +164,54 @@ func (h HTTPS) handleClientTLSAuth(k store.K8s, cfg *config.ControllerCfg, api a
 			return false, err
 		}
 	}
+	utils.ReloadRequired("client_tls_auth_updated", cfg.FrontHTTPS, caFile)
 	reload = true
 	return
 }
 
+// handleBindOptions updates the TLS options of the HTTPS binds: the ALPN advertisement
+// of the main and internal binds, strict-sni so that TLS handshakes with an unknown or
+// missing SNI fail instead of being answered with the default certificate, and the
+// crt-list of the certificates served with per host options.
+func (h HTTPS) handleBindOptions(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyClient) (reload bool, err error) {
+	alpn := annotations.GetValue("tls-alpn", k.ConfigMaps.Main.Annotations)
+	internalALPN := annotations.GetValue("internal-tls-alpn", k.ConfigMaps.Main.Annotations)
+	if internalALPN == "" {
+		internalALPN = alpn
+	}
+	strictSNI, annErr := utils.GetBoolValue(annotations.GetValue("strict-sni", k.ConfigMaps.Main.Annotations), "strict-sni")
+	if annErr != nil {
+		logger.Errorf("ConfigMap: annotation strict-sni: %s", annErr)
+		annotations.InvalidAnnotationEvent(k.ConfigMaps.Main.Ref(), "strict-sni", annErr)
+	}
+	var crtList string
+	if cfg.Certificates.CrtListEnabled() {
+		crtList = cfg.Certificates.CrtListFile()
+	}
+	binds, err := api.FrontendBindsGet(cfg.FrontHTTPS)
+	if err != nil {
+		return
+	}
+	for _, bind := range binds {
+		bindALPN := alpn
+		if strings.HasPrefix(bind.Name, haproxy.INTERNAL_BIND_PREFIX) {
+			bindALPN = internalALPN
+		}
+		if bind.Alpn == bindALPN && bind.StrictSni == strictSNI && bind.CrtList == crtList {
+			continue
+		}
+		bind.Alpn = bindALPN
+		bind.StrictSni = strictSNI
+		bind.CrtList = crtList
+		if err = api.FrontendBindEdit(cfg.FrontHTTPS, *bind); err != nil {
+			return false, err
+		}
+		utils.ReloadRequired("bind_options_updated", cfg.FrontHTTPS+"/"+bind.Name, fmt.Sprintf("alpn=%s strict-sni=%t crt-list=%s", bindALPN, strictSNI, crtList))
+		reload = true
+	}
+	return
+}
+
 func (h HTTPS) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyClient) (reload bool, err error) {
 	if !h.Enabled {
 		logger.Debugf("Cannot proceed with SSL Passthrough update, HTTPS is disabled")
@@ -148,6 +228,7 @@ func (h HTTPS) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyCli
 			cfg.HTTPS = true
 			reload = true
 			logger.Debug("SSLOffload enabeld, reload required")
+			utils.ReloadRequired("ssl_offload_enabled", cfg.FrontHTTPS, "")
 		}
 		r, err := h.handleClientTLSAuth(k, cfg, api)
 		if err != nil {
@@ -159,6 +240,7 @@ func (h HTTPS) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyCli
 		cfg.HTTPS = false
 		reload = true
 		logger.Debug("SSLOffload disabled, reload required")
+		utils.ReloadRequired("ssl_offload_disabled", cfg.FrontHTTPS, "")
 	}
 	// ssl-passthrough
 	_, errFtSSL := api.FrontendGet(cfg.FrontSSL)
@@ -168,6 +250,7 @@ func (h HTTPS) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyCli
 			cfg.SSLPassthrough = true
 			reload = true
 			logger.Debug("SSLPassthrough enabled, reload required")
+			utils.ReloadRequired("ssl_passthrough_enabled", cfg.FrontSSL, "")
 		}
 		logger.Error(h.sslPassthroughRules(k, cfg))
 	} else if errFtSSL == nil {
@@ -175,8 +258,17 @@ func (h HTTPS) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyCli
 		cfg.SSLPassthrough = false
 		reload = true
 		logger.Debug("SSLPassthrough disabled, reload required")
+		utils.ReloadRequired("ssl_passthrough_disabled", cfg.FrontSSL, "")
+	}
+	// after ssl-passthrough changes which reset the binds
+	if cfg.HTTPS {
+		r, err := h.handleBindOptions(k, cfg, api)
+		if err != nil {
+			return r, err
+		}
+		reload = reload || r
 	}
-	if cfg.Certificates.Updated() {
+	if cfg.Certificates.Updated(api) {
 		reload = true
 	}
 
@@ -191,29 +283,45 @@ func (h HTTPS) enableSSLPassthrough(cfg *config.ControllerCfg, api api.HAProxyCl
 		LogFormat:      "'%ci:%cp [%t] %ft %b/%s %Tw/%Tc/%Tt %B %ts %ac/%fc/%bc/%sc/%rc %sq/%bq %hr %hs haproxy.MAP_SNI: %[var(sess.sni)]'",
 		DefaultBackend: cfg.BackSSL,
 	}
+	if h.PassthroughPort != 0 {
+		// connections with an unknown SNI are closed on the dedicated port
+		frontend.DefaultBackend = ""
+	}
 	err = api.FrontendCreate(frontend)
 	if err != nil {
 		return err
 	}
-	for _, b := range h.bindList(false) {
+	for _, b := range h.passthroughBinds() {
 		if err = api.FrontendBindCreate(cfg.FrontSSL, b); err != nil {
 			return fmt.Errorf("cannot create bind for SSL Passthrough: %w", err)
 		}
 	}
+	if h.PassthroughPort != 0 {
+		return api.BackendSwitchingRuleCreate(cfg.FrontSSL, models.BackendSwitchingRule{
+			Index: utils.PtrInt64(0),
+			Name:  fmt.Sprintf("%%[var(txn.sni_match),field(1,.)]"),
+		})
+	}
 	// Create backend for proxy chaining (chaining
 	// ssl-passthrough frontend to ssl-offload backend)
+	server := models.Server{
+		Name:    cfg.FrontHTTPS,
+		Address: h.ChainAddr,
+		Port:    utils.PtrInt64(h.chainPort()),
+	}
+	switch h.ChainProxy {
+	case "v1":
+		server.SendProxy = "enabled"
+	case "v2":
+		server.SendProxyV2 = "enabled"
+	}
 	var errors utils.Errors
 	errors.Add(
 		api.BackendCreate(models.Backend{
 			Name: cfg.BackSSL,
 			Mode: "tcp",
 		}),
-		api.BackendServerCreate(cfg.BackSSL, models.Server{
-			Name:        cfg.FrontHTTPS,
-			Address:     "127.0.0.1",
-			Port:        utils.PtrInt64(h.Port),
-			SendProxyV2: "enabled",
-		}),
+		api.BackendServerCreate(cfg.BackSSL, server),
 		api.BackendSwitchingRuleCreate(cfg.FrontSSL, models.BackendSwitchingRule{
 			Index: utils.PtrInt64(0),
 			Name:  fmt.Sprintf("%%[var(txn.sni_match),field(1,.)]"),
@@ -228,6 +336,9 @@ func (h HTTPS) disableSSLPassthrough(cfg *config.ControllerCfg, api api.HAProxyC
 		return err
 	}
 	cfg.HAProxyRules.DeleteFrontend(cfg.FrontSSL)
+	if h.PassthroughPort != 0 {
+		return nil
+	}
 	err = api.BackendDelete(cfg.BackSSL)
 	if err != nil {
 		return err
@@ -0,0 +1,87 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"github.com/haproxytech/client-native/v2/models"
+
+	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// LocalServices sets the ports of the healthz, stats and local peer services and the paths
+// of the runtime API socket and the PID file, which are fixed in the base HAProxy configuration,
+// so that they can be moved when they conflict with other services, as with hostNetwork,
+// or when the runtime directory is not writable.
+type LocalServices struct {
+	HealthzPort   int64
+	StatsPort     int64
+	LocalPeerPort int64
+}
+
+func (h LocalServices) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyClient) (reload bool, err error) {
+	var errors utils.Errors
+	errors.Add(
+		h.setBindPort(api, "healthz", h.HealthzPort),
+		h.setBindPort(api, "stats", h.StatsPort),
+		api.PeerEntryEdit("localinstance", models.PeerEntry{
+			Name:    "local",
+			Address: utils.PtrString("127.0.0.1"),
+			Port:    utils.PtrInt64(h.LocalPeerPort),
+		}),
+		h.setRuntimePaths(api, cfg.Env),
+	)
+	return false, errors.Result()
+}
+
+// setBindPort sets the port of the IPv4 bind of a frontend, the IPv6 one is created
+// with the port by the HTTPBind handler.
+func (h LocalServices) setBindPort(api api.HAProxyClient, frontend string, port int64) error {
+	binds, err := api.FrontendBindsGet(frontend)
+	if err != nil {
+		return err
+	}
+	for _, bind := range binds {
+		if bind.Name == "v6" {
+			continue
+		}
+		if bind.Port != nil && *bind.Port == port {
+			return nil
+		}
+		bind.Port = utils.PtrInt64(port)
+		return api.FrontendBindEdit(frontend, *bind)
+	}
+	return nil
+}
+
+func (h LocalServices) setRuntimePaths(api api.HAProxyClient, env config.Env) error {
+	global, err := api.GlobalGetConfiguration()
+	if err != nil {
+		return err
+	}
+	update := global.Pidfile != env.PIDFile
+	global.Pidfile = env.PIDFile
+	if len(global.RuntimeAPIs) > 0 {
+		address := global.RuntimeAPIs[0].Address
+		update = update || address == nil || *address != env.RuntimeSocket
+		global.RuntimeAPIs[0].Address = &env.RuntimeSocket
+	}
+	if !update {
+		return nil
+	}
+	return api.GlobalPushConfiguration(*global)
+}
@@ -69,6 +69,7 @@ func (h PatternFiles) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAP
 		}
 		if f.updated {
 			logger.Debugf("updating PatternFile '%s': reload required", name)
+			utils.ReloadRequired("pattern_file_updated", name, "")
 			reload = true
 		}
 		f.inUse = false
@@ -15,15 +15,19 @@
 package handler
 
 import (
+	"fmt"
+
 	"github.com/haproxytech/client-native/v2/models"
 
 	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
 	"github.com/haproxytech/kubernetes-ingress/controller/route"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 type Pprof struct {
+	Port int64
 }
 
 func (h Pprof) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyClient) (reload bool, err error) {
@@ -40,7 +44,7 @@ func (h Pprof) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyCli
 		}
 		err = api.BackendServerCreate(pprofBackend, models.Server{
 			Name:    "pprof",
-			Address: "127.0.0.1:6060",
+			Address: fmt.Sprintf("127.0.0.1:%d", h.Port),
 		})
 		if err != nil {
 			return
@@ -58,6 +62,7 @@ func (h Pprof) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyCli
 		return
 	}
 	cfg.ActiveBackends[pprofBackend] = struct{}{}
+	utils.ReloadRequired("pprof", pprofBackend, "")
 	reload = true
 	return
 }
@@ -50,11 +50,7 @@ func (p ProxyProtocol) Update(k store.K8s, cfg *config.ControllerCfg, api api.HA
 	}
 	// Configure Annotation
 	logger.Trace("Configuring ProxyProtcol annotation")
-	frontends := []string{cfg.FrontHTTP, cfg.FrontHTTPS}
-	if cfg.SSLPassthrough {
-		frontends = []string{cfg.FrontHTTP, cfg.FrontSSL}
-	}
-	for _, frontend := range frontends {
+	for _, frontend := range cfg.ClientFrontends() {
 		err = cfg.HAProxyRules.AddRule(rules.ReqProxyProtocol{SrcIPsMap: mapName}, false, frontend)
 		if err != nil {
 			return
@@ -0,0 +1,79 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
+	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// RequestLimits denies, in every HTTP frontend, the requests exceeding the URI and headers
+// limits of the ConfigMap or whose decoded URL contains control characters.
+type RequestLimits struct{}
+
+// requestLimits are the ConfigMap annotations limiting requests with the
+// condition denying the requests over the limit and the deny status.
+var requestLimits = []struct {
+	annotation string
+	condTest   string
+	status     int64
+}{
+	{"max-uri-length", "{ url,length gt %d }", 414},
+	{"max-header-count", "{ req.hdr_cnt() gt %d }", 431},
+	{"max-header-size", "{ req.hdrs,length gt %d }", 431},
+}
+
+func (h RequestLimits) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyClient) (reload bool, err error) {
+	var denyRules []rules.ReqDenyAnomaly
+	for _, limit := range requestLimits {
+		annValue := annotations.GetValue(limit.annotation, k.ConfigMaps.Main.Annotations)
+		if annValue == "" {
+			continue
+		}
+		value, errParse := strconv.ParseInt(annValue, 10, 64)
+		if errParse != nil || value < 1 {
+			logger.Errorf("ConfigMap: incorrect value '%s' in %s annotation", annValue, limit.annotation)
+			continue
+		}
+		denyRules = append(denyRules, rules.ReqDenyAnomaly{
+			CondTest:   fmt.Sprintf(limit.condTest, value),
+			DenyStatus: limit.status,
+		})
+	}
+	if annValue := annotations.GetValue("deny-invalid-chars", k.ConfigMaps.Main.Annotations); annValue != "" {
+		enabled, errBool := utils.GetBoolValue(annValue, "deny-invalid-chars")
+		logger.Error(errBool)
+		if enabled {
+			// null bytes and line feeds hidden in percent-encoded URLs
+			denyRules = append(denyRules, rules.ReqDenyAnomaly{
+				CondTest:   "{ url,url_dec -m reg [[:cntrl:]] }",
+				DenyStatus: 400,
+			})
+		}
+	}
+	for _, rule := range denyRules {
+		for _, frontend := range []string{cfg.FrontHTTP, cfg.FrontHTTPS} {
+			logger.Error(cfg.HAProxyRules.AddRule(rule, false, frontend))
+		}
+	}
+	return false, nil
+}
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/haproxytech/client-native/v2/models"
 	config "github.com/haproxytech/kubernetes-ingress/controller/configuration"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
 	"github.com/haproxytech/kubernetes-ingress/controller/utils"
@@ -25,6 +27,14 @@ type tcpSvcParser struct {
 	service    *store.Service
 	port       int64
 	sslOffload bool
+	// sslSecret is the "namespace/name" of the secret of the offloaded certificate,
+	// certificates of the frontend certificate directory are used when not set
+	sslSecret     string
+	acceptProxy   bool
+	clientTimeout *int64
+	maxconn       *int64
+	// annotations are the options applied to the backend of the service
+	annotations map[string]string
 }
 
 func (t TCPServices) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAProxyClient) (reload bool, err error) {
@@ -34,23 +44,42 @@ func (t TCPServices) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAPr
 	reload = t.clearFrontends(api, k)
 	var p tcpSvcParser
 	for port, tcpSvcAnn := range k.ConfigMaps.TCPServices.Annotations {
+		if _, errPort := strconv.Atoi(port); errPort != nil {
+			// SNI services are routed by the HTTPS frontend, see SNIService
+			continue
+		}
 		frontendName := fmt.Sprintf("tcp-%s", port)
 		p, err = t.parseTCPService(k, tcpSvcAnn)
 		if err != nil {
 			logger.Error(err)
 			continue
 		}
+		var certPath string
+		if p.sslOffload {
+			certPath = t.CertDir
+			if p.sslSecret != "" {
+				// certificates of the crt-list directory are not loaded by the https frontend
+				certPath, err = cfg.Certificates.HandleTLSSecret(k, haproxy.SecretCtx{
+					SecretPath: p.sslSecret,
+					SecretType: haproxy.FT_CRTLIST_CERT,
+				})
+				if err != nil {
+					logger.Errorf("tcp-services: port %s: secret '%s': %s", port, p.sslSecret, err)
+					continue
+				}
+			}
+		}
 		frontend, errGet := api.FrontendGet(frontendName)
 		// Create Frontend
 		if errGet != nil {
-			frontend, reload, err = t.createTCPFrontend(api, frontendName, port, p.sslOffload)
+			frontend, reload, err = t.createTCPFrontend(api, frontendName, port, certPath)
 			if err != nil {
 				logger.Error(err)
 				continue
 			}
 		}
 		// Update  Frontend
-		reload, err = t.updateTCPFrontend(api, frontend, p)
+		reload, err = t.updateTCPFrontend(api, frontend, p, certPath)
 		if err != nil {
 			logger.Errorf("TCP frontend '%s': update failed: %s", frontendName, err)
 		}
@@ -61,7 +90,7 @@ func (t TCPServices) Update(k store.K8s, cfg *config.ControllerCfg, api api.HAPr
 func (t TCPServices) parseTCPService(store store.K8s, input string) (p tcpSvcParser, err error) {
 	// parts[0]: Service Name
 	// parts[1]: Service Port
-	// parts[2]: SSL option
+	// parts[2:]: options
 	parts := strings.Split(input, ":")
 	if len(parts) < 2 {
 		err = fmt.Errorf("incorrect format '%s', 'ServiceName:ServicePort' is required", input)
@@ -69,9 +98,11 @@ func (t TCPServices) parseTCPService(store store.K8s, input string) (p tcpSvcPar
 	}
 	svcName := strings.Split(parts[0], "/")
 	svcPort := parts[1]
-	if len(parts) > 2 {
-		if parts[2] == "ssl" {
-			p.sslOffload = true
+	p.annotations = make(map[string]string)
+	for _, option := range parts[2:] {
+		if err = p.parseOption(option); err != nil {
+			err = fmt.Errorf("tcp-services: '%s': %w", input, err)
+			return
 		}
 	}
 	if len(svcName) != 2 {
@@ -96,6 +127,70 @@ func (t TCPServices) parseTCPService(store store.K8s, input string) (p tcpSvcPar
 	return p, err
 }
 
+// SNIService returns the Ingress and IngressPath of a tcp-services entry whose key is an SNI hostname
+// rather than a port. The service is reached through the ssl-passthrough of the HTTPS frontend,
+// so only backend options are allowed.
+func SNIService(k store.K8s, host, input string) (ingress *store.Ingress, path *store.IngressPath, err error) {
+	p, err := TCPServices{}.parseTCPService(k, input)
+	if err != nil {
+		return
+	}
+	if p.sslOffload || p.acceptProxy || p.clientTimeout != nil || p.maxconn != nil {
+		err = fmt.Errorf("tcp-services: SNI '%s': only send-proxy, timeout-server and timeout-tunnel options are available", host)
+		return
+	}
+	p.annotations["ssl-passthrough"] = "true"
+	ingress = &store.Ingress{
+		Namespace:   p.service.Namespace,
+		Name:        k.ConfigMaps.TCPServices.Name,
+		Annotations: p.annotations,
+	}
+	path = &store.IngressPath{
+		SvcName:    p.service.Name,
+		SvcPortInt: p.port,
+	}
+	return
+}
+
+// parseOption parses an option of the service, "name" or "name=value":
+// ssl[=namespace/secret], accept-proxy, send-proxy[=version], maxconn, timeout-client, timeout-server and timeout-tunnel.
+func (p *tcpSvcParser) parseOption(option string) (err error) {
+	name, value := option, ""
+	if i := strings.IndexByte(option, '='); i != -1 {
+		name, value = option[:i], option[i+1:]
+	}
+	switch name {
+	case "ssl":
+		p.sslOffload = true
+		if value != "" && len(strings.Split(value, "/")) != 2 {
+			return fmt.Errorf("incorrect secret '%s', 'SecretNS/SecretName' is required", value)
+		}
+		p.sslSecret = value
+	case "accept-proxy":
+		p.acceptProxy = true
+	case "send-proxy":
+		if value == "" {
+			value = "proxy"
+		}
+		p.annotations["send-proxy-protocol"] = value
+	case "maxconn":
+		var maxconn int64
+		if maxconn, err = strconv.ParseInt(value, 10, 64); err != nil || maxconn < 1 {
+			return fmt.Errorf("incorrect maxconn '%s'", value)
+		}
+		p.maxconn = &maxconn
+	case "timeout-client":
+		p.clientTimeout, err = utils.ParseTime(value)
+	case "timeout-server", "timeout-tunnel":
+		if _, err = utils.ParseTime(value); err == nil {
+			p.annotations[name] = value
+		}
+	default:
+		err = fmt.Errorf("unknown option '%s'", name)
+	}
+	return err
+}
+
 func (t TCPServices) clearFrontends(api api.HAProxyClient, k store.K8s) (cleared bool) {
 	frontends, err := api.FrontendsGet()
 	if err != nil {
@@ -112,13 +207,14 @@ func (t TCPServices) clearFrontends(api api.HAProxyClient, k store.K8s) (cleared
 			} else {
 				cleared = true
 				logger.Debugf("TCP frontend '%s' deleted, reload required", ft.Name)
+				utils.ReloadRequired("tcp_frontend_deleted", ft.Name, "")
 			}
 		}
 	}
 	return
 }
 
-func (t TCPServices) createTCPFrontend(api api.HAProxyClient, frontendName, bindPort string, sslOffload bool) (frontend models.Frontend, reload bool, err error) {
+func (t TCPServices) createTCPFrontend(api api.HAProxyClient, frontendName, bindPort string, certPath string) (frontend models.Frontend, reload bool, err error) {
 	// Create Frontend
 	frontend = models.Frontend{
 		Name:   frontendName,
@@ -141,30 +237,32 @@ func (t TCPServices) createTCPFrontend(api api.HAProxyClient, frontendName, bind
 			V4v6:    true,
 		}))
 	}
-	if sslOffload {
-		errors.Add(api.FrontendEnableSSLOffload(frontend.Name, t.CertDir, ""))
+	if certPath != "" {
+		errors.Add(api.FrontendEnableSSLOffload(frontend.Name, certPath, ""))
 	}
 	if errors.Result() != nil {
 		err = fmt.Errorf("error configuring tcp frontend: %w", err)
 		return frontend, false, err
 	}
 	logger.Debugf("TCP frontend '%s' created, reload required", frontendName)
+	utils.ReloadRequired("tcp_frontend_created", frontendName, "")
 	return frontend, true, nil
 }
 
-func (t TCPServices) updateTCPFrontend(api api.HAProxyClient, frontend models.Frontend, p tcpSvcParser) (reload bool, err error) {
+func (t TCPServices) updateTCPFrontend(api api.HAProxyClient, frontend models.Frontend, p tcpSvcParser, certPath string) (reload bool, err error) {
 	binds, err := api.FrontendBindsGet(frontend.Name)
 	if err != nil {
 		err = fmt.Errorf("failed to get bind lines: %w", err)
 		return
 	}
-	if !binds[0].Ssl && p.sslOffload {
-		err = api.FrontendEnableSSLOffload(frontend.Name, t.CertDir, "")
+	if p.sslOffload && (!binds[0].Ssl || binds[0].SslCertificate != certPath) {
+		err = api.FrontendEnableSSLOffload(frontend.Name, certPath, "")
 		if err != nil {
 			err = fmt.Errorf("failed to enable SSL offload: %w", err)
 			return
 		}
 		logger.Debugf("TCP frontend '%s': ssl offload enabled, reload required", frontend.Name)
+		utils.ReloadRequired("tcp_frontend_updated", frontend.Name, "ssl offload enabled")
 		reload = true
 	}
 	if binds[0].Ssl && !p.sslOffload {
@@ -174,17 +272,43 @@ func (t TCPServices) updateTCPFrontend(api api.HAProxyClient, frontend models.Fr
 			return
 		}
 		logger.Debugf("TCP frontend '%s': ssl offload disabled, reload required", frontend.Name)
+		utils.ReloadRequired("tcp_frontend_updated", frontend.Name, "ssl offload disabled")
+		reload = true
+	}
+	for _, bind := range binds {
+		if bind.AcceptProxy == p.acceptProxy {
+			continue
+		}
+		bind.AcceptProxy = p.acceptProxy
+		if err = api.FrontendBindEdit(frontend.Name, *bind); err != nil {
+			err = fmt.Errorf("failed to update bind '%s': %w", bind.Name, err)
+			return
+		}
+		logger.Debugf("TCP frontend '%s': accept-proxy updated, reload required", frontend.Name)
+		utils.ReloadRequired("tcp_frontend_updated", frontend.Name, "accept-proxy")
+		reload = true
+	}
+	if !reflect.DeepEqual(frontend.ClientTimeout, p.clientTimeout) || !reflect.DeepEqual(frontend.Maxconn, p.maxconn) {
+		frontend.ClientTimeout = p.clientTimeout
+		frontend.Maxconn = p.maxconn
+		if err = api.FrontendEdit(frontend); err != nil {
+			err = fmt.Errorf("failed to update options: %w", err)
+			return
+		}
+		logger.Debugf("TCP frontend '%s': options updated, reload required", frontend.Name)
+		utils.ReloadRequired("tcp_frontend_updated", frontend.Name, "timeout-client, maxconn")
 		reload = true
 	}
 	if p.service.Status == store.DELETED {
 		frontend.DefaultBackend = ""
 		err = api.FrontendEdit(frontend)
+		utils.ReloadRequired("tcp_frontend_updated", frontend.Name, "service deleted")
 		reload = true
 		return
 	}
 	ingress := &store.Ingress{
 		Namespace:   p.service.Namespace,
-		Annotations: make(map[string]string),
+		Annotations: p.annotations,
 		DefaultBackend: &store.IngressPath{
 			SvcName:    p.service.Name,
 			SvcPortInt: p.port,
@@ -0,0 +1,255 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HAProxy configuration handoff:
+// after each sync the generated configuration, maps, patterns, error files and certificates
+// are saved in a snapshot of "handoff-dir" so that a replacement controller starts HAProxy
+// with them and skips its first reload when it generates the same configuration.
+
+// handoffFormat is increased when the files of the snapshot change
+const handoffFormat = 2
+
+type handoffMarker struct {
+	Format     int    `json:"format"`
+	HAProxy    string `json:"haproxy"`
+	Controller string `json:"controller"`
+	Hash       string `json:"hash"`
+}
+
+// handoffFiles returns the files of the snapshot with their location in the controller configuration.
+// Directories are copied without their subdirectories.
+func (c *HAProxyController) handoffFiles() map[string]string {
+	env := c.Cfg.Env
+	return map[string]string{
+		"haproxy.cfg":             env.MainCFGFile,
+		"maps":                    env.MapDir,
+		"patterns":                env.PatternDir,
+		"errors":                  env.ErrFileDir,
+		"certs/frontend":          env.FrontendCertDir,
+		"certs/frontend-crt-list": env.FrontendCrtListDir,
+		"certs/frontend.crt-list": env.FrontendCrtList,
+		"certs/backend":           env.BackendCertDir,
+		"certs/ca":                env.CaCertDir,
+	}
+}
+
+// handoffAdopt restores the snapshot of a previous controller, if any, before HAProxy is started.
+func (c *HAProxyController) handoffAdopt() {
+	snapshot := filepath.Join(c.OSArgs.HandoffDir, "snapshot")
+	data, err := ioutil.ReadFile(filepath.Join(snapshot, "version.json"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Error(err)
+		}
+		return
+	}
+	var marker handoffMarker
+	if err = json.Unmarshal(data, &marker); err != nil {
+		logger.Errorf("handoff: invalid version marker: %s", err)
+		return
+	}
+	if marker.Format != handoffFormat || marker.HAProxy != c.haproxyVersion() {
+		logger.Infof("handoff: snapshot of controller '%s' running '%s' not compatible, ignored", marker.Controller, marker.HAProxy)
+		return
+	}
+	mainCfg, err := ioutil.ReadFile(c.Cfg.Env.MainCFGFile)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	for name, dst := range c.handoffFiles() {
+		if err = copyPath(filepath.Join(snapshot, name), dst); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		//nolint:gosec //checks on HAProxyBinary should be done in configuration module.
+		out, errCheck := exec.Command(c.Cfg.Env.HAProxyBinary, "-c", "-f", c.Cfg.Env.MainCFGFile).CombinedOutput()
+		if errCheck != nil {
+			err = fmt.Errorf("%w: %s", errCheck, out)
+		}
+	}
+	if err != nil {
+		logger.Errorf("handoff: unable to adopt snapshot of controller '%s': %s", marker.Controller, err)
+		logger.Error(ioutil.WriteFile(c.Cfg.Env.MainCFGFile, mainCfg, 0644)) //nolint:gosec
+		return
+	}
+	c.handoffHash = marker.Hash
+	c.handoffSaved = marker.Hash
+	logger.Infof("handoff: adopted configuration of controller '%s'", marker.Controller)
+}
+
+// handoffSkipReload returns true if the configuration of the first sync is identical to the adopted one
+func (c *HAProxyController) handoffSkipReload() bool {
+	if c.handoffHash == "" {
+		return false
+	}
+	adopted := c.handoffHash
+	c.handoffHash = ""
+	hash, err := c.handoffHashFiles()
+	if err != nil {
+		logger.Error(err)
+		return false
+	}
+	return hash == adopted
+}
+
+// handoffSave updates the snapshot if the configuration changed since the last save
+func (c *HAProxyController) handoffSave() {
+	hash, err := c.handoffHashFiles()
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	if hash == c.handoffSaved {
+		return
+	}
+	tmp := filepath.Join(c.OSArgs.HandoffDir, "snapshot.tmp")
+	snapshot := filepath.Join(c.OSArgs.HandoffDir, "snapshot")
+	old := filepath.Join(c.OSArgs.HandoffDir, "snapshot.old")
+	logger.Error(os.RemoveAll(tmp))
+	for name, src := range c.handoffFiles() {
+		if err = copyPath(src, filepath.Join(tmp, name)); err != nil {
+			logger.Errorf("handoff: unable to save snapshot: %s", err)
+			return
+		}
+	}
+	marker, _ := json.Marshal(handoffMarker{
+		Format:     handoffFormat,
+		HAProxy:    c.haproxyVersion(),
+		Controller: c.Version,
+		Hash:       hash,
+	})
+	if err = ioutil.WriteFile(filepath.Join(tmp, "version.json"), marker, 0644); err != nil { //nolint:gosec
+		logger.Errorf("handoff: unable to save snapshot: %s", err)
+		return
+	}
+	logger.Error(os.RemoveAll(old))
+	if err = os.Rename(snapshot, old); err != nil && !os.IsNotExist(err) {
+		logger.Errorf("handoff: unable to save snapshot: %s", err)
+		return
+	}
+	if err = os.Rename(tmp, snapshot); err != nil {
+		logger.Errorf("handoff: unable to save snapshot: %s", err)
+		return
+	}
+	logger.Error(os.RemoveAll(old))
+	c.handoffSaved = hash
+	logger.Debug("handoff: configuration snapshot saved")
+}
+
+// handoffHashFiles hashes the files of the snapshot,
+// lines added by the configuration library to the main configuration file are ignored.
+func (c *HAProxyController) handoffHashFiles() (string, error) {
+	var files []string
+	for name, src := range c.handoffFiles() {
+		info, err := os.Stat(src)
+		if err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			files = append(files, name+"\x00"+src)
+			continue
+		}
+		entries, err := ioutil.ReadDir(src)
+		if err != nil {
+			return "", err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files = append(files, name+"/"+entry.Name()+"\x00"+filepath.Join(src, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+	h := sha256.New()
+	for _, file := range files {
+		parts := strings.SplitN(file, "\x00", 2)
+		data, err := ioutil.ReadFile(parts[1])
+		if err != nil {
+			return "", err
+		}
+		if parts[0] == "haproxy.cfg" {
+			var lines [][]byte
+			for _, line := range bytes.Split(data, []byte("\n")) {
+				if !bytes.HasPrefix(line, []byte("# _")) {
+					lines = append(lines, line)
+				}
+			}
+			data = bytes.Join(lines, []byte("\n"))
+		}
+		fmt.Fprintf(h, "%s %d\n", parts[0], len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *HAProxyController) haproxyVersion() string {
+	//nolint:gosec //checks on HAProxyBinary should be done in configuration module.
+	out, err := exec.Command(c.Cfg.Env.HAProxyBinary, "-v").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.Split(string(out), "\n")[0]
+}
+
+// copyPath copies the src file, or the files of the src directory, to dst
+func copyPath(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		if err = os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(dst, data, info.Mode())
+	}
+	if err = os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	entries, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err = copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
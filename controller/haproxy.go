@@ -43,4 +43,5 @@ func (c *HAProxyController) haproxyStartup() {
 	}
 	logger.Printf("Starting HAProxy with %s", c.Cfg.Env.MainCFGFile)
 	logger.Panic(c.haproxyService("start"))
+	c.haproxyLoaded()
 }
@@ -1,10 +1,16 @@
 package api
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	clientnative "github.com/haproxytech/client-native/v2"
 	"github.com/haproxytech/client-native/v2/configuration"
 	"github.com/haproxytech/client-native/v2/models"
 	"github.com/haproxytech/client-native/v2/runtime"
+	parser "github.com/haproxytech/config-parser/v4"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
 )
@@ -13,6 +19,14 @@ type HAProxyClient interface {
 	APIStartTransaction() error
 	APICommitTransaction() error
 	APIDisposeTransaction()
+	APIStaleTransactions() []string
+	APIDeleteTransaction(id string) error
+	APIRetryDue() bool
+	APITransactionStats() TransactionStats
+	APISetValidator(validate func(config string) (string, error))
+	APIRevalidate()
+	MapFileWrite(path, content string)
+	MapFileRemove(path string)
 	BackendsGet() (models.Backends, error)
 	BackendGet(backendName string) (*models.Backend, error)
 	BackendCreate(backend models.Backend) error
@@ -30,6 +44,7 @@ type HAProxyClient interface {
 	DefaultsGetConfiguration() (*models.Defaults, error)
 	DefaultsPushConfiguration(models.Defaults) error
 	ExecuteRaw(command string) (result []string, err error)
+	ExecuteWorker(pid int, command string) (string, error)
 	FrontendCfgSnippetSet(frontendName string, value []string) error
 	FrontendCreate(frontend models.Frontend) error
 	FrontendDelete(frontendName string) error
@@ -51,29 +66,57 @@ type HAProxyClient interface {
 	GlobalGetConfiguration() (*models.Global, error)
 	GlobalPushConfiguration(models.Global) error
 	GlobalCfgSnippet(snippet []string) error
+	PeerEntryEdit(peerSection string, entry models.PeerEntry) error
+	RingsGet() (map[string][]string, error)
+	RingsReplace(rings map[string][]string) error
 	GetMap(mapFile string) (*models.Map, error)
 	SetMapContent(mapFile string, payload string) error
+	SetACLContent(aclFile string, entries []string) error
+	SetCertContent(certFile string, payload []byte) error
 	SetServerAddr(backendName string, serverName string, ip string, port int) error
 	SetServerState(backendName string, serverName string, state string) error
+	SetServerWeight(backendName string, serverName string, weight string) error
+	BackendServersStateGet(backendName string) (models.RuntimeServers, error)
+	InfoGet() (models.ProcessInfos, error)
 	ServerGet(serverName, backendNa string) (models.Server, error)
 	SetAuxCfgFile(auxCfgFile string)
 	SyncBackendSrvs(oldEndpoints, newEndpoints *store.PortEndpoints) error
+	StatsGet() (models.NativeStats, error)
+	TablesGet() (models.StickTables, error)
+	TableEntriesGet(table string, filter []string, key string) (models.StickTableEntries, error)
 	UserListDeleteAll() error
 	UserListExistsByGroup(group string) (bool, error)
 	UserListCreateByGroup(group string, userPasswordMap map[string][]byte) error
+	WorkersGet() ([]Worker, error)
 }
 
 type clientNative struct {
 	nativeAPI                   clientnative.HAProxyClient
 	activeTransaction           string
 	activeTransactionHasChanges bool
+	stats                       TransactionStats
+	statsMu                     sync.Mutex
+	srvQueue                    srvQueue
+	mapQueue                    mapQueue
+	// masterSocket is set when runtime commands go through the master CLI
+	masterSocket string
+	// validate checks the configuration of a transaction before it is committed
+	validate func(config string) (string, error)
+	// revalidate validates the next transaction even when it has no changes
+	revalidate bool
 }
 
-func Init(transactionDir, configFile, programPath, runtimeSocket string) (client HAProxyClient, err error) {
+// Init returns the HAProxy client, runtime commands are sent to the current worker via the master CLI
+// when masterSocket is set, so that they are not lost on a stats socket rebound by a reload.
+func Init(transactionDir, configFile, programPath, runtimeSocket, masterSocket string) (client HAProxyClient, err error) {
 	runtimeClient := runtime.Client{}
-	err = runtimeClient.InitWithSockets(map[int]string{
-		0: runtimeSocket,
-	})
+	if masterSocket != "" {
+		err = runtimeClient.InitWithMasterSocket(masterSocket, 1)
+	} else {
+		err = runtimeClient.InitWithSockets(map[int]string{
+			0: runtimeSocket,
+		})
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +143,7 @@ func Init(transactionDir, configFile, programPath, runtimeSocket string) (client
 			Configuration: &confClient,
 			Runtime:       &runtimeClient,
 		},
+		masterSocket: masterSocket,
 	}
 	return &cn, nil
 }
@@ -116,23 +160,137 @@ func (c *clientNative) APIStartTransaction() error {
 	}
 	c.activeTransaction = transaction.ID
 	c.activeTransactionHasChanges = false
+	c.srvQueue.reset()
 	return nil
 }
 
 func (c *clientNative) APICommitTransaction() error {
-	if !c.activeTransactionHasChanges {
-		if err := c.nativeAPI.Configuration.DeleteTransaction(c.activeTransaction); err != nil {
+	revalidate := c.revalidate
+	c.revalidate = false
+	// map files are loaded by the configuration, they are written first even without configuration changes
+	err := c.flushMapQueue()
+	if err == nil && !c.activeTransactionHasChanges && !revalidate {
+		if err = c.nativeAPI.Configuration.DeleteTransaction(c.activeTransaction); err != nil {
 			return err
 		}
+		// nothing left to replay
+		c.statsMu.Lock()
+		c.stats.ConsecutiveFailures = 0
+		c.statsMu.Unlock()
+		return nil
+	}
+	if err == nil {
+		err = c.flushSrvQueue()
+	}
+	if err == nil {
+		err = c.validateTransaction()
+	} else {
+		logger.Error(c.nativeAPI.Configuration.DeleteTransaction(c.activeTransaction))
+	}
+	if err == nil {
+		_, err = c.nativeAPI.Configuration.CommitTransaction(c.activeTransaction)
+	}
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	if err != nil {
+		c.stats.Failed++
+		c.stats.ConsecutiveFailures++
+		c.stats.LastError = err.Error()
+		c.stats.LastFailure = time.Now()
+		c.stats.NextRetry = c.stats.LastFailure.Add(TransactionBackoff.Delay(c.stats.ConsecutiveFailures))
+		return err
+	}
+	c.stats.Committed++
+	c.stats.ConsecutiveFailures = 0
+	c.stats.NextRetry = time.Time{}
+	return nil
+}
+
+// APISetValidator sets the function checking the configuration of transactions before they are committed,
+// a transaction whose configuration is refused fails as if its commit failed.
+// The validator returns the configuration to commit, which replaces the one of the transaction when it differs.
+func (c *clientNative) APISetValidator(validate func(config string) (string, error)) {
+	c.validate = validate
+}
+
+// APIRevalidate makes the next transaction go through the validator even without changes,
+// so that the validator can replace its configuration.
+func (c *clientNative) APIRevalidate() {
+	c.revalidate = true
+}
+
+func (c *clientNative) validateTransaction() error {
+	if c.validate == nil {
 		return nil
 	}
-	_, err := c.nativeAPI.Configuration.CommitTransaction(c.activeTransaction)
+	version, config, err := c.nativeAPI.Configuration.GetRawConfiguration(c.activeTransaction, 0)
+	if err == nil {
+		var validated string
+		validated, err = c.validate(config)
+		if err == nil && validated != config {
+			err = c.replaceTransaction(version, validated)
+		}
+	}
+	if err != nil {
+		logger.Error(c.nativeAPI.Configuration.DeleteTransaction(c.activeTransaction))
+	}
 	return err
 }
 
+// replaceTransaction replaces the configuration of the active transaction
+func (c *clientNative) replaceTransaction(version int64, config string) error {
+	p, err := c.nativeAPI.Configuration.GetParser(c.activeTransaction)
+	if err != nil {
+		return err
+	}
+	if err = p.Process(strings.NewReader(fmt.Sprintf("# _version=%d\n%s", version, config))); err != nil {
+		return err
+	}
+	return c.saveTransaction(p)
+}
+
+// saveTransaction saves to the active transaction file the changes made directly to its parser
+func (c *clientNative) saveTransaction(p parser.Parser) error {
+	file, err := c.nativeAPI.Configuration.GetTransactionFile(c.activeTransaction)
+	if err != nil {
+		return err
+	}
+	return p.Save(file)
+}
+
+// APIRetryDue returns true when the last transaction failed
+// and the backoff delay before replaying it elapsed.
+func (c *clientNative) APIRetryDue() bool {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	return c.stats.ConsecutiveFailures > 0 && time.Now().After(c.stats.NextRetry)
+}
+
+func (c *clientNative) APITransactionStats() TransactionStats {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	return c.stats
+}
+
 func (c *clientNative) APIDisposeTransaction() {
 	c.activeTransaction = ""
 	c.activeTransactionHasChanges = false
+	c.srvQueue.reset()
+}
+
+// APIStaleTransactions returns the in progress transactions
+// which are not the active one, e.g. left behind by a failed sync.
+func (c *clientNative) APIStaleTransactions() (ids []string) {
+	for _, t := range c.nativeAPI.Configuration.GetParserTransactions() {
+		if t.ID != c.activeTransaction && t.Status == models.TransactionStatusInProgress {
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids
+}
+
+func (c *clientNative) APIDeleteTransaction(id string) error {
+	return c.nativeAPI.Configuration.DeleteTransaction(id)
 }
 
 func (c *clientNative) SetAuxCfgFile(auxCfgFile string) {
@@ -30,6 +30,7 @@ func (c *clientNative) BackendEdit(backend models.Backend) error {
 
 func (c *clientNative) BackendDelete(backendName string) error {
 	c.activeTransactionHasChanges = true
+	c.srvQueue.dropBackend(backendName)
 	return c.nativeAPI.Configuration.DeleteBackend(backendName, c.activeTransaction, 0)
 }
 
@@ -60,6 +61,13 @@ func (c *clientNative) BackendServerDeleteAll(backendName string) bool {
 		c.activeTransactionHasChanges = true
 		_ = c.BackendServerDelete(backendName, srv.Name)
 	}
+	if b, ok := c.srvQueue.ops[backendName]; ok {
+		for name, op := range b.ops {
+			if op.kind == srvCreate {
+				c.srvQueue.remove(backendName, name)
+			}
+		}
+	}
 	return c.activeTransactionHasChanges
 }
 
@@ -72,19 +80,56 @@ func (c *clientNative) BackendRuleDeleteAll(backend string) {
 	}
 }
 
+// BackendServerCreate queues the creation of a backend server, see srvQueue
 func (c *clientNative) BackendServerCreate(backendName string, data models.Server) error {
+	op := c.srvQueue.get(backendName, data.Name)
+	switch {
+	case op != nil && op.kind == srvDelete:
+		op.kind = srvEdit
+		op.server = data
+	case op != nil, c.srvCommitted(backendName, data.Name):
+		return errSrvExist(backendName, data.Name)
+	default:
+		c.srvQueue.set(backendName, &srvOp{kind: srvCreate, server: data})
+	}
 	c.activeTransactionHasChanges = true
-	return c.nativeAPI.Configuration.CreateServer(backendName, &data, c.activeTransaction, 0)
+	return nil
 }
 
+// BackendServerEdit queues the edition of a backend server, see srvQueue
 func (c *clientNative) BackendServerEdit(backendName string, data models.Server) error {
+	op := c.srvQueue.get(backendName, data.Name)
+	switch {
+	case op != nil && op.kind == srvDelete:
+		return errSrvNotExist(backendName, data.Name)
+	case op != nil:
+		op.server = data
+	case !c.srvCommitted(backendName, data.Name):
+		return errSrvNotExist(backendName, data.Name)
+	default:
+		c.srvQueue.set(backendName, &srvOp{kind: srvEdit, server: data})
+	}
 	c.activeTransactionHasChanges = true
-	return c.nativeAPI.Configuration.EditServer(data.Name, backendName, &data, c.activeTransaction, 0)
+	return nil
 }
 
+// BackendServerDelete queues the deletion of a backend server, see srvQueue
 func (c *clientNative) BackendServerDelete(backendName string, serverName string) error {
+	op := c.srvQueue.get(backendName, serverName)
+	switch {
+	case op != nil && op.kind == srvDelete:
+		return errSrvNotExist(backendName, serverName)
+	case op != nil && op.kind == srvCreate:
+		c.srvQueue.remove(backendName, serverName)
+	case op != nil:
+		op.kind = srvDelete
+	case !c.srvCommitted(backendName, serverName):
+		return errSrvNotExist(backendName, serverName)
+	default:
+		c.srvQueue.set(backendName, &srvOp{kind: srvDelete, server: models.Server{Name: serverName}})
+	}
 	c.activeTransactionHasChanges = true
-	return c.nativeAPI.Configuration.DeleteServer(serverName, backendName, c.activeTransaction, 0)
+	return nil
 }
 
 func (c *clientNative) BackendSwitchingRuleCreate(frontend string, rule models.BackendSwitchingRule) error {
@@ -101,6 +146,12 @@ func (c *clientNative) BackendSwitchingRuleDeleteAll(frontend string) {
 }
 
 func (c *clientNative) ServerGet(serverName, backendName string) (models.Server, error) {
+	if op := c.srvQueue.get(backendName, serverName); op != nil {
+		if op.kind == srvDelete {
+			return models.Server{}, errSrvNotExist(backendName, serverName)
+		}
+		return op.server, nil
+	}
 	_, server, err := c.nativeAPI.Configuration.GetServer(serverName, backendName, c.activeTransaction)
 	if err != nil {
 		return models.Server{}, err
@@ -81,6 +81,8 @@ func (c *clientNative) FrontendDisableSSLOffload(frontendName string) (err error
 		bind.Verify = ""
 		bind.SslCertificate = ""
 		bind.Alpn = ""
+		bind.StrictSni = false
+		bind.CrtList = ""
 		err = c.FrontendBindEdit(frontendName, *bind)
 	}
 	if err != nil {
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haproxytech/client-native/v2/misc"
+	"github.com/haproxytech/client-native/v2/models"
+)
+
+const masterTimeout = 5 * time.Second
+
+// ErrStaleWorker is returned when the worker targeted via the master CLI is gone,
+// as happens to the workers of the previous configuration after a reload.
+var ErrStaleWorker = errors.New("worker not running")
+
+// Worker is an HAProxy worker process listed by the master CLI
+type Worker struct {
+	PID     int `json:"pid"`
+	Reloads int `json:"reloads"`
+	// Current is false for the workers of previous configurations, leaving after a reload
+	Current bool `json:"current"`
+}
+
+// WorkersGet returns the HAProxy workers, current ones first
+func (c *clientNative) WorkersGet() ([]Worker, error) {
+	if c.masterSocket == "" {
+		return nil, fmt.Errorf("master socket not configured")
+	}
+	out, err := c.masterCommand("show proc")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorkers(out), nil
+}
+
+// ExecuteWorker runs a runtime command on the worker with the given PID via the master CLI
+func (c *clientNative) ExecuteWorker(pid int, command string) (string, error) {
+	if c.masterSocket == "" {
+		return "", fmt.Errorf("master socket not configured")
+	}
+	out, err := c.masterCommand(fmt.Sprintf("@!%d %s", pid, command))
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(out, "Can't find the target") {
+		return "", fmt.Errorf("worker %d: %w", pid, ErrStaleWorker)
+	}
+	return out, nil
+}
+
+// masterCommand runs a command on the master CLI, which closes the connection once answered
+func (c *clientNative) masterCommand(command string) (string, error) {
+	conn, err := net.DialTimeout("unix", c.masterSocket, masterTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(masterTimeout)); err != nil {
+		return "", err
+	}
+	if _, err = conn.Write([]byte(command + "\n")); err != nil {
+		return "", err
+	}
+	out, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// serversStateWorkers returns the servers state of a backend aggregated across the current workers,
+// so that the state of the workers leaving after a reload is not mixed with the current one.
+func (c *clientNative) serversStateWorkers(backendName string) (servers models.RuntimeServers, err error) {
+	err = retryRuntime(func() error {
+		workers, errWorkers := c.WorkersGet()
+		if errWorkers != nil {
+			return errWorkers
+		}
+		var states []models.RuntimeServers
+		for _, worker := range workers {
+			if !worker.Current {
+				continue
+			}
+			out, errWorker := c.ExecuteWorker(worker.PID, "show servers state "+backendName)
+			if errWorker != nil {
+				return errWorker
+			}
+			state, errWorker := parseServersState(out)
+			if errWorker != nil {
+				return fmt.Errorf("worker %d: %w", worker.PID, errWorker)
+			}
+			states = append(states, state)
+		}
+		if len(states) == 0 {
+			return fmt.Errorf("no current worker: %w", ErrStaleWorker)
+		}
+		servers = mergeServersState(states)
+		return nil
+	})
+	return servers, err
+}
+
+// parseWorkers parses the output of "show proc"
+func parseWorkers(out string) (workers []Worker) {
+	var current []Worker
+	section := ""
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "#") {
+			section = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "worker" || (section != "workers" && section != "old workers") {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		reloads, _ := strconv.Atoi(fields[2])
+		worker := Worker{PID: pid, Reloads: reloads, Current: section == "workers"}
+		if worker.Current {
+			current = append(current, worker)
+		} else {
+			workers = append(workers, worker)
+		}
+	}
+	return append(current, workers...)
+}
+
+// parseServersState parses the output of "show servers state" in format version 1
+func parseServersState(out string) (models.RuntimeServers, error) {
+	lines := strings.Split(out, "\n")
+	if strings.TrimSpace(lines[0]) != "1" {
+		return nil, fmt.Errorf("unsupported servers state format '%s'", lines[0])
+	}
+	servers := models.RuntimeServers{}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 19 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		server := &models.RuntimeServer{
+			ID:      fields[2],
+			Name:    fields[3],
+			Address: fields[4],
+		}
+		if port, err := strconv.ParseInt(fields[18], 10, 64); err == nil {
+			server.Port = &port
+		}
+		server.AdminState, _ = misc.GetServerAdminState(fields[6])
+		switch fields[5] {
+		case "0":
+			server.OperationalState = "down"
+		case "1", "2":
+			server.OperationalState = "up"
+		case "3":
+			server.OperationalState = "stopping"
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+var (
+	adminStatePriority       = map[string]int{"ready": 0, "drain": 1, "maint": 2}
+	operationalStatePriority = map[string]int{"up": 0, "stopping": 1, "down": 2}
+)
+
+// mergeServersState merges the servers state of several workers,
+// a server is only reported ready and up when it is in all of them.
+func mergeServersState(states []models.RuntimeServers) models.RuntimeServers {
+	merged := models.RuntimeServers{}
+	byName := make(map[string]*models.RuntimeServer)
+	for _, state := range states {
+		for _, server := range state {
+			m, ok := byName[server.Name]
+			if !ok {
+				m = server
+				byName[server.Name] = m
+				merged = append(merged, m)
+				continue
+			}
+			if adminStatePriority[server.AdminState] > adminStatePriority[m.AdminState] {
+				m.AdminState = server.AdminState
+			}
+			if operationalStatePriority[server.OperationalState] > operationalStatePriority[m.OperationalState] {
+				m.OperationalState = server.OperationalState
+			}
+		}
+	}
+	return merged
+}
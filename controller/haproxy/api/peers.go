@@ -0,0 +1,16 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+)
+
+func (c *clientNative) PeerEntryEdit(peerSection string, entry models.PeerEntry) (err error) {
+	c.activeTransactionHasChanges = true
+	err = c.nativeAPI.Configuration.EditPeerEntry(entry.Name, peerSection, &entry, c.activeTransaction, 0)
+	if err != nil {
+		return fmt.Errorf("unable to update peer '%s' of '%s' peers section: %w", entry.Name, peerSection, err)
+	}
+	return
+}
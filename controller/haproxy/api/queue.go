@@ -0,0 +1,254 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/haproxytech/client-native/v2/configuration"
+	"github.com/haproxytech/client-native/v2/models"
+	parser "github.com/haproxytech/config-parser/v4"
+	parsererrors "github.com/haproxytech/config-parser/v4/errors"
+	"github.com/haproxytech/config-parser/v4/types"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+var logger = utils.GetLogger()
+
+type srvOpKind int
+
+const (
+	srvCreate srvOpKind = iota
+	srvEdit
+	srvDelete
+)
+
+type srvOp struct {
+	kind   srvOpKind
+	server models.Server
+}
+
+type backendOps struct {
+	servers []string // servers in queuing order
+	ops     map[string]*srvOp
+}
+
+// srvQueue holds the backend servers mutations of the active transaction.
+// Mutations of a same server are coalesced into a single operation, and the whole queue
+// is applied to the transaction parser right before its commit, which is saved once:
+// per backend, deletions and edits in place then creations in queuing order.
+// Mutations of deleted backends are dropped.
+type srvQueue struct {
+	backends []string // backends in queuing order
+	ops      map[string]*backendOps
+	// committed are the servers names of the backends in the transaction, read once per transaction
+	committed map[string]map[string]struct{}
+}
+
+func (q *srvQueue) get(backend, server string) *srvOp {
+	if b, ok := q.ops[backend]; ok {
+		return b.ops[server]
+	}
+	return nil
+}
+
+func (q *srvQueue) set(backend string, op *srvOp) {
+	if q.ops == nil {
+		q.ops = make(map[string]*backendOps)
+	}
+	b, ok := q.ops[backend]
+	if !ok {
+		b = &backendOps{ops: make(map[string]*srvOp)}
+		q.ops[backend] = b
+		q.backends = append(q.backends, backend)
+	}
+	if _, ok = b.ops[op.server.Name]; !ok {
+		b.servers = append(b.servers, op.server.Name)
+	}
+	b.ops[op.server.Name] = op
+}
+
+func (q *srvQueue) remove(backend, server string) {
+	if b, ok := q.ops[backend]; ok {
+		delete(b.ops, server)
+	}
+}
+
+func (q *srvQueue) dropBackend(backend string) {
+	delete(q.ops, backend)
+	delete(q.committed, backend)
+}
+
+func (q *srvQueue) reset() {
+	q.backends = nil
+	q.ops = nil
+	q.committed = nil
+}
+
+func errSrvNotExist(backend, server string) error {
+	return configuration.NewConfError(configuration.ErrObjectDoesNotExist, fmt.Sprintf("server %s does not exist in backend %s", server, backend))
+}
+
+func errSrvExist(backend, server string) error {
+	return configuration.NewConfError(configuration.ErrObjectAlreadyExists, fmt.Sprintf("server %s already exists in backend %s", server, backend))
+}
+
+// srvCommitted returns true if the server exists in the transaction, queued mutations excluded
+func (c *clientNative) srvCommitted(backend, server string) bool {
+	names, ok := c.srvQueue.committed[backend]
+	if !ok {
+		names = make(map[string]struct{})
+		if p, err := c.nativeAPI.Configuration.GetParser(c.activeTransaction); err == nil {
+			servers, _ := transactionServers(p, backend)
+			for _, s := range servers {
+				names[s.Name] = struct{}{}
+			}
+		}
+		if c.srvQueue.committed == nil {
+			c.srvQueue.committed = make(map[string]map[string]struct{})
+		}
+		c.srvQueue.committed[backend] = names
+	}
+	_, ok = names[server]
+	return ok
+}
+
+// transactionServers returns a copy of the servers of a backend in the transaction parser
+func transactionServers(p parser.Parser, backend string) ([]types.Server, error) {
+	data, err := p.Get(parser.Backends, backend, "server", false)
+	if errors.Is(err, parsererrors.ErrFetch) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]types.Server(nil), data.([]types.Server)...), nil
+}
+
+// flushSrvQueue applies queued server mutations to the parser of the active transaction, which
+// is then saved once. The transaction is not saved when a mutation fails, failing its commit.
+func (c *clientNative) flushSrvQueue() error {
+	defer c.srvQueue.reset()
+	if len(c.srvQueue.ops) == 0 {
+		return nil
+	}
+	p, err := c.nativeAPI.Configuration.GetParser(c.activeTransaction)
+	if err != nil {
+		return err
+	}
+	var errs utils.Errors
+	for _, backend := range c.srvQueue.backends {
+		b, ok := c.srvQueue.ops[backend]
+		if !ok {
+			continue
+		}
+		// a backend deleted then created again is listed twice
+		delete(c.srvQueue.ops, backend)
+		errs.Add(applySrvOps(p, backend, b))
+	}
+	if err = errs.Result(); err != nil {
+		return err
+	}
+	return c.saveTransaction(p)
+}
+
+// applySrvOps sets the servers of a backend in the parser with the queued mutations applied
+func applySrvOps(p parser.Parser, backend string, b *backendOps) error {
+	servers, err := transactionServers(p, backend)
+	if err != nil {
+		return fmt.Errorf("backend '%s': %w", backend, err)
+	}
+	var errs utils.Errors
+	result := make([]types.Server, 0, len(servers)+len(b.ops))
+	existing := make(map[string]struct{}, len(servers))
+	for _, server := range servers {
+		existing[server.Name] = struct{}{}
+		op, ok := b.ops[server.Name]
+		switch {
+		case !ok:
+			result = append(result, server)
+		case op.kind == srvDelete:
+		case op.kind == srvEdit:
+			if err = op.server.Validate(strfmt.Default); err != nil {
+				errs.Add(fmt.Errorf("backend '%s': server '%s': %w", backend, server.Name, err))
+				continue
+			}
+			result = append(result, configuration.SerializeServer(op.server))
+		default:
+			result = append(result, server)
+			errs.Add(errSrvExist(backend, server.Name))
+		}
+	}
+	// a server removed from then added again to the queue is listed twice
+	done := make(map[string]struct{}, len(b.servers))
+	for _, name := range b.servers {
+		op, ok := b.ops[name]
+		if _, applied := done[name]; applied || !ok {
+			continue
+		}
+		done[name] = struct{}{}
+		_, exists := existing[name]
+		switch {
+		case op.kind != srvCreate && !exists:
+			errs.Add(errSrvNotExist(backend, name))
+		case op.kind != srvCreate || exists:
+			// applied in place above
+		default:
+			if err = op.server.Validate(strfmt.Default); err != nil {
+				errs.Add(fmt.Errorf("backend '%s': server '%s': %w", backend, name, err))
+				continue
+			}
+			result = append(result, configuration.SerializeServer(op.server))
+		}
+	}
+	if err = errs.Result(); err != nil {
+		return err
+	}
+	return p.Set(parser.Backends, backend, "server", result)
+}
+
+// mapQueue holds the map files writes of the active transaction, nil content removes a map file.
+// Writes are applied when the transaction is committed, before its configuration loading them is
+// validated, and failed writes are kept for the next transaction.
+type mapQueue map[string]*string
+
+// MapFileWrite queues the write of a map file, see mapQueue
+func (c *clientNative) MapFileWrite(path, content string) {
+	if c.mapQueue == nil {
+		c.mapQueue = make(mapQueue)
+	}
+	c.mapQueue[path] = &content
+}
+
+// MapFileRemove queues the removal of a map file, see mapQueue
+func (c *clientNative) MapFileRemove(path string) {
+	if c.mapQueue == nil {
+		c.mapQueue = make(mapQueue)
+	}
+	c.mapQueue[path] = nil
+}
+
+// flushMapQueue applies queued map files writes
+func (c *clientNative) flushMapQueue() error {
+	var errs utils.Errors
+	for path, content := range c.mapQueue {
+		var err error
+		if content == nil {
+			err = os.Remove(path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		} else {
+			err = ioutil.WriteFile(path, []byte(*content), 0644) //nolint:gosec
+		}
+		if err != nil {
+			errs.Add(fmt.Errorf("map file '%s': %w", path, err))
+			continue
+		}
+		delete(c.mapQueue, path)
+	}
+	return errs.Result()
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// Backoff computes exponentially growing delays between retries,
+// randomized by up to half of the delay to avoid synchronized retries.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+var (
+	// RuntimeBackoff is used between attempts of runtime commands failing on socket errors
+	RuntimeBackoff = Backoff{Initial: 50 * time.Millisecond, Max: time.Second}
+	// TransactionBackoff is used between replays of a configuration sync whose transaction failed
+	TransactionBackoff = Backoff{Initial: 5 * time.Second, Max: 5 * time.Minute}
+)
+
+const runtimeAttempts = 3
+
+// Delay returns the delay before the given retry attempt, attempts starting at 1
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	half := int64(delay / 2)
+	return time.Duration(half + rand.Int63n(half+1)) //nolint:gosec
+}
+
+// TransactionStats reports the outcome of configuration transactions
+type TransactionStats struct {
+	Committed           uint64    `json:"committed"`
+	Failed              uint64    `json:"failed"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastFailure         time.Time `json:"last_failure,omitempty"`
+	NextRetry           time.Time `json:"next_retry,omitempty"`
+}
+
+// retryRuntime runs a runtime command until it succeeds, fails for another reason than
+// an unreachable or busy runtime socket or a stale worker, or runtimeAttempts are reached.
+func retryRuntime(fn func() error) (err error) {
+	var netErr net.Error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt == runtimeAttempts || !(errors.As(err, &netErr) || errors.Is(err, ErrStaleWorker)) {
+			return err
+		}
+		time.Sleep(RuntimeBackoff.Delay(attempt))
+	}
+}
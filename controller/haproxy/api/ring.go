@@ -0,0 +1,62 @@
+package api
+
+import (
+	parser "github.com/haproxytech/config-parser/v4"
+	"github.com/haproxytech/config-parser/v4/types"
+)
+
+// RingsGet returns the lines of the ring sections by ring name
+func (c *clientNative) RingsGet() (rings map[string][]string, err error) {
+	var p parser.Parser
+	if p, err = c.nativeAPI.Configuration.GetParser(c.activeTransaction); err != nil {
+		return
+	}
+	var sections []string
+	if sections, err = p.SectionsGet(parser.Ring); err != nil {
+		return
+	}
+	rings = make(map[string][]string, len(sections))
+	for _, section := range sections {
+		lines := []string{}
+		// ring keywords have no parser and are kept as unprocessed lines
+		if data, errGet := p.Get(parser.Ring, section, ""); errGet == nil {
+			for _, line := range data.([]types.UnProcessed) {
+				lines = append(lines, line.Value)
+			}
+		}
+		rings[section] = lines
+	}
+	return
+}
+
+// RingsReplace replaces the ring sections with the given ones
+func (c *clientNative) RingsReplace(rings map[string][]string) (err error) {
+	c.activeTransactionHasChanges = true
+
+	var p parser.Parser
+	if p, err = c.nativeAPI.Configuration.GetParser(c.activeTransaction); err != nil {
+		return
+	}
+	var sections []string
+	if sections, err = p.SectionsGet(parser.Ring); err != nil {
+		return
+	}
+	for _, section := range sections {
+		if err = p.SectionsDelete(parser.Ring, section); err != nil {
+			return
+		}
+	}
+	for name, lines := range rings {
+		if err = p.SectionsCreate(parser.Ring, name); err != nil {
+			return
+		}
+		data := make([]types.UnProcessed, 0, len(lines))
+		for _, line := range lines {
+			data = append(data, types.UnProcessed{Value: line})
+		}
+		if err = p.Set(parser.Ring, name, "", data); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/haproxytech/client-native/v2/models"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
@@ -12,25 +15,106 @@ func (c *clientNative) ExecuteRaw(command string) (result []string, err error) {
 }
 
 func (c *clientNative) SetServerAddr(backendName string, serverName string, ip string, port int) error {
-	return c.nativeAPI.Runtime.SetServerAddr(backendName, serverName, ip, port)
+	return retryRuntime(func() error {
+		return c.nativeAPI.Runtime.SetServerAddr(backendName, serverName, ip, port)
+	})
 }
 
 func (c *clientNative) SetServerState(backendName string, serverName string, state string) error {
-	return c.nativeAPI.Runtime.SetServerState(backendName, serverName, state)
+	return retryRuntime(func() error {
+		return c.nativeAPI.Runtime.SetServerState(backendName, serverName, state)
+	})
+}
+
+func (c *clientNative) SetServerWeight(backendName string, serverName string, weight string) error {
+	return retryRuntime(func() error {
+		return c.nativeAPI.Runtime.SetServerWeight(backendName, serverName, weight)
+	})
+}
+
+func (c *clientNative) BackendServersStateGet(backendName string) (models.RuntimeServers, error) {
+	if c.masterSocket != "" {
+		return c.serversStateWorkers(backendName)
+	}
+	return c.nativeAPI.Runtime.GetServersState(backendName)
+}
+
+func (c *clientNative) InfoGet() (models.ProcessInfos, error) {
+	return c.nativeAPI.Runtime.GetInfo()
 }
 
 func (c *clientNative) SetMapContent(mapFile string, payload string) error {
-	err := c.nativeAPI.Runtime.ClearMap(mapFile, false)
+	return retryRuntime(func() error {
+		err := c.nativeAPI.Runtime.ClearMap(mapFile, false)
+		if err != nil {
+			return err
+		}
+		return c.nativeAPI.Runtime.AddMapPayload(mapFile, payload)
+	})
+}
+
+// SetACLContent atomically replaces the content of a loaded ACL file
+func (c *clientNative) SetACLContent(aclFile string, entries []string) error {
+	version, err := c.nativeAPI.Runtime.PrepareACL(aclFile)
 	if err != nil {
 		return err
 	}
-	return c.nativeAPI.Runtime.AddMapPayload(mapFile, payload)
+	for _, entry := range entries {
+		if err = c.nativeAPI.Runtime.AddACLVersioned(version, aclFile, entry); err != nil {
+			return err
+		}
+	}
+	return c.nativeAPI.Runtime.CommitACL(version, aclFile)
+}
+
+// SetCertContent replaces the content of a loaded certificate file
+func (c *clientNative) SetCertContent(certFile string, payload []byte) error {
+	// an empty line ends the payload of a runtime command
+	var lines []string
+	for _, line := range strings.Split(string(payload), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	result, err := c.ExecuteRaw(fmt.Sprintf("set ssl cert %s <<\n%s\n", certFile, strings.Join(lines, "\n")))
+	if err != nil {
+		return err
+	}
+	if len(result) == 0 || !strings.Contains(result[0], "Transaction created for certificate") {
+		return fmt.Errorf("set ssl cert %s: %s", certFile, strings.Join(result, ""))
+	}
+	result, err = c.ExecuteRaw("commit ssl cert " + certFile)
+	if err != nil {
+		return err
+	}
+	if len(result) == 0 || !strings.Contains(result[0], "Success!") {
+		return fmt.Errorf("commit ssl cert %s: %s", certFile, strings.Join(result, ""))
+	}
+	return nil
 }
 
 func (c *clientNative) GetMap(mapFile string) (*models.Map, error) {
 	return c.nativeAPI.Runtime.GetMap(mapFile)
 }
 
+func (c *clientNative) StatsGet() (models.NativeStats, error) {
+	stats := c.nativeAPI.Runtime.GetStats()
+	for _, collection := range stats {
+		if collection.Error != "" {
+			return nil, fmt.Errorf("%s: %s", collection.RuntimeAPI, collection.Error)
+		}
+	}
+	return stats, nil
+}
+
+func (c *clientNative) TablesGet() (models.StickTables, error) {
+	return c.nativeAPI.Runtime.ShowTables(0)
+}
+
+func (c *clientNative) TableEntriesGet(table string, filter []string, key string) (models.StickTableEntries, error) {
+	return c.nativeAPI.Runtime.GetTableEntries(table, 0, filter, key)
+}
+
 // SyncBackendSrvs syncs states and addresses of a backend servers with corresponding endpoints.
 func (c *clientNative) SyncBackendSrvs(oldEndpoints, newEndpoints *store.PortEndpoints) error {
 	if oldEndpoints.BackendName == "" {
@@ -38,6 +122,7 @@ func (c *clientNative) SyncBackendSrvs(oldEndpoints, newEndpoints *store.PortEnd
 	}
 	newEndpoints.HAProxySrvs = oldEndpoints.HAProxySrvs
 	newEndpoints.BackendName = oldEndpoints.BackendName
+	newEndpoints.SrvAdminState = oldEndpoints.SrvAdminState
 	haproxySrvs := newEndpoints.HAProxySrvs
 	newAddresses := newEndpoints.AddrNew
 	portChanged := newEndpoints.Port != oldEndpoints.Port
@@ -46,7 +131,8 @@ func (c *clientNative) SyncBackendSrvs(oldEndpoints, newEndpoints *store.PortEnd
 	var disabled []*store.HAProxySrv
 	var errors utils.Errors
 	for i, srv := range haproxySrvs {
-		srv.Modified = portChanged || srv.Modified
+		srv.Modified = portChanged || srv.Modified || oldEndpoints.AddrPort(srv.Address) != newEndpoints.AddrPort(srv.Address) ||
+			oldEndpoints.SrvState(srv.Address) != newEndpoints.SrvState(srv.Address)
 		if _, ok := newAddresses[srv.Address]; ok {
 			delete(newAddresses, srv.Address)
 		} else {
@@ -77,9 +163,8 @@ func (c *clientNative) SyncBackendSrvs(oldEndpoints, newEndpoints *store.PortEnd
 			addrErr = c.SetServerAddr(newEndpoints.BackendName, srv.Name, "127.0.0.1", 0)
 			stateErr = c.SetServerState(newEndpoints.BackendName, srv.Name, "maint")
 		} else {
-			// logger.Tracef("server '%s/%s' changed status to %v", newEndpoints.BackendName, srv.Name, "ready")
-			addrErr = c.SetServerAddr(newEndpoints.BackendName, srv.Name, srv.Address, int(newEndpoints.Port))
-			stateErr = c.SetServerState(newEndpoints.BackendName, srv.Name, "ready")
+			addrErr = c.SetServerAddr(newEndpoints.BackendName, srv.Name, srv.Address, int(newEndpoints.AddrPort(srv.Address)))
+			stateErr = c.SetServerState(newEndpoints.BackendName, srv.Name, newEndpoints.SrvState(srv.Address))
 		}
 		if addrErr != nil || stateErr != nil {
 			newEndpoints.DynUpdateFailed = true
@@ -6,22 +6,40 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 type Certificates struct {
 	frontend map[string]*cert
 	backend  map[string]*cert
 	ca       map[string]*cert
+	// crtList certificates are served with per host options, they are listed
+	// in the frontend crt-list instead of being loaded from the frontend directory
+	crtList map[string]*cert
+	// crtListEntries are the lines of the frontend crt-list for the current sync
+	crtListEntries map[string]struct{}
+	// crtListContent is the content of the last written frontend crt-list,
+	// the file is written at the first sync even if empty.
+	crtListContent string
+	crtListWritten bool
+	// scanned is set once certificate directories were cleaned from files of previous runs
+	scanned bool
 }
 
 type cert struct {
-	name    string
-	path    string
-	inUse   bool
+	name  string
+	path  string
+	inUse bool
+	// updated is set when the certificate file was rewritten during the current sync
 	updated bool
+	// added is set when the certificate file did not exist before the current sync
+	added bool
 }
 
 type SecretType int
@@ -31,6 +49,7 @@ const (
 	NONE_CERT SecretType = iota
 	FT_CERT
 	FT_DEFAULT_CERT
+	FT_CRTLIST_CERT
 	BD_CERT
 	CA_CERT
 )
@@ -45,15 +64,21 @@ var ErrCertNotFound = errors.New("notFound")
 var frontendCertDir string
 var backendCertDir string
 var caCertDir string
+var crtListCertDir string
+var crtListFile string
 
-func NewCertificates(caDir, ftDir, bdDir string) *Certificates {
+func NewCertificates(caDir, ftDir, bdDir, crtListDir, crtList string) *Certificates {
 	frontendCertDir = ftDir
 	backendCertDir = bdDir
 	caCertDir = caDir
+	crtListCertDir = crtListDir
+	crtListFile = crtList
 	return &Certificates{
-		frontend: make(map[string]*cert),
-		backend:  make(map[string]*cert),
-		ca:       make(map[string]*cert),
+		frontend:       make(map[string]*cert),
+		backend:        make(map[string]*cert),
+		ca:             make(map[string]*cert),
+		crtList:        make(map[string]*cert),
+		crtListEntries: make(map[string]struct{}),
 	}
 }
 
@@ -78,6 +103,10 @@ func (c *Certificates) HandleTLSSecret(k8s store.K8s, secretCtx SecretCtx) (cert
 		certName = fmt.Sprintf("%s_%s", secret.Namespace, secret.Name)
 		certPath = path.Join(frontendCertDir, certName)
 		certs = c.frontend
+	case FT_CRTLIST_CERT:
+		certName = fmt.Sprintf("%s_%s", secret.Namespace, secret.Name)
+		certPath = path.Join(crtListCertDir, certName)
+		certs = c.crtList
 	case BD_CERT:
 		certName = fmt.Sprintf("%s_%s", secret.Namespace, secret.Name)
 		certPath = path.Join(backendCertDir, certName)
@@ -93,7 +122,8 @@ func (c *Certificates) HandleTLSSecret(k8s store.K8s, secretCtx SecretCtx) (cert
 	crt, crtOk = certs[certName]
 	if crtOk {
 		crt.inUse = true
-		if secret.Status == store.EMPTY {
+		// the secret may be referenced several times, write it once per sync
+		if secret.Status == store.EMPTY || crt.updated {
 			return crt.path, nil
 		}
 	}
@@ -102,6 +132,7 @@ func (c *Certificates) HandleTLSSecret(k8s store.K8s, secretCtx SecretCtx) (cert
 		name:    fmt.Sprintf("%s/%s", secret.Namespace, secret.Name),
 		inUse:   true,
 		updated: true,
+		added:   !crtOk,
 	}
 	err = writeSecret(secret, crt, privateKeyNull)
 	if err != nil {
@@ -115,15 +146,24 @@ func (c *Certificates) Clean() {
 	for i := range c.frontend {
 		c.frontend[i].inUse = false
 		c.frontend[i].updated = false
+		c.frontend[i].added = false
 	}
 	for i := range c.backend {
 		c.backend[i].inUse = false
 		c.backend[i].updated = false
+		c.backend[i].added = false
 	}
 	for i := range c.ca {
 		c.ca[i].inUse = false
 		c.ca[i].updated = false
+		c.ca[i].added = false
+	}
+	for i := range c.crtList {
+		c.crtList[i].inUse = false
+		c.crtList[i].updated = false
+		c.crtList[i].added = false
 	}
+	c.crtListEntries = make(map[string]struct{})
 }
 
 func (c *Certificates) FrontendCertsEnabled() bool {
@@ -132,30 +172,134 @@ func (c *Certificates) FrontendCertsEnabled() bool {
 			return true
 		}
 	}
-	return false
+	return c.CrtListEnabled()
+}
+
+// AddCrtListEntry serves the certificate, written with the FT_CRTLIST_CERT type,
+// for the host with the given ALPN advertisement.
+func (c *Certificates) AddCrtListEntry(certPath, host, alpn string) {
+	c.crtListEntries[fmt.Sprintf("%s [alpn %s] %s", certPath, alpn, host)] = struct{}{}
+}
+
+// CrtListEnabled returns true if the frontend crt-list has entries
+func (c *Certificates) CrtListEnabled() bool {
+	return len(c.crtListEntries) != 0
+}
+
+// CrtListFile returns the path of the frontend crt-list
+func (c *Certificates) CrtListFile() string {
+	return crtListFile
+}
+
+// refreshCrtList writes the frontend crt-list when its entries changed
+func (c *Certificates) refreshCrtList() (reload bool) {
+	entries := make([]string, 0, len(c.crtListEntries))
+	for entry := range c.crtListEntries {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	content := strings.Join(entries, "\n")
+	if c.crtListWritten && content == c.crtListContent {
+		return false
+	}
+	if err := ioutil.WriteFile(crtListFile, []byte(content+"\n"), 0600); err != nil {
+		logger.Error(err)
+		return false
+	}
+	c.crtListContent = content
+	c.crtListWritten = true
+	if !c.CrtListEnabled() {
+		// the crt-list is then removed from the binds
+		return false
+	}
+	logger.Debugf("crt-list %s updated, reload required", crtListFile)
+	utils.ReloadRequired("crt_list_updated", crtListFile, "")
+	return true
 }
 
-// Refresh removes unused certs from HAProxyCertDir
+// Refresh removes unused certs from HAProxyCertDir.
+// Certificate directories are only scanned the first time, to remove files left by a previous run.
 func (c *Certificates) Refresh() (reload bool) {
+	if !c.scanned {
+		c.scanned = true
+		reload = scanCerts(c.frontend, frontendCertDir)
+		reload = scanCerts(c.backend, backendCertDir) || reload
+		reload = scanCerts(c.ca, caCertDir) || reload
+		reload = scanCerts(c.crtList, crtListCertDir) || reload
+		return c.refreshCrtList() || reload
+	}
 	reload = refreshCerts(c.frontend, frontendCertDir)
 	reload = refreshCerts(c.backend, backendCertDir) || reload
 	reload = refreshCerts(c.ca, caCertDir) || reload
-	return
+	reload = refreshCerts(c.crtList, crtListCertDir) || reload
+	return c.refreshCrtList() || reload
 }
 
-func (c *Certificates) Updated() (reload bool) {
-	for _, certs := range []map[string]*cert{c.frontend, c.backend, c.ca} {
+// Updated returns true if a reload is required to apply certificates updates.
+// Updated frontend and backend certificates are replaced via runtime API,
+// only added certificates, updated CA files and failed runtime updates require a reload.
+func (c *Certificates) Updated(client api.HAProxyClient) (reload bool) {
+	for _, crt := range c.ca {
+		if crt.updated {
+			logger.Debugf("Secret '%s' was updated, reload required", crt.name)
+			utils.ReloadRequired("ca_certificate_updated", crt.name, "")
+			reload = true
+		}
+	}
+	for _, certs := range []map[string]*cert{c.frontend, c.crtList, c.backend} {
 		for _, crt := range certs {
-			if crt.updated {
-				logger.Debugf("Secret '%s' was updated, reload required", crt.name)
+			switch {
+			case !crt.updated:
+			case crt.added:
+				logger.Debugf("Secret '%s' was added, reload required", crt.name)
+				utils.ReloadRequired("certificate_added", crt.name, "")
 				reload = true
+			default:
+				if err := updateCert(client, crt); err != nil {
+					logger.Warningf("Secret '%s': runtime update failed, reload required: %s", crt.name, err)
+					utils.ReloadRequired("certificate_updated", crt.name, err.Error())
+					reload = true
+					continue
+				}
+				logger.Debugf("Secret '%s' was updated via runtime API", crt.name)
 			}
 		}
 	}
 	return reload
 }
 
+func updateCert(client api.HAProxyClient, crt *cert) error {
+	if !strings.HasSuffix(crt.path, ".pem") {
+		// certificate bundles are split by HAProxy into several certificates
+		return errors.New("runtime update of certificate bundles not supported")
+	}
+	payload, err := ioutil.ReadFile(crt.path)
+	if err != nil {
+		return err
+	}
+	return client.SetCertContent(crt.path, payload)
+}
+
+// refreshCerts removes files of certs not used anymore
 func refreshCerts(certs map[string]*cert, certDir string) (reload bool) {
+	for certName, crt := range certs {
+		if crt.inUse {
+			continue
+		}
+		files, _ := filepath.Glob(path.Join(certDir, certName+".pem*"))
+		for _, f := range files {
+			logger.Error(os.Remove(f))
+		}
+		delete(certs, certName)
+		reload = true
+		logger.Debugf("secret %s removed, reload required", crt.name)
+		utils.ReloadRequired("certificate_removed", crt.name, "")
+	}
+	return
+}
+
+// scanCerts removes from certDir the files of certs not used
+func scanCerts(certs map[string]*cert, certDir string) (reload bool) {
 	files, err := ioutil.ReadDir(certDir)
 	if err != nil {
 		logger.Error(err)
@@ -173,7 +317,8 @@ func refreshCerts(certs map[string]*cert, certDir string) (reload bool) {
 			logger.Error(os.Remove(path.Join(certDir, filename)))
 			delete(certs, certName)
 			reload = true
-			logger.Debug("secret %s removed, reload required", crt.name)
+			logger.Debugf("certificate file %s removed, reload required", filename)
+			utils.ReloadRequired("certificate_removed", filename, "")
 		}
 	}
 	return
@@ -16,12 +16,12 @@ package haproxy
 
 import (
 	"hash/fnv"
-	"os"
 	"path"
 	"sort"
 	"strings"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 type Maps map[string]*mapFile
@@ -30,10 +30,19 @@ var mapDir string
 
 //nolint:golint,stylecheck
 const (
-	MAP_SNI         = "sni"
-	MAP_HOST        = "host"
-	MAP_PATH_EXACT  = "path-exact"
-	MAP_PATH_PREFIX = "path-prefix"
+	MAP_SNI          = "sni"
+	MAP_HOST         = "host"
+	MAP_PATH_EXACT   = "path-exact"
+	MAP_PATH_PREFIX  = "path-prefix"
+	MAP_HOST_DEFAULT = "host-default"
+)
+
+//nolint:golint,stylecheck
+const (
+	// Internal binds and the map entries of internal ingress hosts are prefixed
+	// so internal ingresses are only reachable via internal binds.
+	INTERNAL_BIND_PREFIX = "internal"
+	INTERNAL_HOST_PREFIX = "internal@"
 )
 
 type mapFile struct {
@@ -59,10 +68,11 @@ func NewMapFiles(path string) *Maps {
 	mapDir = path
 	var maps Maps = map[string]*mapFile{
 		// Map files required for HAProxy Rules
-		MAP_SNI:         {preserve: true},
-		MAP_HOST:        {preserve: true},
-		MAP_PATH_EXACT:  {preserve: true},
-		MAP_PATH_PREFIX: {preserve: true},
+		MAP_SNI:          {preserve: true},
+		MAP_HOST:         {preserve: true},
+		MAP_PATH_EXACT:   {preserve: true},
+		MAP_PATH_PREFIX:  {preserve: true},
+		MAP_HOST_DEFAULT: {preserve: true},
 	}
 	return &maps
 }
@@ -82,12 +92,36 @@ func (m Maps) AppendRow(name string, row string) {
 	m[name].rows = append(m[name].rows, row)
 }
 
+// Entries returns the number of rows of each map file
+func (m Maps) Entries() map[string]int {
+	entries := make(map[string]int, len(m))
+	for name, mapFile := range m {
+		entries[name] = len(mapFile.rows)
+	}
+	return entries
+}
+
+// Keys returns the number of distinct keys in the given map file
+func (m Maps) Keys(name string) int {
+	if m[name] == nil {
+		return 0
+	}
+	keys := make(map[string]struct{}, len(m[name].rows))
+	for _, row := range m[name].rows {
+		if fields := strings.Fields(row); len(fields) != 0 {
+			keys[fields[0]] = struct{}{}
+		}
+	}
+	return len(keys)
+}
+
 func (m Maps) Clean() {
 	for _, mapFile := range m {
 		mapFile.rows = []string{}
 	}
 }
 
+// Refresh queues in the client transaction the writes of the updated map files
 func (m Maps) Refresh(client api.HAProxyClient) (reload bool) {
 	for name, mapFile := range m {
 		content, hash := mapFile.getContent()
@@ -95,25 +129,16 @@ func (m Maps) Refresh(client api.HAProxyClient) (reload bool) {
 			continue
 		}
 		mapFile.hash = hash
-		var f *os.File
-		var err error
 		filename := GetMapPath(name)
 		if content == "" && !mapFile.preserve {
-			logger.Error(os.Remove(filename))
+			client.MapFileRemove(filename)
 			delete(m, name)
 			continue
-		} else if f, err = os.Create(filename); err != nil {
-			logger.Error(err)
-			continue
-		}
-		defer f.Close()
-		if _, err = f.WriteString(content); err != nil {
-			logger.Error(err)
-			continue
 		}
-		logger.Error(f.Sync())
+		client.MapFileWrite(filename, content)
 		reload = true
 		logger.Debugf("Map file '%s' updated, reload required", name)
+		utils.ReloadRequired("map_updated", name, "")
 		// if err = client.SetMapContent(name, content); err != nil {
 		// 	if strings.HasPrefix(err.Error(), "maps dir doesn't exists") {
 		// 		logger.Debugf("creating Map file %s", name)
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"sync"
 	"syscall"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/configuration"
@@ -17,6 +18,9 @@ type directControl struct {
 	OSArgs     utils.OSArgs
 	API        api.HAProxyClient
 	useAuxFile bool
+	mu         sync.Mutex
+	// startErr is the exit error of the last HAProxy command, which returns once HAProxy is daemonized
+	startErr error
 }
 
 func NewDirectControl(env configuration.Env, oSArgs utils.OSArgs, api api.HAProxyClient) Process {
@@ -44,13 +48,10 @@ func (d *directControl) HaproxyService(action string) (err error) {
 			logger.Error("haproxy is already running")
 			return nil
 		}
-		cmd = exec.Command(d.Env.HAProxyBinary, "-f", d.Env.MainCFGFile)
-		if d.useAuxFile {
-			cmd = exec.Command(d.Env.HAProxyBinary, "-f", d.Env.MainCFGFile, "-f", d.Env.AuxCFGFile)
-		}
+		cmd = exec.Command(d.Env.HAProxyBinary, d.args()...)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
-		return cmd.Start()
+		return d.start(cmd)
 	case "stop":
 		if processErr != nil {
 			logger.Error("haproxy already stopped")
@@ -75,18 +76,60 @@ func (d *directControl) HaproxyService(action string) (err error) {
 			return d.HaproxyService("start")
 		}
 		pid := strconv.Itoa(process.Pid)
-		cmd = exec.Command(d.Env.HAProxyBinary, "-f", d.Env.MainCFGFile, "-sf", pid)
-		if d.useAuxFile {
-			cmd = exec.Command(d.Env.HAProxyBinary, "-f", d.Env.MainCFGFile, "-f", d.Env.AuxCFGFile, "-sf", pid)
-		}
+		cmd = exec.Command(d.Env.HAProxyBinary, append(d.args(), "-sf", pid)...)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
-		return cmd.Start()
+		return d.start(cmd)
 	default:
 		return fmt.Errorf("unknown command '%s'", action)
 	}
 }
 
+// start runs an HAProxy command and records its exit error
+func (d *directControl) start(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.startErr = nil
+	d.mu.Unlock()
+	go func() {
+		err := cmd.Wait()
+		d.mu.Lock()
+		d.startErr = err
+		d.mu.Unlock()
+	}()
+	return nil
+}
+
+func (d *directControl) Status() error {
+	if d.OSArgs.Test {
+		return nil
+	}
+	process, err := haproxyProcess(d.Env.PIDFile)
+	if err != nil {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.startErr != nil {
+			return fmt.Errorf("master process not running: %w", d.startErr)
+		}
+		return fmt.Errorf("master process not running: %w", err)
+	}
+	return processStatus(process.Pid)
+}
+
+// args returns the HAProxy command line arguments, the master CLI is exposed when runtime commands go through it
+func (d *directControl) args() []string {
+	args := []string{"-f", d.Env.MainCFGFile}
+	if d.useAuxFile {
+		args = append(args, "-f", d.Env.AuxCFGFile)
+	}
+	if d.Env.MasterSocket != "" {
+		args = append(args, "-S", d.Env.MasterSocket)
+	}
+	return args
+}
+
 func (d *directControl) UseAuxFile(useAuxFile bool) {
 	d.useAuxFile = useAuxFile
 }
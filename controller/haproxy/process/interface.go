@@ -2,8 +2,13 @@ package process
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
@@ -13,6 +18,8 @@ import (
 type Process interface {
 	HaproxyService(action string) (err error)
 	UseAuxFile(useAuxFile bool)
+	// Status returns nil while HAProxy runs, or else why it does not
+	Status() error
 }
 
 var logger = utils.GetLogger()
@@ -38,6 +45,32 @@ func haproxyProcess(pidFile string) (*os.Process, error) {
 	return process, err
 }
 
+// processStatus returns why a running process is a zombie, with its exit status when it is a child
+// of the controller, as happens in containers where the controller is PID 1 and adopts HAProxy daemon.
+func processStatus(pid int) error {
+	stat, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil
+	}
+	// the state follows the command name between parenthesis
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	if len(fields) == 0 || fields[0] != "Z" {
+		return nil
+	}
+	var ws syscall.WaitStatus
+	if wpid, errWait := syscall.Wait4(pid, &ws, syscall.WNOHANG, nil); errWait != nil || wpid != pid {
+		return fmt.Errorf("master process %d exited", pid)
+	}
+	return fmt.Errorf("master process %d %s", pid, waitStatus(ws))
+}
+
+func waitStatus(ws syscall.WaitStatus) string {
+	if ws.Signaled() {
+		return fmt.Sprintf("killed by signal %s", ws.Signal())
+	}
+	return fmt.Sprintf("exited with code %d", ws.ExitStatus())
+}
+
 // Saves HAProxy servers state so it is retrieved after reload.
 func saveServerState(stateDir string, api api.HAProxyClient) error {
 	result, err := api.ExecuteRaw("show servers state")
@@ -45,7 +78,7 @@ func saveServerState(stateDir string, api api.HAProxyClient) error {
 		return err
 	}
 	var f *os.File
-	if f, err = os.Create(stateDir + "global"); err != nil {
+	if f, err = os.Create(filepath.Join(stateDir, "global")); err != nil {
 		logger.Error(err)
 		return err
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/configuration"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
@@ -67,6 +68,25 @@ func (d *s6Control) HaproxyService(action string) (err error) {
 	}
 }
 
+// Status reports HAProxy as not running while s6 restarts it
+func (d *s6Control) Status() error {
+	if d.OSArgs.Test {
+		return nil
+	}
+	out, err := exec.Command("s6-svstat", "-o", "up,exitcode,signal", "/var/run/s6/services/haproxy").Output()
+	if err != nil {
+		return fmt.Errorf("s6-svstat: %w", err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 3 || fields[0] == "true" {
+		return nil
+	}
+	if fields[2] != "NA" {
+		return fmt.Errorf("haproxy service down, killed by signal %s", fields[2])
+	}
+	return fmt.Errorf("haproxy service down, exited with code %s", fields[1])
+}
+
 func (d *s6Control) UseAuxFile(useAuxFile bool) {
 	// do nothing we always have it
 }
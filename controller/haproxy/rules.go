@@ -3,6 +3,7 @@ package haproxy
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/haproxytech/client-native/v2/models"
 
@@ -36,38 +37,60 @@ const (
 	REQ_ACCEPT_CONTENT RuleType = iota
 	REQ_INSPECT_DELAY
 	REQ_PROXY_PROTOCOL
+	REQ_CONN_TRACK
 	REQ_SET_VAR
 	REQ_SET_SRC
+	REQ_SNI_CHECK
 	REQ_DENY
 	REQ_TRACK
 	REQ_AUTH
+	REQ_OIDC
 	REQ_RATELIMIT
+	REQ_SPIKE_ARREST
+	REQ_CONNLIMIT
 	REQ_CAPTURE
+	REQ_SET_PRIORITY
 	REQ_REDIRECT
+	REQ_RETURN
 	REQ_FORWARDED_PROTO
 	REQ_SET_HEADER
 	REQ_SET_HOST
 	REQ_PATH_REWRITE
 	RES_SET_HEADER
+	RES_REPLACE_HEADER
+	RES_SET_COOKIE
+	RES_SET_STATUS
+	RES_LOG_SAMPLE
 )
 
 var constLookup = map[RuleType]string{
 	REQ_ACCEPT_CONTENT:  "REQ_ACCEPT_CONTENT",
 	REQ_INSPECT_DELAY:   "REQ_INSPECT_DELAY",
 	REQ_PROXY_PROTOCOL:  "REQ_PROXY_PROTOCOL",
+	REQ_CONN_TRACK:      "REQ_CONN_TRACK",
 	REQ_SET_VAR:         "REQ_SET_VAR",
 	REQ_SET_SRC:         "REQ_SET_SRC",
+	REQ_SNI_CHECK:       "REQ_SNI_CHECK",
 	REQ_DENY:            "REQ_DENY",
 	REQ_TRACK:           "REQ_TRACK",
 	REQ_AUTH:            "REQ_AUTH",
+	REQ_OIDC:            "REQ_OIDC",
 	REQ_RATELIMIT:       "REQ_RATELIMIT",
+	REQ_SPIKE_ARREST:    "REQ_SPIKE_ARREST",
+	REQ_CONNLIMIT:       "REQ_CONNLIMIT",
 	REQ_CAPTURE:         "REQ_CAPTURE",
+	REQ_SET_PRIORITY:    "REQ_SET_PRIORITY",
 	REQ_REDIRECT:        "REQ_REDIRECT",
+	REQ_RETURN:          "REQ_RETURN",
 	REQ_FORWARDED_PROTO: "REQ_FORWARDED_PROTO",
 	REQ_SET_HEADER:      "REQ_SET_HEADER",
 	REQ_SET_HOST:        "REQ_SET_HOST",
 	REQ_PATH_REWRITE:    "REQ_PATH_REWRITE",
 	RES_SET_HEADER:      "RES_SET_HEADER",
+	RES_REPLACE_HEADER:  "RES_REPLACE_HEADER",
+	RES_SET_COOKIE:      "RES_SET_COOKIE",
+	RES_SET_STATUS:      "RES_SET_STATUS",
+	RES_LOG_SAMPLE:      "RES_LOG_SAMPLE",
 }
 
 // RuleID uniquely identify a HAProxy Rule
@@ -88,6 +111,11 @@ type ruleset struct {
 type ruleInfo struct {
 	state   ruleState
 	ingress bool
+	// priority orders rules of a same type, highest first,
+	// appliedPriority is the one of the last refresh.
+	priority        int
+	prioritySet     bool
+	appliedPriority int
 }
 
 // ruleState describes Rule creation
@@ -138,6 +166,23 @@ func (r SectionRules) AddRule(rule Rule, ingressRule bool, frontend string) erro
 	return nil
 }
 
+// SetPriority sets the priority of a rule added to the frontend,
+// a rule added with several priorities gets the highest one.
+func (r SectionRules) SetPriority(rule Rule, frontend string, priority int) {
+	ftRuleSet, ok := r[frontend]
+	if !ok {
+		return
+	}
+	info, ok := ftRuleSet.meta[GetID(rule)]
+	if !ok || info.state == TO_DELETE {
+		return
+	}
+	if !info.prioritySet || priority > info.priority {
+		info.priority = priority
+		info.prioritySet = true
+	}
+}
+
 func (r SectionRules) DeleteFrontend(frontend string) {
 	delete(r, frontend)
 }
@@ -147,6 +192,8 @@ func (r SectionRules) Clean(frontends ...string) {
 		if ftRuleSet, ok := r[frontend]; ok {
 			for id := range ftRuleSet.meta {
 				ftRuleSet.meta[id].state = TO_DELETE
+				ftRuleSet.meta[id].priority = 0
+				ftRuleSet.meta[id].prioritySet = false
 			}
 		}
 	}
@@ -169,8 +216,9 @@ func (r SectionRules) Refresh(client api.HAProxyClient) (reload bool) {
 		// Which means first rule inserted will be last in the list of HAProxy rules after iteration
 		// Thus iteration is done in reverse to preserve order between the defined rules in
 		// controller and the resulting order in HAProxy configuration.
-		for ruleType := RES_SET_HEADER; ruleType >= REQ_ACCEPT_CONTENT; ruleType-- {
+		for ruleType := RES_LOG_SAMPLE; ruleType >= REQ_ACCEPT_CONTENT; ruleType-- {
 			rules := ftRuleSet.rules[ruleType]
+			sortRules(rules, ftRuleSet.meta)
 			for i := len(rules) - 1; i >= 0; i-- {
 				id := GetID(rules[i])
 				// Delete HAProxy Rule
@@ -193,6 +241,13 @@ func (r SectionRules) Refresh(client api.HAProxyClient) (reload bool) {
 				if ftRuleSet.meta[id].state == TO_CREATE {
 					reload = true
 					logger.Debugf("New HAProxy rule '%s' created, reload required", constLookup[ruleType])
+					utils.ReloadRequired("rule_created", fe.Name, constLookup[ruleType])
+				}
+				if info := ftRuleSet.meta[id]; info.priority != info.appliedPriority {
+					info.appliedPriority = info.priority
+					reload = true
+					logger.Debugf("HAProxy rule '%s' priority updated, reload required", constLookup[ruleType])
+					utils.ReloadRequired("rule_priority_updated", fe.Name, constLookup[ruleType])
 				}
 			}
 			ftRuleSet.rules[ruleType] = rules
@@ -201,6 +256,50 @@ func (r SectionRules) Refresh(client api.HAProxyClient) (reload bool) {
 	return reload
 }
 
+// Count returns the number of rules of each frontend by rule type
+func (r SectionRules) Count() map[string]map[string]int {
+	count := make(map[string]map[string]int, len(r))
+	for feName, ftRuleSet := range r {
+		count[feName] = make(map[string]int)
+		for ruleType, rules := range ftRuleSet.rules {
+			if len(rules) != 0 {
+				count[feName][constLookup[ruleType]] = len(rules)
+			}
+		}
+	}
+	return count
+}
+
+// sortRules orders rules by priority, highest first,
+// rules with the same priority keep their insertion order.
+func sortRules(rules []Rule, meta map[RuleID]*ruleInfo) {
+	byPriority := rulesByPriority{rules: rules, priorities: make([]int, len(rules))}
+	sorted := true
+	for i, rule := range rules {
+		byPriority.priorities[i] = meta[GetID(rule)].priority
+		if i > 0 && byPriority.priorities[i] > byPriority.priorities[i-1] {
+			sorted = false
+		}
+	}
+	if !sorted {
+		sort.Stable(byPriority)
+	}
+}
+
+type rulesByPriority struct {
+	rules      []Rule
+	priorities []int
+}
+
+func (r rulesByPriority) Len() int { return len(r.rules) }
+
+func (r rulesByPriority) Less(i, j int) bool { return r.priorities[i] > r.priorities[j] }
+
+func (r rulesByPriority) Swap(i, j int) {
+	r.rules[i], r.rules[j] = r.rules[j], r.rules[i]
+	r.priorities[i], r.priorities[j] = r.priorities[j], r.priorities[i]
+}
+
 func GetID(rule Rule) RuleID {
 	b, _ := json.Marshal(rule)
 	b = append(b, byte(rule.GetType()))
@@ -2,6 +2,7 @@ package rules
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/haproxytech/client-native/v2/models"
 
@@ -14,6 +15,9 @@ type ReqBasicAuth struct {
 	AuthGroup   string
 	AuthRealm   string
 	Credentials map[string][]byte
+	// ExcludePaths are paths not requiring authentication,
+	// matched exactly or, when ending with a slash, as a prefix.
+	ExcludePaths []string
 }
 
 func (r ReqBasicAuth) GetType() haproxy.RuleType {
@@ -39,9 +43,34 @@ func (r ReqBasicAuth) Create(client api.HAProxyClient, frontend *models.Frontend
 		Cond:      "if",
 		CondTest:  fmt.Sprintf("!{ http_auth_group(%s) authenticated-users }", r.AuthGroup),
 	}
+	var exact, prefixes []string
+	for _, path := range r.ExcludePaths {
+		if strings.HasSuffix(path, "/") {
+			prefixes = append(prefixes, path)
+		} else {
+			exact = append(exact, path)
+		}
+	}
+	if len(prefixes) != 0 {
+		httpRule.CondTest = fmt.Sprintf("!{ path_beg %s } %s", strings.Join(prefixes, " "), httpRule.CondTest)
+	}
+	if len(exact) != 0 {
+		httpRule.CondTest = fmt.Sprintf("!{ path %s } %s", strings.Join(exact, " "), httpRule.CondTest)
+	}
 	if err = client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL); err != nil {
 		return
 	}
-
-	return
+	if len(r.ExcludePaths) == 0 {
+		return
+	}
+	// Excluded paths are matched on the raw path, dot segments and encoded
+	// slashes or dots could reach a protected path once normalized by the backend.
+	denyRule := models.HTTPRequestRule{
+		Type:       "deny",
+		DenyStatus: utils.PtrInt64(400),
+		Index:      utils.PtrInt64(0),
+		Cond:       "if",
+		CondTest:   "{ path_sub -i /../ /./ %2f %2e %5c } || { path_end /.. /. }",
+	}
+	return client.FrontendHTTPRequestRuleCreate(frontend.Name, denyRule, ingressACL)
 }
@@ -0,0 +1,35 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ReqConnLimit denies the requests of source IP addresses with more concurrent connections
+// than ConnLimit, connections are tracked in sc1 by the ReqConnTrack rule.
+type ReqConnLimit struct {
+	TableName string
+	ConnLimit int64
+}
+
+func (r ReqConnLimit) GetType() haproxy.RuleType {
+	return haproxy.REQ_CONNLIMIT
+}
+
+func (r ReqConnLimit) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("connection limit cannot be configured in TCP mode")
+	}
+	httpRule := models.HTTPRequestRule{
+		Index:    utils.PtrInt64(0),
+		Type:     "deny",
+		Cond:     "if",
+		CondTest: fmt.Sprintf("{ sc1_conn_cur(%s) gt %d }", r.TableName, r.ConnLimit),
+	}
+	return client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL)
+}
@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ReqConnTrack tracks the concurrent connections of source IP addresses in sc1,
+// for the ReqConnLimit rules of the frontend.
+type ReqConnTrack struct {
+	TableName string
+}
+
+func (r ReqConnTrack) GetType() haproxy.RuleType {
+	return haproxy.REQ_CONN_TRACK
+}
+
+// Create adds a "tcp-request connection" rule, the ingress ACL is not used
+// since connections are tracked before their requests are known.
+func (r ReqConnTrack) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("connection limit cannot be configured in TCP mode")
+	}
+	// Create tracking table.
+	if _, err := client.BackendGet(r.TableName); err != nil {
+		err = client.BackendCreate(models.Backend{
+			Name: r.TableName,
+			StickTable: &models.BackendStickTable{
+				Peers: "localinstance",
+				Type:  "ip",
+				Size:  utils.PtrInt64(100000),
+				Store: "conn_cur",
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// sc1 is only tracked by this rule, rate limiting uses sc0 and spike arrest sc2.
+	tcpRule := models.TCPRequestRule{
+		Index:      utils.PtrInt64(0),
+		Type:       "connection",
+		Action:     models.TCPRequestRuleActionTrackSc1,
+		TrackKey:   "src",
+		TrackTable: r.TableName,
+	}
+	return client.FrontendTCPRequestRuleCreate(frontend.Name, tcpRule, "")
+}
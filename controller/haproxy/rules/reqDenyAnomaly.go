@@ -0,0 +1,36 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ReqDenyAnomaly denies the requests matching CondTest, such as requests
+// with an oversized URI or headers, with the given status.
+type ReqDenyAnomaly struct {
+	CondTest   string
+	DenyStatus int64
+}
+
+func (r ReqDenyAnomaly) GetType() haproxy.RuleType {
+	return haproxy.REQ_DENY
+}
+
+func (r ReqDenyAnomaly) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("request anomalies cannot be denied in TCP mode")
+	}
+	httpRule := models.HTTPRequestRule{
+		Index:      utils.PtrInt64(0),
+		Type:       "deny",
+		DenyStatus: utils.PtrInt64(r.DenyStatus),
+		Cond:       "if",
+		CondTest:   r.CondTest,
+	}
+	return client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL)
+}
@@ -0,0 +1,148 @@
+package rules
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+//nolint:golint,stylecheck
+const (
+	// OIDC_FRONTEND forwards the token requests of the "oidc_auth" Lua action,
+	// which cannot resolve and verify TLS servers, to the backend of the provider.
+	OIDC_FRONTEND = "oidc"
+	OIDC_SOCKET   = "abns@haproxy-oidc"
+	OIDC_CA_FILE  = "/etc/ssl/certs/ca-certificates.crt"
+	// OIDC_SECRET_KEY is the key of the client secret in its map file, it is loaded
+	// in the OIDC_SECRET_VAR txn variable read by the "oidc_auth" Lua action.
+	OIDC_SECRET_KEY = "client-secret"
+	OIDC_SECRET_VAR = "oidc_secret"
+)
+
+// ReqOIDC authenticates the requests with an OpenID Connect provider,
+// via the "oidc_auth" Lua action.
+type ReqOIDC struct {
+	Issuer        string
+	AuthEndpoint  string
+	TokenEndpoint string
+	ClientID      string
+	// ClientSecretFile is the map file of the client secret, which is never written
+	// to the configuration, ClientSecretHash changes with the secret.
+	ClientSecretFile string
+	ClientSecretHash string
+	RedirectPath     string
+	// RedirectHosts are the hosts allowed in the redirect URI, the request host is used
+	// when it is one of them, otherwise the first exact one.
+	RedirectHosts []string
+	CookieName    string
+	CookieMaxAge  int64
+}
+
+func (r ReqOIDC) GetType() haproxy.RuleType {
+	return haproxy.REQ_OIDC
+}
+
+// BackendName returns the name of the backend of the token endpoint
+func (r ReqOIDC) BackendName() string {
+	u, err := url.Parse(r.TokenEndpoint)
+	if err != nil {
+		return ""
+	}
+	return "oidc_" + u.Host
+}
+
+func (r ReqOIDC) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("OIDC authentication cannot be configured in TCP mode")
+	}
+	if r.AuthEndpoint == "" || r.TokenEndpoint == "" {
+		return fmt.Errorf("OIDC authentication: endpoints of issuer '%s' not set", r.Issuer)
+	}
+	if r.ClientID == "" || r.ClientSecretFile == "" {
+		return fmt.Errorf("OIDC authentication: client credentials of issuer '%s' not found", r.Issuer)
+	}
+	if err := r.createTokenBackend(client); err != nil {
+		return err
+	}
+	params := []string{OIDC_SOCKET, r.Issuer, r.AuthEndpoint, r.TokenEndpoint, r.ClientID,
+		r.RedirectPath, strings.Join(r.RedirectHosts, ","), r.CookieName, strconv.FormatInt(r.CookieMaxAge, 10)}
+	// rules are created at index 0, the client secret is loaded before the Lua action
+	httpRule := models.HTTPRequestRule{
+		Index:     utils.PtrInt64(0),
+		Type:      "lua",
+		LuaAction: "oidc_auth",
+		LuaParams: "\"" + strings.Join(params, "\" \"") + "\"",
+	}
+	if err := client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL); err != nil {
+		return err
+	}
+	httpRule = models.HTTPRequestRule{
+		Index:    utils.PtrInt64(0),
+		Type:     "set-var",
+		VarName:  OIDC_SECRET_VAR,
+		VarScope: "txn",
+		VarExpr:  fmt.Sprintf("str(%s),map(%s)", OIDC_SECRET_KEY, r.ClientSecretFile),
+	}
+	return client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL)
+}
+
+// createTokenBackend creates the internal frontend and the backend of the token endpoint
+func (r ReqOIDC) createTokenBackend(client api.HAProxyClient) (err error) {
+	if _, err = client.FrontendGet(OIDC_FRONTEND); err != nil {
+		var errors utils.Errors
+		errors.Add(
+			client.FrontendCreate(models.Frontend{
+				Name: OIDC_FRONTEND,
+				Mode: "http",
+			}),
+			client.FrontendBindCreate(OIDC_FRONTEND, models.Bind{
+				Name:    OIDC_FRONTEND,
+				Address: OIDC_SOCKET,
+			}),
+			client.BackendSwitchingRuleCreate(OIDC_FRONTEND, models.BackendSwitchingRule{
+				Index: utils.PtrInt64(0),
+				Name:  "oidc_%[req.hdr(host)]",
+			}),
+		)
+		if err = errors.Result(); err != nil {
+			return
+		}
+	}
+	backendName := r.BackendName()
+	if _, err = client.BackendGet(backendName); err == nil {
+		return
+	}
+	u, _ := url.Parse(r.TokenEndpoint)
+	host, port := u.Host, int64(443)
+	if h, p, errSplit := net.SplitHostPort(u.Host); errSplit == nil {
+		host = h
+		if port, err = strconv.ParseInt(p, 10, 64); err != nil {
+			return
+		}
+	}
+	if err = client.BackendCreate(models.Backend{
+		Name: backendName,
+		Mode: "http",
+	}); err != nil {
+		return
+	}
+	return client.BackendServerCreate(backendName, models.Server{
+		Name:      "provider",
+		Address:   host,
+		Port:      &port,
+		Ssl:       "enabled",
+		Verify:    "required",
+		SslCafile: OIDC_CA_FILE,
+		Sni:       "str(" + host + ")",
+		// the provider is resolved at startup, without failing when it cannot be
+		InitAddr: utils.PtrString("last,libc,none"),
+	})
+}
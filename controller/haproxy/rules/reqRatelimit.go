@@ -2,6 +2,8 @@ package rules
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
 
 	"github.com/haproxytech/client-native/v2/models"
 
@@ -14,6 +16,13 @@ type ReqRateLimit struct {
 	TableName      string
 	ReqsLimit      int64
 	DenyStatusCode int64
+	// TrackKey is the sample used to look up per key limits in LimitsMap
+	TrackKey string
+	// LimitsMap is the path of a map file holding "<key> <limit>" entries,
+	// keys missing from the map are limited to ReqsLimit.
+	LimitsMap string
+	// RetryAfter, when set, is returned in seconds via the Retry-After header.
+	RetryAfter *int64
 }
 
 func (r ReqRateLimit) GetType() haproxy.RuleType {
@@ -24,12 +33,53 @@ func (r ReqRateLimit) Create(client api.HAProxyClient, frontend *models.Frontend
 	if frontend.Mode == "tcp" {
 		return fmt.Errorf("request Track cannot be configured in TCP mode")
 	}
+	condTest := fmt.Sprintf("{ sc0_http_req_rate(%s) gt %d }", r.TableName, r.ReqsLimit)
+	if r.LimitsMap != "" {
+		condTest = fmt.Sprintf("{ sc0_http_req_rate(%s),sub(txn.ratelimit_max) gt 0 }", r.TableName)
+	}
 	httpRule := models.HTTPRequestRule{
 		Index:      utils.PtrInt64(0),
 		Type:       "deny",
 		DenyStatus: utils.PtrInt64(r.DenyStatusCode),
 		Cond:       "if",
-		CondTest:   fmt.Sprintf("{ sc0_http_req_rate(%s) gt %d }", r.TableName, r.ReqsLimit),
+		CondTest:   condTest,
+	}
+	if r.RetryAfter != nil {
+		httpRule = models.HTTPRequestRule{
+			Index:               utils.PtrInt64(0),
+			Type:                "return",
+			ReturnStatusCode:    utils.PtrInt64(r.DenyStatusCode),
+			ReturnContentType:   utils.PtrString("text/plain"),
+			ReturnContentFormat: "string",
+			ReturnContent:       strconv.Quote(http.StatusText(int(r.DenyStatusCode))),
+			ReturnHeaders: []*models.HTTPRequestRuleReturnHdrsItems0{
+				{
+					Name: utils.PtrString("Retry-After"),
+					Fmt:  utils.PtrString(strconv.FormatInt(*r.RetryAfter, 10)),
+				},
+			},
+			Cond:     "if",
+			CondTest: condTest,
+		}
+	}
+	if err := client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL); err != nil {
+		return err
+	}
+	if r.LimitsMap == "" {
+		return nil
+	}
+	// Rules are inserted at index 0 so the limit lookup
+	// is created last in order to be evaluated first.
+	converter := "map_str_int"
+	if r.TrackKey == "src" {
+		converter = "map_ip_int"
+	}
+	httpRule = models.HTTPRequestRule{
+		Index:    utils.PtrInt64(0),
+		Type:     "set-var",
+		VarName:  "ratelimit_max",
+		VarScope: "txn",
+		VarExpr:  fmt.Sprintf("%s,%s(%s,%d)", r.TrackKey, converter, r.LimitsMap, r.ReqsLimit),
 	}
 	return client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL)
 }
@@ -0,0 +1,44 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ReqReturn answers requests with a static response without forwarding them to a backend.
+type ReqReturn struct {
+	StatusCode  int64
+	ContentType string
+	// ContentFormat is the HAProxy "return" content format ("string", "file", "errorfile"...)
+	ContentFormat string
+	Content       string
+	CondTest      string
+}
+
+func (r ReqReturn) GetType() haproxy.RuleType {
+	return haproxy.REQ_RETURN
+}
+
+func (r ReqReturn) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("static responses cannot be returned in TCP mode")
+	}
+	httpRule := models.HTTPRequestRule{
+		Index:               utils.PtrInt64(0),
+		Type:                "return",
+		ReturnStatusCode:    utils.PtrInt64(r.StatusCode),
+		ReturnContentType:   utils.PtrString(r.ContentType),
+		ReturnContentFormat: r.ContentFormat,
+		ReturnContent:       r.Content,
+	}
+	if r.CondTest != "" {
+		httpRule.Cond = "if"
+		httpRule.CondTest = r.CondTest
+	}
+	return client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL)
+}
@@ -0,0 +1,45 @@
+package rules
+
+import (
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ReqSNICheck matches requests whose TLS SNI differs from the Host header (domain fronting),
+// they are rejected with a 421 Misdirected Request or their SNI is captured for logging.
+type ReqSNICheck struct {
+	Reject bool
+}
+
+func (r ReqSNICheck) GetType() haproxy.RuleType {
+	return haproxy.REQ_SNI_CHECK
+}
+
+func (r ReqSNICheck) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return nil
+	}
+	// txn.host is the lowercase Host header without port
+	condTest := "{ ssl_fc_sni -m found } !{ ssl_fc_sni,lower,strcmp(txn.host) eq 0 }"
+	httpRule := models.HTTPRequestRule{
+		Index:         utils.PtrInt64(0),
+		Type:          "capture",
+		CaptureSample: "ssl_fc_sni",
+		CaptureLen:    128,
+		Cond:          "if",
+		CondTest:      condTest,
+	}
+	if r.Reject {
+		httpRule = models.HTTPRequestRule{
+			Index:      utils.PtrInt64(0),
+			Type:       "deny",
+			DenyStatus: utils.PtrInt64(421),
+			Cond:       "if",
+			CondTest:   condTest,
+		}
+	}
+	return client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL)
+}
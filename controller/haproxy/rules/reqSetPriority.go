@@ -0,0 +1,42 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ReqSetPriority sets the priority class, or the priority offset in milliseconds,
+// of requests so they are dequeued before other requests when backend servers are saturated.
+type ReqSetPriority struct {
+	Offset   bool
+	Value    int64
+	CondTest string
+}
+
+func (r ReqSetPriority) GetType() haproxy.RuleType {
+	return haproxy.REQ_SET_PRIORITY
+}
+
+func (r ReqSetPriority) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("request priority cannot be set in TCP mode")
+	}
+	httpRule := models.HTTPRequestRule{
+		Index: utils.PtrInt64(0),
+		Type:  "set-priority-class",
+		Expr:  fmt.Sprintf("int(%d)", r.Value),
+	}
+	if r.Offset {
+		httpRule.Type = "set-priority-offset"
+	}
+	if r.CondTest != "" {
+		httpRule.Cond = "if"
+		httpRule.CondTest = r.CondTest
+	}
+	return client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL)
+}
@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ReqSpikeArrest delays, instead of denying, the requests of clients exceeding
+// the request rate with the "spike_arrest" Lua action.
+type ReqSpikeArrest struct {
+	TableName   string
+	TablePeriod *int64
+	ReqsLimit   int64
+	Delay       int64
+}
+
+func (r ReqSpikeArrest) GetType() haproxy.RuleType {
+	return haproxy.REQ_SPIKE_ARREST
+}
+
+func (r ReqSpikeArrest) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("spike arrest cannot be configured in TCP mode")
+	}
+	// Create tracking table.
+	if _, err := client.BackendGet(r.TableName); err != nil {
+		err = client.BackendCreate(models.Backend{
+			Name: r.TableName,
+			StickTable: &models.BackendStickTable{
+				Peers: "localinstance",
+				Type:  "ip",
+				Size:  utils.PtrInt64(100000),
+				Store: fmt.Sprintf("http_req_rate(%d)", *r.TablePeriod),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// Create rules, sc2 is used to not conflict with rate limiting on sc0 and connection limiting on sc1.
+	// Rules are inserted at index 0 so tracking is created last to be evaluated first.
+	httpRule := models.HTTPRequestRule{
+		Index:     utils.PtrInt64(0),
+		Type:      "lua",
+		LuaAction: "spike_arrest",
+		LuaParams: strconv.FormatInt(r.Delay, 10),
+		Cond:      "if",
+		CondTest:  fmt.Sprintf("{ sc2_http_req_rate(%s) gt %d }", r.TableName, r.ReqsLimit),
+	}
+	if err := client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL); err != nil {
+		return err
+	}
+	httpRule = models.HTTPRequestRule{
+		Index:         utils.PtrInt64(0),
+		Type:          "track-sc2",
+		TrackSc2Key:   "src",
+		TrackSc2Table: r.TableName,
+	}
+	return client.FrontendHTTPRequestRuleCreate(frontend.Name, httpRule, ingressACL)
+}
@@ -14,6 +14,8 @@ type ReqTrack struct {
 	TableName   string
 	TablePeriod *int64
 	TableSize   *int64
+	// TableExpire, when set, removes entries not updated for this time in milliseconds
+	TableExpire *int64
 	TrackKey    string
 }
 
@@ -27,13 +29,20 @@ func (r ReqTrack) Create(client api.HAProxyClient, frontend *models.Frontend, in
 	}
 	// Create tracking table.
 	if _, err := client.BackendGet(r.TableName); err != nil {
+		// Source IP is tracked with an ip table, any other
+		// sample (header, query parameter...) with a string table.
+		tableType := "ip"
+		if r.TrackKey != "src" {
+			tableType = "string"
+		}
 		err = client.BackendCreate(models.Backend{
 			Name: r.TableName,
 			StickTable: &models.BackendStickTable{
-				Peers: "localinstance",
-				Type:  "ip",
-				Size:  r.TableSize,
-				Store: fmt.Sprintf("http_req_rate(%d)", *r.TablePeriod),
+				Peers:  "localinstance",
+				Type:   tableType,
+				Size:   r.TableSize,
+				Expire: r.TableExpire,
+				Store:  fmt.Sprintf("http_req_rate(%d)", *r.TablePeriod),
 			},
 		})
 		if err != nil {
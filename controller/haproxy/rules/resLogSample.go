@@ -0,0 +1,35 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ResLogSample only logs the given percentage of normal responses (non 5xx),
+// error responses as well as responses generated by HAProxy being always logged.
+type ResLogSample struct {
+	Percent int64
+}
+
+func (r ResLogSample) GetType() haproxy.RuleType {
+	return haproxy.RES_LOG_SAMPLE
+}
+
+func (r ResLogSample) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return nil
+	}
+	httpRule := models.HTTPResponseRule{
+		Index:    utils.PtrInt64(0),
+		Type:     "set-log-level",
+		LogLevel: "silent",
+		Cond:     "if",
+		CondTest: fmt.Sprintf("{ status lt 500 } { rand(100) ge %d }", r.Percent),
+	}
+	return client.FrontendHTTPResponseRuleCreate(frontend.Name, httpRule, ingressACL)
+}
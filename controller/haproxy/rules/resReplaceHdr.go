@@ -0,0 +1,36 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ResReplaceHdr rewrites the value of a response header matching HdrMatch with HdrFormat.
+type ResReplaceHdr struct {
+	HdrName   string
+	HdrMatch  string
+	HdrFormat string
+}
+
+func (r ResReplaceHdr) GetType() haproxy.RuleType {
+	return haproxy.RES_REPLACE_HEADER
+}
+
+func (r ResReplaceHdr) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("HTTP headers cannot be replaced in TCP mode")
+	}
+	httpRule := models.HTTPResponseRule{
+		Index:     utils.PtrInt64(0),
+		Type:      "replace-header",
+		HdrName:   r.HdrName,
+		HdrMatch:  r.HdrMatch,
+		HdrFormat: r.HdrFormat,
+	}
+	return client.FrontendHTTPResponseRuleCreate(frontend.Name, httpRule, ingressACL)
+}
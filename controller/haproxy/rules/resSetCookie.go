@@ -0,0 +1,40 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ResSetCookie appends a Set-Cookie header to responses,
+// unlike SetHdr it keeps Set-Cookie headers sent by backends.
+type ResSetCookie struct {
+	Name     string
+	Value    string
+	CondTest string
+}
+
+func (r ResSetCookie) GetType() haproxy.RuleType {
+	return haproxy.RES_SET_COOKIE
+}
+
+func (r ResSetCookie) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("HTTP cookies cannot be set in TCP mode")
+	}
+	httpRule := models.HTTPResponseRule{
+		Index:     utils.PtrInt64(0),
+		Type:      "add-header",
+		HdrName:   "Set-Cookie",
+		HdrFormat: fmt.Sprintf("\"%s=%s; Path=/\"", r.Name, r.Value),
+	}
+	if r.CondTest != "" {
+		httpRule.Cond = "if"
+		httpRule.CondTest = r.CondTest
+	}
+	return client.FrontendHTTPResponseRuleCreate(frontend.Name, httpRule, ingressACL)
+}
@@ -0,0 +1,45 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// ResSetStatus rewrites the status of the responses having the BackendStatus status.
+// When a File is set, the response is replaced by its content with the "status_reply" Lua action.
+type ResSetStatus struct {
+	BackendStatus int64
+	Status        int64
+	ContentType   string
+	File          string
+}
+
+func (r ResSetStatus) GetType() haproxy.RuleType {
+	return haproxy.RES_SET_STATUS
+}
+
+func (r ResSetStatus) Create(client api.HAProxyClient, frontend *models.Frontend, ingressACL string) error {
+	if frontend.Mode == "tcp" {
+		return fmt.Errorf("HTTP status cannot be rewritten in TCP mode")
+	}
+	httpRule := models.HTTPResponseRule{
+		Index:    utils.PtrInt64(0),
+		Type:     "set-status",
+		Status:   r.Status,
+		Cond:     "if",
+		CondTest: fmt.Sprintf("{ status %d }", r.BackendStatus),
+	}
+	if r.File != "" {
+		httpRule.Type = "lua"
+		httpRule.Status = 0
+		httpRule.LuaAction = "status_reply"
+		httpRule.LuaParams = fmt.Sprintf("%s %s %s", strconv.FormatInt(r.Status, 10), r.ContentType, r.File)
+	}
+	return client.FrontendHTTPResponseRuleCreate(frontend.Name, httpRule, ingressACL)
+}
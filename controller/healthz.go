@@ -0,0 +1,139 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// healthzSyncFailedACL returns the ACL file matching all clients while the last configuration apply failed
+func (c *HAProxyController) healthzSyncFailedACL() string {
+	return filepath.Join(c.Cfg.Env.StateDir, "healthz-sync-failed")
+}
+
+// healthzDrainACL returns the ACL file matching all clients while the instance is drained via the admin API
+func (c *HAProxyController) healthzDrainACL() string {
+	return filepath.Join(c.Cfg.Env.StateDir, "healthz-drain")
+}
+
+// healthzMonitorFail returns the condition of the built-in healthz service failure,
+// made of the "healthz-fail-condition" ConfigMap key, of the last configuration apply outcome
+// when "healthz-fail-on-sync-error" is enabled and of the drain mode of the admin API.
+func (c *HAProxyController) healthzMonitorFail() *models.MonitorFail {
+	var conds []string
+	if c.OSArgs.HealthzFailOnSyncError {
+		conds = append(conds, fmt.Sprintf("{ src -f %s }", c.healthzSyncFailedACL()))
+	}
+	if c.OSArgs.AdminPort != 0 {
+		conds = append(conds, fmt.Sprintf("{ src -f %s }", c.healthzDrainACL()))
+	}
+	if cond := annotations.GetValue("healthz-fail-condition", c.Store.ConfigMaps.Main.Annotations); cond != "" {
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return nil
+	}
+	cond := models.MonitorFailCondIf
+	condTest := strings.Join(conds, " || ")
+	return &models.MonitorFail{Cond: &cond, CondTest: &condTest}
+}
+
+// handleFrontendsHealthz answers, when "frontends-healthz-path" is set, health checks on each port of the HTTP
+// and HTTPS frontends with monitor-uri, so that external load balancers check each exposed port independently.
+// They fail with the same condition as the built-in healthz service.
+func (c *HAProxyController) handleFrontendsHealthz() (reload bool) {
+	var monitorURI models.MonitorURI
+	var monitorFail *models.MonitorFail
+	if c.OSArgs.FrontendsHealthzPath != "" {
+		monitorURI = models.MonitorURI(c.OSArgs.FrontendsHealthzPath)
+		monitorFail = c.healthzMonitorFail()
+	}
+	for _, name := range []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS} {
+		frontend, err := c.Client.FrontendGet(name)
+		if err != nil {
+			// disabled frontend
+			continue
+		}
+		if frontend.MonitorURI == monitorURI && reflect.DeepEqual(frontend.MonitorFail, monitorFail) {
+			continue
+		}
+		frontend.MonitorURI = monitorURI
+		frontend.MonitorFail = monitorFail
+		if err = c.Client.FrontendEdit(frontend); err != nil {
+			logger.Error(err)
+			continue
+		}
+		utils.ReloadRequired("healthz_updated", name, "monitor-uri")
+		reload = true
+	}
+	return reload
+}
+
+// setHealthzSyncFailed makes the built-in healthz service fail, without reload,
+// while the last configuration apply failed.
+func (c *HAProxyController) setHealthzSyncFailed(failed bool) {
+	if !c.OSArgs.HealthzFailOnSyncError || failed == c.healthzFailed {
+		return
+	}
+	if err := c.setHealthzACL(c.healthzSyncFailedACL(), failed); err != nil {
+		logger.Error(err)
+		return
+	}
+	c.healthzFailed = failed
+	if failed {
+		logger.Warning("healthz: reporting failure until HAProxy configuration is applied")
+	} else {
+		logger.Info("healthz: HAProxy configuration applied, reporting success")
+	}
+}
+
+// setHealthzDrain makes the built-in healthz service fail, without reload, while the instance is drained
+func (c *HAProxyController) setHealthzDrain(drain bool) {
+	if err := c.setHealthzACL(c.healthzDrainACL(), drain); err != nil {
+		logger.Error(err)
+		return
+	}
+	if drain {
+		logger.Warning("healthz: reporting failure while the instance is drained")
+	} else {
+		logger.Info("healthz: instance no longer drained")
+	}
+}
+
+// setHealthzACL sets an ACL of healthz failure conditions to match all clients or none
+func (c *HAProxyController) setHealthzACL(file string, match bool) error {
+	var entries []string
+	if match {
+		entries = []string{"0.0.0.0/0", "::/0"}
+	}
+	// file content is used when HAProxy is reloaded
+	if err := ioutil.WriteFile(file, []byte(strings.Join(entries, "\n")+"\n"), 0644); err != nil { //nolint:gosec
+		return err
+	}
+	if err := c.Client.SetACLContent(file, entries); err != nil {
+		// the ACL is not loaded yet, e.g. the first sync failed before healthz was configured
+		logger.Debugf("healthz: runtime update of '%s' failed: %s", file, err)
+	}
+	return nil
+}
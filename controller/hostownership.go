@@ -0,0 +1,154 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// checkHostOwnership returns the ingresses and the BlueGreen and TrafficSplit CRs, by routeKey,
+// claiming a host owned by other namespaces according to the host ownership ConfigMap, along with the reason.
+// The ConfigMap lists, for each namespace, the hosts it owns: exact hosts or wildcard
+// patterns like '*.team-a.example.com' matching any subdomain. Hosts not owned by any
+// namespace can be claimed by all of them. Wildcard hosts, empty hosts and default backends
+// serve hosts of other namespaces, they are only allowed when all owned hosts they cover
+// are owned by the namespace of the resource.
+func (c *HAProxyController) checkHostOwnership() map[string]string {
+	cm := c.Store.ConfigMaps.HostOwnership
+	if cm == nil || !cm.Loaded {
+		return nil
+	}
+	rejected := make(map[string]string)
+	for _, namespace := range c.Store.Namespaces {
+		if !namespace.Relevant {
+			continue
+		}
+		for _, ingress := range namespace.Ingresses {
+			if ingress.Status == DELETED {
+				continue
+			}
+			hosts := make([]string, 0, len(ingress.Rules)+1)
+			for _, rule := range ingress.Rules {
+				hosts = append(hosts, rule.Host)
+			}
+			if ingress.DefaultBackend != nil && !c.defaultBackendPerHost(ingress) {
+				hosts = append(hosts, "")
+			}
+			checkHostsOwned(cm.Annotations, ingress.Ref(), hosts, rejected)
+		}
+	}
+	for _, bg := range c.Store.CR.BlueGreens {
+		if ns, ok := c.Store.Namespaces[bg.Namespace]; ok && ns.Relevant {
+			checkHostsOwned(cm.Annotations, bg.Ref(), []string{bg.Host}, rejected)
+		}
+	}
+	for _, ts := range c.Store.CR.TrafficSplits {
+		if ns, ok := c.Store.Namespaces[ts.Namespace]; ok && ns.Relevant {
+			checkHostsOwned(cm.Annotations, ts.Ref(), []string{ts.Host}, rejected)
+		}
+	}
+	return rejected
+}
+
+// checkHostsOwned adds the resource to rejected when one of its hosts is owned by other namespaces
+func checkHostsOwned(owned map[string]string, ref utils.ResourceRef, hosts []string, rejected map[string]string) {
+	for _, host := range hosts {
+		owners := hostOwners(owned, host)
+		if hostOwned(host, owners, ref.Namespace) {
+			continue
+		}
+		claim := fmt.Sprintf("host '%s'", host)
+		if host == "" {
+			claim = "default backend"
+		}
+		msg := fmt.Sprintf("%s ignored: %s overlaps hosts owned by namespaces '%s'", strings.ToLower(ref.Kind), claim, strings.Join(owners, ","))
+		rejected[routeKey(ref)] = msg
+		logger.Errorf("%s '%s/%s': %s", ref.Kind, ref.Namespace, ref.Name, msg)
+		utils.WarningEvent(ref, "HostNotOwned", msg)
+		return
+	}
+}
+
+// hostOwnershipRejected checks if the resource claims a host owned by other namespaces
+func (c *HAProxyController) hostOwnershipRejected(ref utils.ResourceRef) (msg string, rejected bool) {
+	msg, rejected = c.hostOwnershipViolations[routeKey(ref)]
+	return
+}
+
+// routeKey identifies an ingress or a CR routing host/paths, as kind/namespace/name
+func routeKey(ref utils.ResourceRef) string {
+	return ref.Kind + "/" + ref.Namespace + "/" + ref.Name
+}
+
+// hostOwned checks if the namespace can claim the host: an exact host must be owned by no namespace
+// or by the namespace, a wildcard or empty host must not overlap hosts owned by other namespaces.
+func hostOwned(host string, owners []string, namespace string) bool {
+	if len(owners) == 0 {
+		return true
+	}
+	wildcard := host == "" || strings.HasPrefix(host, "*.")
+	for _, owner := range owners {
+		if owner == namespace && !wildcard {
+			return true
+		}
+		if owner != namespace && wildcard {
+			return false
+		}
+	}
+	return wildcard
+}
+
+// hostOwners returns the sorted namespaces owning hosts that overlap the host.
+// An empty host, serving any host, overlaps all owned hosts.
+func hostOwners(owners map[string]string, host string) (namespaces []string) {
+	for namespace, patterns := range owners {
+		for _, pattern := range strings.FieldsFunc(patterns, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+			if hostOverlaps(pattern, host) {
+				namespaces = append(namespaces, namespace)
+				break
+			}
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// hostOverlaps checks if an exact host or a wildcard pattern and a host, possibly a wildcard or empty,
+// can match a same request host
+func hostOverlaps(pattern, host string) bool {
+	switch {
+	case host == "":
+		return true
+	case strings.HasPrefix(host, "*."):
+		if strings.HasPrefix(pattern, "*.") {
+			return hostMatches(pattern, host[2:]) || hostMatches(host, pattern[2:]) || pattern == host
+		}
+		return hostMatches(host, pattern)
+	default:
+		return hostMatches(pattern, host)
+	}
+}
+
+// hostMatches checks if the host matches an exact host or a wildcard pattern
+func hostMatches(pattern, host string) bool {
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return pattern == host
+}
@@ -15,7 +15,12 @@
 package controller
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/haproxytech/client-native/v2/models"
 
@@ -28,6 +33,17 @@ import (
 	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
+// alpnRegex matches an ALPN advertisement: comma separated protocol names like h2,http/1.1
+var alpnRegex = regexp.MustCompile(`^[A-Za-z0-9._/-]+(,[A-Za-z0-9._/-]+)*$`)
+
+// canaryHeader matches an HTTP header name (RFC 7230 token) without the characters
+// HAProxy config parsing gives a meaning to: '#', '$' and single quote
+var canaryHeader = regexp.MustCompile("^[A-Za-z0-9!%&*+.^_`|~-]+$")
+
+// canaryHeaderValue matches a header value usable as an ACL pattern: printable ASCII without
+// whitespace, braces, quotes, backslash, '#' and '$'
+var canaryHeaderValue = regexp.MustCompile(`^[!%&()*+,./0-9:;<=>?@A-Z\[\]^_a-z|~-]+$`)
+
 // igClassIsSupported verifies if the IngressClass matches the ControllerClass
 // and in such case returns true otherwise false
 //
@@ -58,11 +74,37 @@ func (c *HAProxyController) igClassIsSupported(ingress *store.Ingress) bool {
 	if igClassAnn == c.OSArgs.IngressClass {
 		return true
 	}
+	if c.OSArgs.InternalIngressClass != "" && igClassAnn == c.OSArgs.InternalIngressClass {
+		return true
+	}
 	return false
 }
 
+// ingressInternal checks if the ingress is only exposed via the internal binds,
+// its class being provided by the ingress.class annotation or the IngressClass name.
+func (c *HAProxyController) ingressInternal(ingress *store.Ingress) bool {
+	if c.OSArgs.InternalIngressClass == "" {
+		return false
+	}
+	if igClassAnn := annotations.GetValue("ingress.class", ingress.Annotations); igClassAnn != "" {
+		return igClassAnn == c.OSArgs.InternalIngressClass
+	}
+	return ingress.Class == c.OSArgs.InternalIngressClass
+}
+
+// publishService returns the service whose addresses are published in the status of
+// internal or external ingresses.
+func (c *HAProxyController) publishService(internal bool) *utils.NamespaceValue {
+	if internal {
+		return c.InternalPublishService
+	}
+	return c.PublishService
+}
+
 func (c *HAProxyController) handleIngressPath(ingress *store.Ingress, host string, path *store.IngressPath, ruleIDs []haproxy.RuleID) (reload bool, err error) {
-	sslPassthrough := c.sslPassthroughEnabled(*ingress, path)
+	internal := c.ingressInternal(ingress)
+	// SSL passthrough is only available via the main binds
+	sslPassthrough := !internal && c.sslPassthroughEnabled(*ingress, path)
 	svc, err := service.NewCtx(c.Store, ingress, path, sslPassthrough)
 	if err != nil {
 		return
@@ -83,17 +125,28 @@ func (c *HAProxyController) handleIngressPath(ingress *store.Ingress, host strin
 		HAProxyRules:   ruleIDs,
 		BackendName:    backendName,
 		SSLPassthrough: sslPassthrough,
+		Internal:       internal,
 	}
-	routeACLAnn := annotations.GetValue("route-acl", svc.GetService().Annotations)
-	if routeACLAnn == "" {
+	routeACLAnn := annotations.GetValue("route-acl", svc.GetServiceAnnotations())
+	if c.canaryEnabled(ingress) {
+		routeReload, err = c.handleCanaryRoute(ingress, ingRoute)
+	} else if routeACLAnn == "" {
 		if _, ok := route.CustomRoutes[backendName]; ok {
 			delete(route.CustomRoutes, backendName)
 			logger.Debugf("Custom Route to backend '%s' deleted, reload required", backendName)
+			utils.ReloadRequired("route_deleted", backendName, "")
 			routeReload = true
 		}
 		err = route.AddHostPathRoute(ingRoute, c.Cfg.MapFiles)
 	} else {
+		ingRoute.StickyCookie = annotations.GetValue("route-acl-cookie", svc.GetServiceAnnotations())
 		routeReload, err = route.AddCustomRoute(ingRoute, routeACLAnn, c.Client)
+		if err == nil && ingRoute.StickyCookie != "" {
+			for _, rule := range route.StickyCookieRules(ingRoute) {
+				logger.Error(c.Cfg.HAProxyRules.AddRule(rule, false, c.Cfg.FrontHTTP))
+				logger.Error(c.Cfg.HAProxyRules.AddRule(rule, false, c.Cfg.FrontHTTPS))
+			}
+		}
 	}
 	if err != nil {
 		return
@@ -104,6 +157,112 @@ func (c *HAProxyController) handleIngressPath(ingress *store.Ingress, host strin
 	return backendReload || endpointsReload || routeReload, err
 }
 
+// canaryEnabled checks if the ingress is a canary of another ingress having the same hosts and paths
+func (c *HAProxyController) canaryEnabled(ingress *store.Ingress) bool {
+	annCanary := annotations.GetValue("canary", ingress.Annotations)
+	if annCanary == "" {
+		return false
+	}
+	enabled, err := utils.GetBoolValue(annCanary, "canary")
+	if err != nil {
+		logger.Errorf("canary annotation: %s", err)
+		return false
+	}
+	return enabled
+}
+
+// handleCanaryRoute routes to the canary backend the requests selected by the canary annotations
+// (as set by progressive delivery controllers), the other ones being left to the main ingress.
+func (c *HAProxyController) handleCanaryRoute(ingress *store.Ingress, ingRoute route.Route) (reload bool, err error) {
+	var conds []string
+	header := annotations.GetValue("canary-by-header", ingress.Annotations)
+	headerValue := annotations.GetValue("canary-by-header-value", ingress.Annotations)
+	if header != "" && !canaryHeader.MatchString(header) {
+		return false, fmt.Errorf("canary-by-header: invalid header name '%s'", header)
+	}
+	if headerValue != "" && !canaryHeaderValue.MatchString(headerValue) {
+		return false, fmt.Errorf("canary-by-header-value: invalid value '%s'", headerValue)
+	}
+	if header != "" {
+		if headerValue == "" {
+			conds = append(conds, fmt.Sprintf("{ req.hdr(%s) -m str always }", header))
+		} else {
+			conds = append(conds, fmt.Sprintf("{ req.hdr(%s) -m str %s }", header, headerValue))
+		}
+	}
+	weight, total, err := canaryWeight(ingress)
+	if err != nil {
+		return
+	}
+	if weight > 0 {
+		cond := fmt.Sprintf("{ rand(%d) lt %d }", total, weight)
+		if header != "" && headerValue == "" {
+			cond = fmt.Sprintf("!{ req.hdr(%s) -m str never } %s", header, cond)
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		if _, ok := route.CustomRoutes[ingRoute.BackendName]; ok {
+			delete(route.CustomRoutes, ingRoute.BackendName)
+			logger.Debugf("Custom Route to backend '%s' deleted, reload required", ingRoute.BackendName)
+			utils.ReloadRequired("route_deleted", ingRoute.BackendName, "")
+			reload = true
+		}
+		return
+	}
+	// The canary decision is made once per request so the weight is applied
+	// regardless of how many rules the route has.
+	varName := "canary_" + utils.Hash([]byte(ingRoute.BackendName))
+	for _, frontend := range []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS} {
+		logger.Error(c.Cfg.HAProxyRules.AddRule(rules.ReqSetVar{
+			Name:       varName,
+			Scope:      "txn",
+			Expression: "bool(true)",
+			CondTest:   strings.Join(conds, " || "),
+		}, false, frontend))
+	}
+	return route.AddCustomRoute(ingRoute, fmt.Sprintf("var(txn.%s) -m bool", varName), c.Client)
+}
+
+// canaryWeight returns the weight of a canary ingress and the total weight it is relative to
+func canaryWeight(ingress *store.Ingress) (weight, total int64, err error) {
+	total = 100
+	if annTotal := annotations.GetValue("canary-weight-total", ingress.Annotations); annTotal != "" {
+		total, err = strconv.ParseInt(annTotal, 10, 64)
+		if err != nil || total <= 0 {
+			return 0, 0, fmt.Errorf("canary-weight-total: invalid value '%s'", annTotal)
+		}
+	}
+	annWeight := annotations.GetValue("canary-weight", ingress.Annotations)
+	if annWeight == "" {
+		return 0, total, nil
+	}
+	weight, err = strconv.ParseInt(annWeight, 10, 64)
+	if err != nil || weight < 0 || weight > total {
+		return 0, 0, fmt.Errorf("canary-weight: invalid value '%s'", annWeight)
+	}
+	return weight, total, nil
+}
+
+// handleServiceBackend creates the backend and servers of the service referenced by path,
+// routing to that backend is left to the caller.
+func (c *HAProxyController) handleServiceBackend(ingress *store.Ingress, path *store.IngressPath) (reload bool, backendName string, err error) {
+	svc, err := service.NewCtx(c.Store, ingress, path, false)
+	if err != nil {
+		return
+	}
+	if svc.GetStatus() == DELETED {
+		return reload, backendName, fmt.Errorf("service '%s' deleted", path.SvcName)
+	}
+	reload, backendName, err = svc.HandleBackend(c.Client, c.Store)
+	if err != nil {
+		return
+	}
+	c.Cfg.ActiveBackends[backendName] = struct{}{}
+	reload = svc.HandleEndpoints(c.Client, c.Store, c.Cfg.Certificates) || reload
+	return reload, backendName, nil
+}
+
 func (c *HAProxyController) setDefaultService(ingress *store.Ingress, frontends []string) (reload bool, err error) {
 	var frontend models.Frontend
 	var ftReload bool
@@ -139,6 +298,7 @@ func (c *HAProxyController) setDefaultService(ingress *store.Ingress, frontends
 			}
 			ftReload = true
 			logger.Debugf("Setting '%s' default backend to '%s'", frontendName, backendName)
+			utils.ReloadRequired("default_backend_updated", frontendName, backendName)
 		}
 	}
 	c.Cfg.ActiveBackends[backendName] = struct{}{}
@@ -147,6 +307,56 @@ func (c *HAProxyController) setDefaultService(ingress *store.Ingress, frontends
 	return reload, err
 }
 
+// defaultBackendPerHost checks if the ingress default backend is only used for the ingress hosts
+func (c *HAProxyController) defaultBackendPerHost(ingress *store.Ingress) bool {
+	if ingress.DefaultBackend == nil || len(ingress.Rules) == 0 {
+		return false
+	}
+	annPerHost := annotations.GetValue("default-backend-per-host", ingress.Annotations)
+	if annPerHost == "" {
+		return false
+	}
+	enabled, err := utils.GetBoolValue(annPerHost, "default-backend-per-host")
+	if err != nil {
+		logger.Errorf("default-backend-per-host annotation: %s", err)
+		return false
+	}
+	return enabled
+}
+
+// handleHostDefaultBackend routes the requests of the ingress hosts which do not match
+// any ingress path to the ingress default backend, instead of the global default backend.
+func (c *HAProxyController) handleHostDefaultBackend(ingress *store.Ingress, ruleIDs []haproxy.RuleID) (reload bool, err error) {
+	svc, err := service.NewCtx(c.Store, ingress, ingress.DefaultBackend, false)
+	if err != nil {
+		return
+	}
+	if svc.GetStatus() == DELETED {
+		return
+	}
+	backendReload, backendName, err := svc.HandleBackend(c.Client, c.Store)
+	if err != nil {
+		return
+	}
+	for _, rule := range ingress.Rules {
+		if rule.Host == "" || rule.Status == DELETED {
+			continue
+		}
+		err = route.AddHostDefaultRoute(route.Route{
+			Host:         rule.Host,
+			HAProxyRules: ruleIDs,
+			BackendName:  backendName,
+			Internal:     c.ingressInternal(ingress),
+		}, c.Cfg.MapFiles)
+		if err != nil {
+			return
+		}
+	}
+	c.Cfg.ActiveBackends[backendName] = struct{}{}
+	endpointsReload := svc.HandleEndpoints(c.Client, c.Store, c.Cfg.Certificates)
+	return backendReload || endpointsReload, err
+}
+
 func (c *HAProxyController) sslPassthroughEnabled(ingress store.Ingress, path *store.IngressPath) bool {
 	var annSSLPassthrough string
 	var service *store.Service
@@ -174,6 +384,107 @@ func (c *HAProxyController) sslPassthroughEnabled(ingress store.Ingress, path *s
 	return false
 }
 
+// crossNsSecretAllowed checks, when cross namespace secrets are restricted, that the namespace
+// of the TLS secret allows the ingress namespace via its "tls-secret-allowed-namespaces" annotation.
+func (c *HAProxyController) crossNsSecretAllowed(ingress *store.Ingress, secretPath string) bool {
+	parts := strings.Split(secretPath, "/")
+	if !c.OSArgs.RestrictCrossNsSecrets || len(parts) < 2 || parts[0] == ingress.Namespace {
+		return true
+	}
+	namespace, ok := c.Store.Namespaces[parts[0]]
+	if !ok {
+		return false
+	}
+	for _, allowed := range strings.Split(annotations.GetValue("tls-secret-allowed-namespaces", namespace.Annotations), ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "*" || allowed == ingress.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// ingressALPN returns the ALPN advertisement set by the tls-alpn annotation of the ingress
+// for its TLS hosts, in place of the one of the https binds.
+func (c *HAProxyController) ingressALPN(ingress *store.Ingress) (alpn string, ok bool) {
+	alpn, ok = ingress.Annotations["tls-alpn"]
+	if !ok {
+		return
+	}
+	if !alpnRegex.MatchString(alpn) {
+		err := fmt.Errorf("invalid ALPN '%s': comma separated protocol names expected", alpn)
+		logger.Errorf("Ingress '%s/%s': annotation tls-alpn: %s", ingress.Namespace, ingress.Name, err)
+		annotations.InvalidAnnotationEvent(ingress.Ref(), "tls-alpn", err)
+		return "", false
+	}
+	return alpn, true
+}
+
+// handleCrtListEntry serves the TLS secret of an ingress host via the frontend crt-list,
+// with its own ALPN advertisement.
+func (c *HAProxyController) handleCrtListEntry(ingress *store.Ingress, tls *store.IngressTLS, alpn string) {
+	certPath, err := c.Cfg.Certificates.HandleTLSSecret(c.Store, haproxy.SecretCtx{
+		DefaultNS:  ingress.Namespace,
+		SecretPath: tls.SecretName,
+		SecretType: haproxy.FT_CRTLIST_CERT,
+	})
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	c.Cfg.Certificates.AddCrtListEntry(certPath, tls.Host, alpn)
+}
+
+// handleWildcardCerts loads, for ingress hosts without TLS secret, the first matching
+// certificate among the TLS secrets of the wildcard certificates namespace.
+// HAProxy then picks it via SNI instead of falling back to the default certificate.
+func (c *HAProxyController) handleWildcardCerts(ingress *store.Ingress) {
+	namespace, ok := c.Store.Namespaces[c.OSArgs.WildcardCertsNamespace]
+	if !ok {
+		return
+	}
+	for _, rule := range ingress.Rules {
+		if rule.Host == "" || rule.Status == DELETED {
+			continue
+		}
+		if tls, ok := ingress.TLS[rule.Host]; ok && tls.Status != DELETED {
+			continue
+		}
+		for _, secret := range namespace.Secret {
+			if secret.Status == DELETED || !certMatchesHost(secret.Data["tls.crt"], rule.Host) {
+				continue
+			}
+			_, err := c.Cfg.Certificates.HandleTLSSecret(c.Store, haproxy.SecretCtx{
+				DefaultNS:  namespace.Name,
+				SecretPath: secret.Name,
+				SecretType: haproxy.FT_CERT,
+			})
+			logger.Error(err)
+			break
+		}
+	}
+}
+
+func certMatchesHost(crt []byte, host string) bool {
+	block, _ := pem.Decode(crt)
+	if block == nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(host, "*.") {
+		for _, name := range cert.DNSNames {
+			if name == host {
+				return true
+			}
+		}
+		return false
+	}
+	return cert.VerifyHostname(host) == nil
+}
+
 // handleIngressAnnotations processes ingress annotations to create HAProxy Rules and provide
 // corresponding list of RuleIDs.
 // If Ingress Annotations are at the ConfigMap scope, HAProxy Rules will be applied globally
@@ -183,44 +494,70 @@ func (c *HAProxyController) handleIngressAnnotations(ingress store.Ingress) []ha
 	var ingressRule bool
 	var annValue, annSource string
 	var annList map[string]string
+	var annRef utils.ResourceRef
 	if ingress.Equal(&store.Ingress{}) {
 		annSource = "ConfigMap"
 		annList = c.Store.ConfigMaps.Main.Annotations
+		annRef = c.Store.ConfigMaps.Main.Ref()
 		ingressRule = false
 	} else {
 		annSource = fmt.Sprintf("Ingress '%s/%s'", ingress.Namespace, ingress.Name)
 		annList = ingress.Annotations
+		annRef = ingress.Ref()
 		ingressRule = true
 	}
 	ids := []haproxy.RuleID{}
-	frontends := []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS}
 	result := haproxy.Rules{}
+	template := annotations.Template{Namespace: ingress.Namespace, Ingress: ingress.Name, K8s: c.Store}
 	for _, a := range annotations.GetFrontendAnnotations(ingress, &result, *c.Cfg.MapFiles, c.Store) {
-		annValue = annotations.GetValue(a.GetName(), annList)
-		err = a.Process(annValue)
+		annValue, err = template.GetValue(a.GetName(), annList)
+		if err == nil {
+			err = annotations.Process(a, annValue)
+		}
 		if err != nil {
 			logger.Errorf("%s: annotation %s: %s", annSource, a.GetName(), err)
+			annotations.InvalidAnnotationEvent(annRef, a.GetName(), err)
+		}
+	}
+	var priority int
+	if annValue = annotations.GetValue("rule-priority", annList); annValue != "" {
+		if priority, err = strconv.Atoi(annValue); err != nil {
+			err = fmt.Errorf("invalid rule-priority '%s': an integer is expected", annValue)
+			logger.Errorf("%s: annotation rule-priority: %s", annSource, err)
+			annotations.InvalidAnnotationEvent(annRef, "rule-priority", err)
 		}
 	}
 	for _, rule := range result {
+		frontends := []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS}
 		switch rule.GetType() {
 		case haproxy.REQ_REDIRECT:
 			redirRule := rule.(*rules.RequestRedirect)
 			if redirRule.SSLRedirect {
 				frontends = []string{c.Cfg.FrontHTTP}
-			} else {
-				frontends = []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS}
 			}
+		case haproxy.REQ_SNI_CHECK:
+			// SNI is only available on TLS connections
+			frontends = []string{c.Cfg.FrontHTTPS}
 		case haproxy.REQ_DENY, haproxy.REQ_CAPTURE:
 			if c.sslPassthroughEnabled(ingress, nil) {
-				frontends = []string{c.Cfg.FrontHTTP, c.Cfg.FrontSSL}
+				frontends = c.Cfg.ClientFrontends()
 			}
 		case haproxy.REQ_RATELIMIT:
 			limitRule := rule.(*rules.ReqRateLimit)
 			c.Cfg.RateLimitTables = append(c.Cfg.RateLimitTables, limitRule.TableName)
+		case haproxy.REQ_CONNLIMIT:
+			limitRule := rule.(*rules.ReqConnLimit)
+			c.Cfg.RateLimitTables = append(c.Cfg.RateLimitTables, limitRule.TableName)
+		case haproxy.REQ_SPIKE_ARREST:
+			arrestRule := rule.(*rules.ReqSpikeArrest)
+			c.Cfg.RateLimitTables = append(c.Cfg.RateLimitTables, arrestRule.TableName)
+		case haproxy.REQ_OIDC:
+			oidcRule := rule.(*rules.ReqOIDC)
+			c.Cfg.ActiveBackends[oidcRule.BackendName()] = struct{}{}
 		}
 		for _, frontend := range frontends {
 			logger.Error(c.Cfg.HAProxyRules.AddRule(rule, ingressRule, frontend))
+			c.Cfg.HAProxyRules.SetPriority(rule, frontend, priority)
 		}
 		ids = append(ids, haproxy.GetID(rule))
 	}
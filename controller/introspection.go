@@ -0,0 +1,151 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/admin"
+	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
+	"github.com/haproxytech/kubernetes-ingress/controller/service"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+// updateIntrospection replaces the state served by the admin API with the synced one,
+// it must be called outside of a configuration transaction.
+func (c *HAProxyController) updateIntrospection() {
+	var ingresses []admin.Ingress
+	backends := make(map[string][]admin.Backend)
+	seen := make(map[string]struct{})
+	for _, namespace := range c.Store.Namespaces {
+		if !namespace.Relevant {
+			continue
+		}
+		for _, ingress := range namespace.Ingresses {
+			if ingress.Status == DELETED {
+				continue
+			}
+			_, ownershipRejected := c.hostOwnershipRejected(ingress.Ref())
+			ing := admin.Ingress{
+				Namespace:   ingress.Namespace,
+				Name:        ingress.Name,
+				Class:       ingress.Class,
+				Ignored:     !c.igClassIsSupported(ingress) || ownershipRejected,
+				Paths:       []admin.IngressPath{},
+				Annotations: annotations.Resolve(ingress.Annotations, c.Store.ConfigMaps.Main.Annotations),
+			}
+			var paths []*store.IngressPath
+			var hosts []string
+			if ingress.DefaultBackend != nil {
+				paths = append(paths, ingress.DefaultBackend)
+				hosts = append(hosts, "")
+			}
+			for _, rule := range ingress.Rules {
+				for _, path := range rule.Paths {
+					paths = append(paths, path)
+					hosts = append(hosts, rule.Host)
+				}
+			}
+			for i, path := range paths {
+				if path.Status == DELETED {
+					continue
+				}
+				ingPath, backend := c.introspectPath(ingress, hosts[i], path)
+				ing.Paths = append(ing.Paths, ingPath)
+				if backend == nil {
+					continue
+				}
+				if _, ok := seen[backend.Name]; !ok {
+					seen[backend.Name] = struct{}{}
+					key := ingress.Namespace + "/" + path.SvcName
+					backends[key] = append(backends[key], *backend)
+				}
+			}
+			sort.Slice(ing.Paths, func(i, j int) bool {
+				if ing.Paths[i].Host != ing.Paths[j].Host {
+					return ing.Paths[i].Host < ing.Paths[j].Host
+				}
+				return ing.Paths[i].Path < ing.Paths[j].Path
+			})
+			ingresses = append(ingresses, ing)
+		}
+	}
+	sort.Slice(ingresses, func(i, j int) bool {
+		if ingresses[i].Namespace != ingresses[j].Namespace {
+			return ingresses[i].Namespace < ingresses[j].Namespace
+		}
+		return ingresses[i].Name < ingresses[j].Name
+	})
+	c.introspection.Update(ingresses, backends)
+}
+
+// introspectPath returns the admin API view of an ingress path and of its backend,
+// the backend is nil when the path is not served by an active backend.
+func (c *HAProxyController) introspectPath(ingress *store.Ingress, host string, path *store.IngressPath) (admin.IngressPath, *admin.Backend) {
+	ingPath := admin.IngressPath{
+		Host:           host,
+		Path:           path.Path,
+		PathType:       path.PathTypeMatch,
+		Service:        path.SvcName,
+		ServicePort:    path.SvcPortString,
+		DefaultBackend: path.IsDefaultBackend,
+	}
+	if path.SvcPortInt != 0 {
+		ingPath.ServicePort = strconv.FormatInt(path.SvcPortInt, 10)
+	}
+	if msg, rejected := c.hostOwnershipRejected(ingress.Ref()); rejected {
+		ingPath.Error = msg
+		return ingPath, nil
+	}
+	if msg, rejected := c.hostPathRejected(ingress.Ref(), host, path.Path); rejected && !path.IsDefaultBackend {
+		ingPath.Error = msg
+		return ingPath, nil
+	}
+	svc, err := service.NewCtx(c.Store, ingress, path, false)
+	if err != nil {
+		ingPath.Error = err.Error()
+		return ingPath, nil
+	}
+	backendName, err := svc.GetBackendName()
+	if err != nil {
+		ingPath.Error = err.Error()
+		return ingPath, nil
+	}
+	ingPath.Backend = backendName
+	if _, ok := c.Cfg.ActiveBackends[backendName]; !ok {
+		return ingPath, nil
+	}
+	backend := &admin.Backend{
+		Name:        backendName,
+		ServicePort: ingPath.ServicePort,
+		Servers:     []admin.BackendServer{},
+	}
+	if backend.Config, err = c.Client.BackendGet(backendName); err != nil {
+		ingPath.Error = err.Error()
+		return ingPath, nil
+	}
+	if endpoints, err := svc.GetEndpoints(c.Store); err == nil {
+		for _, srv := range endpoints.HAProxySrvs {
+			server := admin.BackendServer{Name: srv.Name, Address: srv.Address}
+			if srv.Address != "" {
+				server.Port = endpoints.AddrPort(srv.Address)
+				server.Pod = c.Store.PodName(ingress.Namespace, path.SvcName, srv.Address)
+			}
+			backend.Servers = append(backend.Servers, server)
+		}
+	}
+	return ingPath, backend
+}
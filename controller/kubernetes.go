@@ -16,8 +16,10 @@ package controller
 
 import (
 	"errors"
+	"sync"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
@@ -42,10 +44,19 @@ type K8s struct {
 	Logger                     utils.Logger
 	DisableServiceExternalName bool // CVE-2021-25740
 	RestConfig                 *rest.Config
+	// LocalNode is the node whose endpoints are only used when set, see "localnode-only" flag
+	LocalNode string
+	// NodeSelector selects the nodes whose endpoints are used when set, see "endpoints-node-selector" flag
+	NodeSelector labels.Selector
+	// NotReadyServers adds the addresses of pods waiting for their readiness gate, see "pod-readiness-gate" flag
+	NotReadyServers bool
+	nodes           map[string]bool
+	nodesMu         sync.Mutex
+	endpointsResync []func()
 }
 
 // GetKubernetesClient returns new client that communicates with k8s
-func GetKubernetesClient(disableServiceExternalName bool) (*K8s, error) {
+func GetKubernetesClient(disableServiceExternalName bool, qps float32, burst int) (*K8s, error) {
 	k8sLogger := utils.GetK8sAPILogger()
 	if !TRACE_API {
 		k8sLogger.SetLevel(utils.Info)
@@ -54,6 +65,8 @@ func GetKubernetesClient(disableServiceExternalName bool) (*K8s, error) {
 	if err != nil {
 		return nil, err
 	}
+	config.QPS = qps
+	config.Burst = burst
 	clientset, err := kubernetes.NewForConfig(config)
 	logger.Trace(config)
 	if err != nil {
@@ -82,9 +95,11 @@ func getKubeConfig(kubeconfig string) *rest.Config {
 }
 
 // GetRemoteKubernetesClient returns new client that communicates with k8s
-func GetRemoteKubernetesClient(kubeconfig string, disableServiceExternalName bool) (*K8s, error) {
+func GetRemoteKubernetesClient(kubeconfig string, disableServiceExternalName bool, qps float32, burst int) (*K8s, error) {
 	// create the clientset
 	restConfig := getKubeConfig(kubeconfig)
+	restConfig.QPS = qps
+	restConfig.Burst = burst
 	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		logger.Panic(err)
@@ -112,12 +127,13 @@ func (k *K8s) EventsNamespaces(channel chan SyncDataEvent, stop chan struct{}, i
 					status = DELETED
 				}
 				item := &store.Namespace{
-					Name:      data.GetName(),
-					Endpoints: make(map[string]*store.Endpoints),
-					Services:  make(map[string]*store.Service),
-					Ingresses: make(map[string]*store.Ingress),
-					Secret:    make(map[string]*store.Secret),
-					Status:    status,
+					Name:        data.GetName(),
+					Annotations: store.CopyAnnotations(data.ObjectMeta.Annotations),
+					Endpoints:   make(map[string]*store.Endpoints),
+					Services:    make(map[string]*store.Service),
+					Ingresses:   make(map[string]*store.Ingress),
+					Secret:      make(map[string]*store.Secret),
+					Status:      status,
 				}
 				k.Logger.Tracef("%s %s: %s", NAMESPACE, item.Status, item.Name)
 				channel <- SyncDataEvent{SyncType: NAMESPACE, Namespace: item.Name, Data: item}
@@ -153,14 +169,16 @@ func (k *K8s) EventsNamespaces(channel chan SyncDataEvent, stop chan struct{}, i
 				}
 				status := MODIFIED
 				item1 := &store.Namespace{
-					Name:   data1.GetName(),
-					Status: status,
+					Name:        data1.GetName(),
+					Annotations: store.CopyAnnotations(data1.ObjectMeta.Annotations),
+					Status:      status,
 				}
 				item2 := &store.Namespace{
-					Name:   data2.GetName(),
-					Status: status,
+					Name:        data2.GetName(),
+					Annotations: store.CopyAnnotations(data2.ObjectMeta.Annotations),
+					Status:      status,
 				}
-				if item1.Name == item2.Name {
+				if item1.Equal(item2) {
 					return
 				}
 				k.Logger.Tracef("%s %s: %s", SERVICE, item2.Status, item2.Name)
@@ -203,6 +221,13 @@ func (k *K8s) EventsEndpoints(channel chan SyncDataEvent, stop chan struct{}, in
 			channel <- SyncDataEvent{SyncType: ENDPOINTS, Namespace: item2.Namespace, Data: item2}
 		},
 	})
+	k.addEndpointsResync(func() {
+		for _, obj := range informer.GetStore().List() {
+			if item, err := k.convertToEndpoints(obj, MODIFIED); err == nil {
+				channel <- SyncDataEvent{SyncType: ENDPOINTS, Namespace: item.Namespace, Data: item}
+			}
+		}
+	})
 	go informer.Run(stop)
 }
 
@@ -212,13 +237,8 @@ func (k *K8s) convertToEndpoints(obj interface{}, status store.Status) (*store.E
 		k.Logger.Errorf("%s: Invalid data from k8s api, %s", ENDPOINTS, obj)
 		return nil, ErrIgnored
 	}
-	if data.GetNamespace() == "kube-system" {
-		if data.ObjectMeta.Name == "kube-controller-manager" ||
-			data.ObjectMeta.Name == "kube-scheduler" ||
-			data.ObjectMeta.Name == "kubernetes-dashboard" ||
-			data.ObjectMeta.Name == "kube-dns" {
-			return nil, ErrIgnored
-		}
+	if ignoredEndpoints(data.GetNamespace(), data.GetName()) {
+		return nil, ErrIgnored
 	}
 	if data.ObjectMeta.GetDeletionTimestamp() != nil {
 		// detect endpoints that are in terminating state
@@ -229,24 +249,102 @@ func (k *K8s) convertToEndpoints(obj interface{}, status store.Status) (*store.E
 		Service:   data.GetName(),
 		Ports:     make(map[string]*store.PortEndpoints),
 		Status:    status,
+		Pods:      make(map[string]string),
 	}
+	// pods resolving a named target port differently are in distinct subsets
+	ports := make(map[string]int64)
+	addrPorts := make(map[string]map[string]int64)
+	nodes := make(map[string]string)
+	notReady := make(map[string]struct{})
 	for _, subset := range data.Subsets {
+		addresses := subset.Addresses
+		if k.NotReadyServers {
+			addresses = append(append([]corev1.EndpointAddress{}, subset.Addresses...), subset.NotReadyAddresses...)
+		}
 		for _, port := range subset.Ports {
-			addresses := make(map[string]struct{})
-			for _, address := range subset.Addresses {
-				addresses[address.IP] = struct{}{}
+			if _, ok := addrPorts[port.Name]; !ok {
+				ports[port.Name] = int64(port.Port)
+				addrPorts[port.Name] = make(map[string]int64)
 			}
-			item.Ports[port.Name] = &store.PortEndpoints{
-				Port:        int64(port.Port),
-				AddrCount:   len(addresses),
-				AddrNew:     addresses,
-				HAProxySrvs: make([]*store.HAProxySrv, 0, len(addresses)),
+			for i, address := range addresses {
+				if i >= len(subset.Addresses) {
+					// pods waiting for their readiness gate
+					if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+						continue
+					}
+					notReady[address.IP] = struct{}{}
+				}
+				addrPorts[port.Name][address.IP] = int64(port.Port)
+				if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+					item.Pods[address.IP] = address.TargetRef.Name
+				}
+				if address.NodeName != nil {
+					nodes[address.IP] = *address.NodeName
+				}
 			}
 		}
 	}
+	for name, addresses := range addrPorts {
+		item.Ports[name] = k.newPortEndpoints(ports[name], addresses, nodes, notReady)
+	}
 	return item, nil
 }
 
+// newPortEndpoints returns the PortEndpoints of the addresses kept by nodeAddresses,
+// notReady are the addresses of pods waiting for their readiness gate.
+func (k *K8s) newPortEndpoints(port int64, addresses map[string]int64, nodes map[string]string, notReady map[string]struct{}) *store.PortEndpoints {
+	endpoints := store.NewPortEndpoints(port, k.nodeAddresses(addresses, nodes))
+	for addr := range endpoints.AddrNew {
+		if _, ok := notReady[addr]; ok {
+			if endpoints.AddrNotReady == nil {
+				endpoints.AddrNotReady = make(map[string]struct{})
+			}
+			endpoints.AddrNotReady[addr] = struct{}{}
+		}
+	}
+	return endpoints
+}
+
+// nodeAddresses returns the addresses of the nodes matching the "endpoints-node-selector", and, when only
+// endpoints of the local node are used, the ones of the local node or all of them when none is on the local node.
+// nodes are the node names by address, addresses without node are always kept.
+func (k *K8s) nodeAddresses(addresses map[string]int64, nodes map[string]string) map[string]int64 {
+	if k.NodeSelector != nil {
+		selected := make(map[string]int64, len(addresses))
+		for addr, port := range addresses {
+			if node, ok := nodes[addr]; !ok || k.nodeSelected(node) {
+				selected[addr] = port
+			}
+		}
+		addresses = selected
+	}
+	if k.LocalNode == "" {
+		return addresses
+	}
+	local := make(map[string]int64)
+	for addr, port := range addresses {
+		if nodes[addr] == k.LocalNode {
+			local[addr] = port
+		}
+	}
+	if len(local) == 0 {
+		return addresses
+	}
+	return local
+}
+
+// ignoredEndpoints returns true for endpoints of kube-system services frequently updated for leader election
+func ignoredEndpoints(namespace, service string) bool {
+	if namespace != "kube-system" {
+		return false
+	}
+	switch service {
+	case "kube-controller-manager", "kube-scheduler", "kubernetes-dashboard", "kube-dns":
+		return true
+	}
+	return false
+}
+
 func (k *K8s) EventsIngressClass(channel chan SyncDataEvent, stop chan struct{}, informer cache.SharedIndexInformer) {
 	informer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -343,7 +441,15 @@ func (k *K8s) EventsIngresses(channel chan SyncDataEvent, stop chan struct{}, in
 	go informer.Run(stop)
 }
 
-func (k *K8s) EventsServices(channel chan SyncDataEvent, ingChan chan ingstatus.SyncIngress, stop chan struct{}, informer cache.SharedIndexInformer, publishSvc *utils.NamespaceValue) {
+func (k *K8s) EventsServices(channel chan SyncDataEvent, ingChan chan ingstatus.SyncIngress, stop chan struct{}, informer cache.SharedIndexInformer, publishSvc, internalPublishSvc *utils.NamespaceValue) {
+	syncStatus := func(data *corev1.Service) {
+		if publishSvc != nil && publishSvc.Namespace == data.Namespace && publishSvc.Name == data.Name {
+			ingChan <- ingstatus.SyncIngress{Service: data}
+		}
+		if internalPublishSvc != nil && internalPublishSvc.Namespace == data.Namespace && internalPublishSvc.Name == data.Name {
+			ingChan <- ingstatus.SyncIngress{Service: data, Internal: true}
+		}
+	}
 	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			data, ok := obj.(*corev1.Service)
@@ -361,27 +467,28 @@ func (k *K8s) EventsServices(channel chan SyncDataEvent, ingChan chan ingstatus.
 				status = DELETED
 			}
 			item := &store.Service{
-				Namespace:   data.GetNamespace(),
-				Name:        data.GetName(),
-				Annotations: store.CopyAnnotations(data.ObjectMeta.Annotations),
-				Ports:       []store.ServicePort{},
-				Status:      status,
+				Namespace:             data.GetNamespace(),
+				Name:                  data.GetName(),
+				UID:                   string(data.GetUID()),
+				Annotations:           store.CopyAnnotations(data.ObjectMeta.Annotations),
+				ControllerAnnotations: store.ControllerAnnotations(data.ObjectMeta.Annotations),
+				Ports:                 []store.ServicePort{},
+				Status:                status,
 			}
 			if data.Spec.Type == corev1.ServiceTypeExternalName {
 				item.DNS = data.Spec.ExternalName
 			}
 			for _, sp := range data.Spec.Ports {
 				item.Ports = append(item.Ports, store.ServicePort{
-					Name:     sp.Name,
-					Protocol: string(sp.Protocol),
-					Port:     int64(sp.Port),
+					Name:        sp.Name,
+					Protocol:    string(sp.Protocol),
+					AppProtocol: appProtocol(sp),
+					Port:        int64(sp.Port),
 				})
 			}
 			k.Logger.Tracef("%s %s: %s", SERVICE, item.Status, item.Name)
 			channel <- SyncDataEvent{SyncType: SERVICE, Namespace: item.Namespace, Data: item}
-			if publishSvc != nil && publishSvc.Namespace == data.Namespace && publishSvc.Name == data.Name {
-				ingChan <- ingstatus.SyncIngress{Service: data}
-			}
+			syncStatus(data)
 		},
 		DeleteFunc: func(obj interface{}) {
 			data, ok := obj.(*corev1.Service)
@@ -394,19 +501,19 @@ func (k *K8s) EventsServices(channel chan SyncDataEvent, ingChan chan ingstatus.
 			}
 			status := DELETED
 			item := &store.Service{
-				Namespace:   data.GetNamespace(),
-				Name:        data.GetName(),
-				Annotations: store.CopyAnnotations(data.ObjectMeta.Annotations),
-				Status:      status,
+				Namespace:             data.GetNamespace(),
+				Name:                  data.GetName(),
+				UID:                   string(data.GetUID()),
+				Annotations:           store.CopyAnnotations(data.ObjectMeta.Annotations),
+				ControllerAnnotations: store.ControllerAnnotations(data.ObjectMeta.Annotations),
+				Status:                status,
 			}
 			if data.Spec.Type == corev1.ServiceTypeExternalName {
 				item.DNS = data.Spec.ExternalName
 			}
 			k.Logger.Tracef("%s %s: %s", SERVICE, item.Status, item.Name)
 			channel <- SyncDataEvent{SyncType: SERVICE, Namespace: item.Namespace, Data: item}
-			if publishSvc != nil && publishSvc.Namespace == data.Namespace && publishSvc.Name == data.Name {
-				ingChan <- ingstatus.SyncIngress{Service: data}
-			}
+			syncStatus(data)
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			data1, ok := oldObj.(*corev1.Service)
@@ -427,43 +534,47 @@ func (k *K8s) EventsServices(channel chan SyncDataEvent, ingChan chan ingstatus.
 				k.Logger.Tracef("forwarding to ExternalName Services for %v is disabled", data2)
 				return
 			}
-			if publishSvc != nil && publishSvc.Namespace == data2.Namespace && publishSvc.Name == data2.Name {
-				ingChan <- ingstatus.SyncIngress{Service: data2}
-			}
+			syncStatus(data2)
 			status := MODIFIED
 			item1 := &store.Service{
-				Namespace:   data1.GetNamespace(),
-				Name:        data1.GetName(),
-				Annotations: store.CopyAnnotations(data1.ObjectMeta.Annotations),
-				Ports:       []store.ServicePort{},
-				Status:      status,
+				Namespace:             data1.GetNamespace(),
+				Name:                  data1.GetName(),
+				UID:                   string(data1.GetUID()),
+				Annotations:           store.CopyAnnotations(data1.ObjectMeta.Annotations),
+				ControllerAnnotations: store.ControllerAnnotations(data1.ObjectMeta.Annotations),
+				Ports:                 []store.ServicePort{},
+				Status:                status,
 			}
 			if data1.Spec.Type == corev1.ServiceTypeExternalName {
 				item1.DNS = data1.Spec.ExternalName
 			}
 			for _, sp := range data1.Spec.Ports {
 				item1.Ports = append(item1.Ports, store.ServicePort{
-					Name:     sp.Name,
-					Protocol: string(sp.Protocol),
-					Port:     int64(sp.Port),
+					Name:        sp.Name,
+					Protocol:    string(sp.Protocol),
+					AppProtocol: appProtocol(sp),
+					Port:        int64(sp.Port),
 				})
 			}
 
 			item2 := &store.Service{
-				Namespace:   data2.GetNamespace(),
-				Name:        data2.GetName(),
-				Annotations: store.CopyAnnotations(data2.ObjectMeta.Annotations),
-				Ports:       []store.ServicePort{},
-				Status:      status,
+				Namespace:             data2.GetNamespace(),
+				Name:                  data2.GetName(),
+				UID:                   string(data2.GetUID()),
+				Annotations:           store.CopyAnnotations(data2.ObjectMeta.Annotations),
+				ControllerAnnotations: store.ControllerAnnotations(data2.ObjectMeta.Annotations),
+				Ports:                 []store.ServicePort{},
+				Status:                status,
 			}
 			if data2.Spec.Type == corev1.ServiceTypeExternalName {
 				item2.DNS = data2.Spec.ExternalName
 			}
 			for _, sp := range data2.Spec.Ports {
 				item2.Ports = append(item2.Ports, store.ServicePort{
-					Name:     sp.Name,
-					Protocol: string(sp.Protocol),
-					Port:     int64(sp.Port),
+					Name:        sp.Name,
+					Protocol:    string(sp.Protocol),
+					AppProtocol: appProtocol(sp),
+					Port:        int64(sp.Port),
 				})
 			}
 			if item2.Equal(item1) {
@@ -476,6 +587,14 @@ func (k *K8s) EventsServices(channel chan SyncDataEvent, ingChan chan ingstatus.
 	go informer.Run(stop)
 }
 
+// appProtocol returns the application protocol of a service port, empty if not set
+func appProtocol(sp corev1.ServicePort) string {
+	if sp.AppProtocol == nil {
+		return ""
+	}
+	return *sp.AppProtocol
+}
+
 func (k *K8s) EventsConfigfMaps(channel chan SyncDataEvent, stop chan struct{}, informer cache.SharedIndexInformer) {
 	informer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -493,6 +612,7 @@ func (k *K8s) EventsConfigfMaps(channel chan SyncDataEvent, stop chan struct{},
 				item := &store.ConfigMap{
 					Namespace:   data.GetNamespace(),
 					Name:        data.GetName(),
+					UID:         string(data.GetUID()),
 					Annotations: store.CopyAnnotations(data.Data),
 					Status:      status,
 				}
@@ -509,6 +629,7 @@ func (k *K8s) EventsConfigfMaps(channel chan SyncDataEvent, stop chan struct{},
 				item := &store.ConfigMap{
 					Namespace:   data.GetNamespace(),
 					Name:        data.GetName(),
+					UID:         string(data.GetUID()),
 					Annotations: store.CopyAnnotations(data.Data),
 					Status:      status,
 				}
@@ -530,12 +651,14 @@ func (k *K8s) EventsConfigfMaps(channel chan SyncDataEvent, stop chan struct{},
 				item1 := &store.ConfigMap{
 					Namespace:   data1.GetNamespace(),
 					Name:        data1.GetName(),
+					UID:         string(data1.GetUID()),
 					Annotations: store.CopyAnnotations(data1.Data),
 					Status:      status,
 				}
 				item2 := &store.ConfigMap{
 					Namespace:   data2.GetNamespace(),
 					Name:        data2.GetName(),
+					UID:         string(data2.GetUID()),
 					Annotations: store.CopyAnnotations(data2.Data),
 					Status:      status,
 				}
@@ -632,6 +755,14 @@ func (k *K8s) IsNetworkingV1Beta1ApiSupported() bool {
 	return major == 1 && minor >= 14 && minor < 22
 }
 
+func (k *K8s) IsEndpointSlicesV1Supported() bool {
+	vi, _ := k.API.Discovery().ServerVersion()
+	major, _ := utils.ParseInt(vi.Major)
+	minor, _ := utils.ParseInt(vi.Minor)
+
+	return major == 1 && minor >= 21
+}
+
 func (k *K8s) IsNetworkingV1ApiSupported() bool {
 	vi, _ := k.API.Discovery().ServerVersion()
 	major, _ := utils.ParseInt(vi.Major)
@@ -0,0 +1,94 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/admin"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// maintenance holds the maintenance operations requested via the admin API,
+// they are applied by the sync loop.
+type maintenance struct {
+	mu     sync.Mutex
+	state  admin.MaintenanceState
+	resync bool
+	// applied is the state applied by the sync loop
+	applied   admin.MaintenanceState
+	eventChan chan SyncDataEvent
+}
+
+func (m *maintenance) State() admin.MaintenanceState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+func (m *maintenance) SetState(state admin.MaintenanceState) {
+	m.mu.Lock()
+	m.state = state
+	m.mu.Unlock()
+	m.trigger()
+}
+
+func (m *maintenance) Resync() {
+	m.mu.Lock()
+	m.resync = true
+	m.mu.Unlock()
+	m.trigger()
+}
+
+// trigger applies the operations without waiting for the sync period
+func (m *maintenance) trigger() {
+	select {
+	case m.eventChan <- SyncDataEvent{SyncType: COMMAND}:
+	default:
+	}
+}
+
+// handleMaintenance applies the maintenance operations, it returns whether reconciliation is paused
+// and whether a resync was forced. The initial configuration is always applied, and a forced resync
+// waits for reconciliation to be resumed.
+func (c *HAProxyController) handleMaintenance() (paused, resync bool) {
+	m := c.maintenance
+	if m == nil {
+		return false, false
+	}
+	m.mu.Lock()
+	state := m.state
+	paused = state.Paused && c.ready
+	resync = m.resync && !paused
+	if resync {
+		m.resync = false
+	}
+	m.mu.Unlock()
+	if state.Draining != m.applied.Draining {
+		c.setHealthzDrain(state.Draining)
+	}
+	if state.Paused != m.applied.Paused {
+		if state.Paused {
+			logger.Warning("reconciliation paused, HAProxy configuration changes are held")
+		} else {
+			logger.Info("reconciliation resumed")
+		}
+	}
+	m.applied = state
+	if resync {
+		utils.ReloadRequired("resync_forced", "admin-api", "")
+	}
+	return paused, resync
+}
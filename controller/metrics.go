@@ -0,0 +1,54 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
+	"github.com/haproxytech/kubernetes-ingress/controller/metrics"
+	"github.com/haproxytech/kubernetes-ingress/controller/route"
+)
+
+// updateConfigMetrics exports the size of the synced configuration,
+// it must be called before map files are cleaned for the next sync.
+func (c *HAProxyController) updateConfigMetrics() {
+	metrics.Hosts.Set(c.Cfg.MapFiles.Keys(haproxy.MAP_HOST))
+	// Every host/path route has an entry in the exact path map
+	metrics.Paths.Set(c.Cfg.MapFiles.Keys(haproxy.MAP_PATH_EXACT))
+
+	metrics.MapEntries.Reset()
+	for name, entries := range c.Cfg.MapFiles.Entries() {
+		metrics.MapEntries.Set(entries, name)
+	}
+
+	metrics.Rules.Reset()
+	for frontend, types := range c.Cfg.HAProxyRules.Count() {
+		for ruleType, count := range types {
+			metrics.Rules.Set(count, frontend, ruleType)
+		}
+	}
+
+	// Custom routes add a backend switching rule to both main frontends,
+	// on top of the map based one.
+	var acls int
+	for _, cond := range route.CustomRoutes {
+		acls += strings.Count(cond, "{")
+	}
+	for _, frontend := range []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS} {
+		metrics.BackendSwitchingRules.Set(len(route.CustomRoutes)+1, frontend)
+		metrics.ACLs.Set(acls, frontend)
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exposes controller metrics in Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CounterVec is a counter partitioned by the values of a single label
+type CounterVec struct {
+	Name   string
+	Help   string
+	Label  string
+	mu     sync.Mutex
+	values map[string]uint64
+}
+
+// ReloadTotal counts HAProxy reloads and restarts by reason,
+// a reload done for several reasons increments each of them.
+var ReloadTotal = &CounterVec{
+	Name:  "haproxy_ingress_reload_total",
+	Help:  "Number of HAProxy reloads and restarts by reason.",
+	Label: "reason",
+}
+
+// HAProxyCrashes counts HAProxy crashes detected by the controller, by the configuration
+// HAProxy was restarted with: "current" or "last_valid".
+var HAProxyCrashes = &CounterVec{
+	Name:  "haproxy_ingress_haproxy_crashes_total",
+	Help:  "Number of HAProxy crashes by configuration HAProxy was restarted with.",
+	Label: "config",
+}
+
+var counters = []*CounterVec{ReloadTotal, HAProxyCrashes}
+
+// GaugeVec is a gauge partitioned by the values of its labels,
+// a GaugeVec without labels holds a single value.
+// Counter is set for values read from HAProxy counters, which are exported as counters
+// so that rate() and increase() handle their resets on HAProxy reloads.
+type GaugeVec struct {
+	Name    string
+	Help    string
+	Labels  []string
+	Counter bool
+	mu      sync.Mutex
+	values  map[string]gaugeValue
+}
+
+type gaugeValue struct {
+	labels []string
+	value  int
+}
+
+// Configuration size gauges, updated after each successful sync.
+var (
+	Hosts = &GaugeVec{
+		Name: "haproxy_ingress_hosts",
+		Help: "Number of hosts in the host map file.",
+	}
+	Paths = &GaugeVec{
+		Name: "haproxy_ingress_paths",
+		Help: "Number of host/path routes in the path map files.",
+	}
+	BackendSwitchingRules = &GaugeVec{
+		Name:   "haproxy_ingress_backend_switching_rules",
+		Help:   "Number of backend switching rules by frontend.",
+		Labels: []string{"frontend"},
+	}
+	ACLs = &GaugeVec{
+		Name:   "haproxy_ingress_acls",
+		Help:   "Number of inline ACLs in backend switching rules by frontend.",
+		Labels: []string{"frontend"},
+	}
+	Rules = &GaugeVec{
+		Name:   "haproxy_ingress_rules",
+		Help:   "Number of HAProxy rules by frontend and rule type.",
+		Labels: []string{"frontend", "type"},
+	}
+	MapEntries = &GaugeVec{
+		Name:   "haproxy_ingress_map_entries",
+		Help:   "Number of entries by map file.",
+		Labels: []string{"map"},
+	}
+	HostPathConflicts = &GaugeVec{
+		Name: "haproxy_ingress_host_path_conflicts",
+		Help: "Number of host/paths claimed by ingresses of different namespaces.",
+	}
+	SyncConsecutiveFailures = &GaugeVec{
+		Name: "haproxy_ingress_sync_consecutive_failures",
+		Help: "Number of consecutive failed HAProxy configuration syncs, 0 after a successful one.",
+	}
+	UnknownAnnotations = &GaugeVec{
+		Name:   "haproxy_ingress_unknown_annotations",
+		Help:   "Annotations with a controller prefix which are unknown, by resource kind, namespace, name and annotation.",
+		Labels: []string{"kind", "namespace", "name", "annotation"},
+	}
+)
+
+// Stick table gauges, updated from HAProxy runtime API when metrics are scraped.
+var (
+	StickTableSize = &GaugeVec{
+		Name:   "haproxy_ingress_stick_table_size",
+		Help:   "Maximum number of entries by stick table.",
+		Labels: []string{"table"},
+	}
+	StickTableUsed = &GaugeVec{
+		Name:   "haproxy_ingress_stick_table_used",
+		Help:   "Number of entries in use by stick table.",
+		Labels: []string{"table"},
+	}
+)
+
+// Backend retry counters, read from HAProxy stats when metrics are scraped.
+// They are counters of HAProxy which are reset when it reloads.
+var (
+	BackendRetries = &GaugeVec{
+		Name:    "haproxy_ingress_backend_retries",
+		Help:    "Number of connection retries by backend since the last HAProxy reload.",
+		Labels:  []string{"backend"},
+		Counter: true,
+	}
+	BackendRedispatches = &GaugeVec{
+		Name:    "haproxy_ingress_backend_redispatches",
+		Help:    "Number of requests redispatched to another server by backend since the last HAProxy reload.",
+		Labels:  []string{"backend"},
+		Counter: true,
+	}
+)
+
+// HAProxy stats gauges, collected every "stats-metrics-period" from "show info" and "show stat".
+// Backends and servers are labeled with the Kubernetes objects they are configured from.
+// Totals are counters of HAProxy which are reset when it reloads, they are exported as counters.
+var (
+	ProcessUptime = &GaugeVec{
+		Name: "haproxy_ingress_process_uptime_seconds",
+		Help: "Uptime of the HAProxy process.",
+	}
+	ProcessConnections = &GaugeVec{
+		Name: "haproxy_ingress_process_current_connections",
+		Help: "Number of active connections of the HAProxy process.",
+	}
+	ProcessIdle = &GaugeVec{
+		Name: "haproxy_ingress_process_idle_percent",
+		Help: "Percentage of time the HAProxy process was idle.",
+	}
+	FrontendSessions = &GaugeVec{
+		Name:   "haproxy_ingress_frontend_current_sessions",
+		Help:   "Number of active sessions by frontend.",
+		Labels: []string{"frontend"},
+	}
+	FrontendSessionsTotal = &GaugeVec{
+		Name:    "haproxy_ingress_frontend_sessions",
+		Help:    "Number of sessions by frontend since the last HAProxy reload.",
+		Labels:  []string{"frontend"},
+		Counter: true,
+	}
+	FrontendRequestsTotal = &GaugeVec{
+		Name:    "haproxy_ingress_frontend_requests",
+		Help:    "Number of HTTP requests by frontend since the last HAProxy reload.",
+		Labels:  []string{"frontend"},
+		Counter: true,
+	}
+	FrontendBytesIn = &GaugeVec{
+		Name:    "haproxy_ingress_frontend_bytes_in",
+		Help:    "Number of request bytes by frontend since the last HAProxy reload.",
+		Labels:  []string{"frontend"},
+		Counter: true,
+	}
+	FrontendBytesOut = &GaugeVec{
+		Name:    "haproxy_ingress_frontend_bytes_out",
+		Help:    "Number of response bytes by frontend since the last HAProxy reload.",
+		Labels:  []string{"frontend"},
+		Counter: true,
+	}
+	FrontendResponses = &GaugeVec{
+		Name:    "haproxy_ingress_frontend_http_responses",
+		Help:    "Number of HTTP responses by frontend and status class since the last HAProxy reload.",
+		Labels:  []string{"frontend", "code"},
+		Counter: true,
+	}
+	BackendSessions = &GaugeVec{
+		Name:   "haproxy_ingress_backend_current_sessions",
+		Help:   "Number of active sessions by backend.",
+		Labels: []string{"backend", "namespace", "ingress", "service"},
+	}
+	BackendSessionsTotal = &GaugeVec{
+		Name:    "haproxy_ingress_backend_sessions",
+		Help:    "Number of sessions by backend since the last HAProxy reload.",
+		Labels:  []string{"backend", "namespace", "ingress", "service"},
+		Counter: true,
+	}
+	BackendQueue = &GaugeVec{
+		Name:   "haproxy_ingress_backend_current_queue",
+		Help:   "Number of queued requests by backend.",
+		Labels: []string{"backend", "namespace", "ingress", "service"},
+	}
+	BackendResponseTime = &GaugeVec{
+		Name:   "haproxy_ingress_backend_response_time_milliseconds",
+		Help:   "Average response time of the last 1024 requests by backend.",
+		Labels: []string{"backend", "namespace", "ingress", "service"},
+	}
+	BackendConnectionErrors = &GaugeVec{
+		Name:    "haproxy_ingress_backend_connection_errors",
+		Help:    "Number of failed connections to servers by backend since the last HAProxy reload.",
+		Labels:  []string{"backend", "namespace", "ingress", "service"},
+		Counter: true,
+	}
+	BackendResponseErrors = &GaugeVec{
+		Name:    "haproxy_ingress_backend_response_errors",
+		Help:    "Number of invalid or aborted server responses by backend since the last HAProxy reload.",
+		Labels:  []string{"backend", "namespace", "ingress", "service"},
+		Counter: true,
+	}
+	BackendResponses = &GaugeVec{
+		Name:    "haproxy_ingress_backend_http_responses",
+		Help:    "Number of HTTP responses by backend and status class since the last HAProxy reload.",
+		Labels:  []string{"backend", "namespace", "ingress", "service", "code"},
+		Counter: true,
+	}
+	ServerUp = &GaugeVec{
+		Name:   "haproxy_ingress_server_up",
+		Help:   "Whether the server is up (1) or not (0).",
+		Labels: []string{"backend", "server", "namespace", "service", "pod"},
+	}
+	ServerSessions = &GaugeVec{
+		Name:   "haproxy_ingress_server_current_sessions",
+		Help:   "Number of active sessions by server.",
+		Labels: []string{"backend", "server", "namespace", "service", "pod"},
+	}
+	ServerSessionsTotal = &GaugeVec{
+		Name:    "haproxy_ingress_server_sessions",
+		Help:    "Number of sessions by server since the last HAProxy reload.",
+		Labels:  []string{"backend", "server", "namespace", "service", "pod"},
+		Counter: true,
+	}
+	ServerResponseTime = &GaugeVec{
+		Name:   "haproxy_ingress_server_response_time_milliseconds",
+		Help:   "Average response time of the last 1024 requests by server.",
+		Labels: []string{"backend", "server", "namespace", "service", "pod"},
+	}
+	ServerConnectionErrors = &GaugeVec{
+		Name:    "haproxy_ingress_server_connection_errors",
+		Help:    "Number of failed connections by server since the last HAProxy reload.",
+		Labels:  []string{"backend", "server", "namespace", "service", "pod"},
+		Counter: true,
+	}
+)
+
+// StatsGauges are the gauges of HAProxy stats, reset before each collection
+var StatsGauges = []*GaugeVec{ProcessUptime, ProcessConnections, ProcessIdle,
+	FrontendSessions, FrontendSessionsTotal, FrontendRequestsTotal, FrontendBytesIn, FrontendBytesOut, FrontendResponses,
+	BackendSessions, BackendSessionsTotal, BackendQueue, BackendResponseTime, BackendConnectionErrors, BackendResponseErrors, BackendResponses,
+	ServerUp, ServerSessions, ServerSessionsTotal, ServerResponseTime, ServerConnectionErrors}
+
+var gauges = append([]*GaugeVec{Hosts, Paths, BackendSwitchingRules, ACLs, Rules, MapEntries, HostPathConflicts, SyncConsecutiveFailures, UnknownAnnotations, StickTableSize, StickTableUsed, BackendRetries, BackendRedispatches}, StatsGauges...)
+
+// Inc increments the counter of the given label value
+func (c *CounterVec) Inc(value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = make(map[string]uint64)
+	}
+	c.values[value]++
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values := make([]string, 0, len(c.values))
+	for value := range c.values {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.Name, c.Help, c.Name)
+	for _, value := range values {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", c.Name, c.Label, escape(value), c.values[value])
+	}
+}
+
+// Set sets the gauge of the given label values, one per label
+func (g *GaugeVec) Set(value int, labels ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.values == nil {
+		g.values = make(map[string]gaugeValue)
+	}
+	g.values[strings.Join(labels, "\x00")] = gaugeValue{labels: labels, value: value}
+}
+
+// Reset removes all the label values of the gauge,
+// so label values which no longer exist are not exported.
+func (g *GaugeVec) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values = nil
+}
+
+func (g *GaugeVec) write(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	keys := make([]string, 0, len(g.values))
+	for key := range g.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	metricType := "gauge"
+	if g.Counter {
+		metricType = "counter"
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", g.Name, g.Help, g.Name, metricType)
+	for _, key := range keys {
+		v := g.values[key]
+		pairs := make([]string, 0, len(g.Labels))
+		for i, label := range g.Labels {
+			if i < len(v.labels) {
+				pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", label, escape(v.labels[i])))
+			}
+		}
+		if len(pairs) == 0 {
+			fmt.Fprintf(w, "%s %d\n", g.Name, v.value)
+			continue
+		}
+		fmt.Fprintf(w, "%s{%s} %d\n", g.Name, strings.Join(pairs, ","), v.value)
+	}
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// Handler writes the metrics in Prometheus text exposition format
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, c := range counters {
+		c.write(w)
+	}
+	for _, g := range gauges {
+		g.write(w)
+	}
+}
@@ -18,33 +18,70 @@ import (
 	"os"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 func (c *HAProxyController) monitorChanges() {
 	go c.SyncData()
 
-	informersSynced := []cache.InformerSynced{}
 	stop := make(chan struct{})
+	informersSynced := c.startInformers(stop)
+	if !cache.WaitForCacheSync(stop, informersSynced...) {
+		logger.Panic("Caches are not populated due to an underlying error, cannot run the Ingress Controller")
+	}
+
+	syncPeriod := c.OSArgs.SyncPeriod
+	logger.Debugf("Executing syncPeriod every %s", syncPeriod.String())
+	for {
+		time.Sleep(syncPeriod)
+		c.eventChan <- SyncDataEvent{SyncType: COMMAND}
+	}
+}
+
+// startInformers starts the informers sending k8s events to the controller channels
+// and returns the functions reporting whether their caches are synced.
+func (c *HAProxyController) startInformers(stop chan struct{}) (informersSynced []cache.InformerSynced) {
 	crManager := NewCRManager(&c.Store, c.k8s.RestConfig, c.OSArgs.CacheResyncPeriod, c.eventChan, stop)
 	c.crManager = crManager
 
+	if c.k8s.NodeSelector != nil {
+		factory := informers.NewSharedInformerFactory(c.k8s.API, c.OSArgs.CacheResyncPeriod)
+		ni := trimmedInformer(factory, "", "nodes", &corev1.Node{}, trimNode)
+		c.k8s.EventsNodes(stop, ni)
+		// endpoints are filtered with the nodes known
+		if !cache.WaitForCacheSync(stop, ni.HasSynced) {
+			logger.Error("nodes informer not synced")
+		}
+	}
+
+	endpointSlices := c.k8s.IsEndpointSlicesV1Supported()
+	if endpointSlices {
+		logger.Debug("watching EndpointSlices")
+	}
 	for _, namespace := range c.getWhitelistedNamespaces() {
 		factory := informers.NewSharedInformerFactoryWithOptions(c.k8s.API, c.OSArgs.CacheResyncPeriod, informers.WithNamespace(namespace))
 
-		pi := factory.Core().V1().Endpoints().Informer()
-		c.k8s.EventsEndpoints(c.eventChan, stop, pi)
+		var pi cache.SharedIndexInformer
+		if endpointSlices {
+			pi = endpointSlicesInformer(factory, namespace)
+			c.k8s.EventsEndpointSlices(c.endpointsChan, stop, pi)
+		} else {
+			pi = trimmedInformer(factory, namespace, "endpoints", &corev1.Endpoints{}, trimEndpoints)
+			c.k8s.EventsEndpoints(c.endpointsChan, stop, pi)
+		}
 
-		svci := factory.Core().V1().Services().Informer()
-		c.k8s.EventsServices(c.eventChan, c.statusChan, stop, svci, c.PublishService)
+		svci := trimmedInformer(factory, namespace, "services", &corev1.Service{}, trimObjectMeta)
+		c.k8s.EventsServices(c.eventChan, c.statusChan, stop, svci, c.PublishService, c.InternalPublishService)
 
 		nsi := factory.Core().V1().Namespaces().Informer()
 		c.k8s.EventsNamespaces(c.eventChan, stop, nsi)
 
-		si := factory.Core().V1().Secrets().Informer()
+		si := secretsInformer(factory, namespace)
 		c.k8s.EventsSecrets(c.eventChan, stop, si)
 
 		ci := factory.Core().V1().ConfigMaps().Informer()
@@ -65,16 +102,36 @@ func (c *HAProxyController) monitorChanges() {
 			informersSynced = append(informersSynced, ici.HasSynced)
 		}
 	}
+	return informersSynced
+}
 
-	if !cache.WaitForCacheSync(stop, informersSynced...) {
-		logger.Panic("Caches are not populated due to an underlying error, cannot run the Ingress Controller")
+// nextEvent returns the next k8s event to process.
+// Endpoints events are only processed when no other event is pending,
+// so that endpoints churn does not delay ingress, service and configuration changes.
+func (c *HAProxyController) nextEvent() SyncDataEvent {
+	select {
+	case job := <-c.eventChan:
+		return job
+	default:
+	}
+	select {
+	case job := <-c.eventChan:
+		return job
+	case job := <-c.endpointsChan:
+		return job
 	}
+}
 
-	syncPeriod := c.OSArgs.SyncPeriod
-	logger.Debugf("Executing syncPeriod every %s", syncPeriod.String())
+// drainEndpoints processes pending endpoints events
+func (c *HAProxyController) drainEndpoints() (change bool) {
 	for {
-		time.Sleep(syncPeriod)
-		c.eventChan <- SyncDataEvent{SyncType: COMMAND}
+		select {
+		case job := <-c.endpointsChan:
+			ns := c.Store.GetNamespace(job.Namespace)
+			change = c.Store.EventEndpoints(ns, job.Data.(*store.Endpoints), c.Client.SyncBackendSrvs) || change
+		default:
+			return change
+		}
 	}
 }
 
@@ -82,38 +139,67 @@ func (c *HAProxyController) monitorChanges() {
 // All the changes must come through this function
 func (c *HAProxyController) SyncData() {
 	hadChanges := false
-	for job := range c.eventChan {
-		ns := c.Store.GetNamespace(job.Namespace)
+	for {
+		job := c.nextEvent()
 		change := false
 		switch job.SyncType {
 		case COMMAND:
-			c.reload = c.auxCfgUpdated()
+			c.superviseHAProxy()
+			paused, resync := c.handleMaintenance()
+			if paused {
+				// k8s events are still applied to the store, and endpoints via runtime API
+				continue
+			}
+			c.reload = c.auxCfgUpdated() || c.reloadDue() || resync
+			if !c.ready {
+				// first sync must include endpoints of the initial informers sync
+				hadChanges = c.drainEndpoints() || hadChanges
+			}
+			hadChanges = c.Store.ExpireDenylists() || hadChanges
+			// replay failed syncs once their backoff delay elapsed
+			hadChanges = c.Client.APIRetryDue() || hadChanges
+			// apply or refuse risky changes deferred by the canary rollout
+			hadChanges = c.canaryCheck() || hadChanges
 			if hadChanges || c.reload {
 				c.updateHAProxy()
 				hadChanges = false
 				continue
 			}
-		case CUSTOM_RESOURCE:
-			change = c.crManager.EventCustomResource(job)
-		case NAMESPACE:
-			change = c.Store.EventNamespace(ns, job.Data.(*store.Namespace))
-		case INGRESS:
-			change = c.Store.EventIngress(ns, job.Data.(*store.Ingress), c.OSArgs.IngressClass)
-		case INGRESS_CLASS:
-			change = c.Store.EventIngressClass(job.Data.(*store.IngressClass))
-		case ENDPOINTS:
-			change = c.Store.EventEndpoints(ns, job.Data.(*store.Endpoints), c.Client.SyncBackendSrvs)
-		case SERVICE:
-			change = c.Store.EventService(ns, job.Data.(*store.Service))
-		case CONFIGMAP:
-			change = c.Store.EventConfigMap(ns, job.Data.(*store.ConfigMap))
-		case SECRET:
-			change = c.Store.EventSecret(ns, job.Data.(*store.Secret))
+			c.auditDrift()
+			c.collectStats()
+			c.updateReadinessGates()
+			c.checkAlerts()
+		default:
+			change = c.processEvent(job)
 		}
 		hadChanges = hadChanges || change
 	}
 }
 
+// processEvent applies a k8s event to the store and reports whether it changed
+func (c *HAProxyController) processEvent(job SyncDataEvent) (change bool) {
+	ns := c.Store.GetNamespace(job.Namespace)
+	switch job.SyncType {
+	case CUSTOM_RESOURCE:
+		change = c.crManager.EventCustomResource(job)
+	case NAMESPACE:
+		change = c.Store.EventNamespace(ns, job.Data.(*store.Namespace))
+	case INGRESS:
+		change = c.Store.EventIngress(ns, job.Data.(*store.Ingress), c.OSArgs.IngressClass)
+	case INGRESS_CLASS:
+		change = c.Store.EventIngressClass(job.Data.(*store.IngressClass))
+	case ENDPOINTS:
+		change = c.Store.EventEndpoints(ns, job.Data.(*store.Endpoints), c.Client.SyncBackendSrvs)
+	case SERVICE:
+		change = c.Store.EventService(ns, job.Data.(*store.Service))
+	case CONFIGMAP:
+		change = c.Store.EventConfigMap(ns, job.Data.(*store.ConfigMap))
+	case SECRET:
+		change = c.Store.EventSecret(ns, job.Data.(*store.Secret))
+	}
+	return change
+}
+
 func (c *HAProxyController) getIngressSharedInformers(factory informers.SharedInformerFactory) (ii, ici cache.SharedIndexInformer) {
 	for i, apiGroup := range []string{"networking.k8s.io/v1", "networking.k8s.io/v1beta1", "extensions/v1beta1"} {
 		resources, err := c.k8s.API.ServerResourcesForGroupVersion(apiGroup)
@@ -167,6 +253,20 @@ func (c *HAProxyController) getWhitelistedNamespaces() []string {
 }
 
 // auxCfgUpdate returns true if auxiliary HAProxy config file was updated, false otherwise.
+// auxCfgValid returns false when HAProxy configuration with the auxiliary one is refused by "validate-config",
+// HAProxy is then not reloaded and, as the auxiliary configuration is part of the validation, configuration
+// changes are refused until it is fixed.
+func (c *HAProxyController) auxCfgValid() bool {
+	err := c.validateCurrent()
+	if err == nil {
+		return true
+	}
+	logger.Errorf("Auxiliary HAProxy config '%s' not applied: %s", c.Cfg.Env.AuxCFGFile, err)
+	c.setHealthzSyncFailed(true)
+	c.notify("config_error", nil, 0, err)
+	return false
+}
+
 func (c *HAProxyController) auxCfgUpdated() bool {
 	info, errStat := os.Stat(c.Cfg.Env.AuxCFGFile)
 	// File does not exist
@@ -178,6 +278,10 @@ func (c *HAProxyController) auxCfgUpdated() bool {
 		c.AuxCfgModTime = 0
 		c.Client.SetAuxCfgFile("")
 		c.haproxyProcess.UseAuxFile(false)
+		if !c.auxCfgValid() {
+			return false
+		}
+		utils.ReloadRequired("aux_config_updated", c.Cfg.Env.AuxCFGFile, "removed")
 		return true
 	}
 	// Check modification time
@@ -189,5 +293,9 @@ func (c *HAProxyController) auxCfgUpdated() bool {
 	c.AuxCfgModTime = modifTime
 	c.Client.SetAuxCfgFile(c.Cfg.Env.AuxCFGFile)
 	c.haproxyProcess.UseAuxFile(true)
+	if !c.auxCfgValid() {
+		return false
+	}
+	utils.ReloadRequired("aux_config_updated", c.Cfg.Env.AuxCFGFile, "")
 	return true
 }
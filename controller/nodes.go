@@ -0,0 +1,85 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// EventsNodes tracks the nodes matching the "endpoints-node-selector". Endpoints are converted
+// again when a node starts or stops matching, so that their addresses on the node are added or removed.
+func (k *K8s) EventsNodes(stop chan struct{}, informer cache.SharedIndexInformer) {
+	k.nodes = make(map[string]bool)
+	update := func(obj interface{}) {
+		node, ok := obj.(*corev1.Node)
+		if !ok {
+			return
+		}
+		selected := k.NodeSelector.Matches(labels.Set(node.Labels))
+		k.nodesMu.Lock()
+		wasSelected, known := k.nodes[node.Name]
+		k.nodes[node.Name] = selected
+		k.nodesMu.Unlock()
+		// unknown nodes are selected, see nodeSelected
+		if selected == (wasSelected || !known) {
+			return
+		}
+		logger.Infof("node '%s': endpoints selected: %t", node.Name, selected)
+		k.resyncEndpoints()
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: update,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			update(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if node, ok := obj.(*corev1.Node); ok {
+				k.nodesMu.Lock()
+				delete(k.nodes, node.Name)
+				k.nodesMu.Unlock()
+			}
+		},
+	})
+	go informer.Run(stop)
+}
+
+// nodeSelected returns false for nodes known not to match the "endpoints-node-selector"
+func (k *K8s) nodeSelected(node string) bool {
+	k.nodesMu.Lock()
+	defer k.nodesMu.Unlock()
+	selected, ok := k.nodes[node]
+	return !ok || selected
+}
+
+// addEndpointsResync registers a function sending again the endpoints of an informer
+func (k *K8s) addEndpointsResync(resync func()) {
+	k.nodesMu.Lock()
+	k.endpointsResync = append(k.endpointsResync, resync)
+	k.nodesMu.Unlock()
+}
+
+func (k *K8s) resyncEndpoints() {
+	k.nodesMu.Lock()
+	resyncs := append([]func(){}, k.endpointsResync...)
+	k.nodesMu.Unlock()
+	for _, resync := range resyncs {
+		resync()
+	}
+}
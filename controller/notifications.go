@@ -0,0 +1,85 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// webhookTimeout is the timeout of the requests to the alert and notification webhooks
+const webhookTimeout = 10 * time.Second
+
+// notification is the JSON payload posted to the "notification-webhook"
+type notification struct {
+	// Event is "reload", "restart", "config_error" or "crash"
+	Event      string               `json:"event"`
+	Success    bool                 `json:"success"`
+	Reasons    []notificationReason `json:"reasons"`
+	DurationMs int64                `json:"duration_ms"`
+	Error      string               `json:"error,omitempty"`
+	Time       time.Time            `json:"time"`
+}
+
+type notificationReason struct {
+	Reason string `json:"reason"`
+	Object string `json:"object"`
+	Field  string `json:"field,omitempty"`
+}
+
+// notify posts, if the "notification-webhook" is set, the result of an HAProxy reload, restart or configuration sync,
+// or an HAProxy crash
+func (c *HAProxyController) notify(event string, reasons []utils.ReloadReason, duration time.Duration, err error) {
+	if c.OSArgs.NotificationWebhook == "" {
+		return
+	}
+	n := notification{
+		Event:      event,
+		Success:    err == nil,
+		Reasons:    make([]notificationReason, 0, len(reasons)),
+		DurationMs: duration.Milliseconds(),
+		Time:       time.Now().UTC(),
+	}
+	for _, r := range reasons {
+		n.Reasons = append(n.Reasons, notificationReason{Reason: r.Reason, Object: r.Object, Field: r.Field})
+	}
+	if err != nil {
+		n.Error = err.Error()
+	}
+	go postJSON(c.OSArgs.NotificationWebhook, n)
+}
+
+// postJSON posts a value as JSON to a webhook, failures are only logged
+func postJSON(url string, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	client := http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.Errorf("webhook: %s", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		logger.Errorf("webhook '%s': status %d", url, resp.StatusCode)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// updateReadinessGates sets, at each sync period, the "pod-readiness-gate" condition of backend pods
+// declaring it as readiness gate once their HAProxy server is up, so that rollouts wait for new pods
+// to be reachable via HAProxy. Until then, the pods are not ready and their servers are drained:
+// they are health checked without receiving traffic. The condition is never set back to False.
+func (c *HAProxyController) updateReadinessGates() {
+	if c.OSArgs.PodReadinessGate == "" || !c.ready {
+		return
+	}
+	stats, err := c.Client.StatsGet()
+	if err != nil {
+		logger.Errorf("pod readiness gate: %s", err)
+		return
+	}
+	// servers up by backend
+	up := make(map[string]map[string]struct{})
+	for _, collection := range stats {
+		for _, stat := range collection.Stats {
+			if stat.Type != "server" || stat.Stats == nil {
+				continue
+			}
+			// servers of pods waiting for their readiness gate are drained
+			if !strings.HasPrefix(stat.Stats.Status, "UP") && stat.Stats.Status != "DRAIN" && stat.Stats.Status != "no check" {
+				continue
+			}
+			if up[stat.BackendName] == nil {
+				up[stat.BackendName] = make(map[string]struct{})
+			}
+			up[stat.BackendName][stat.Name] = struct{}{}
+		}
+	}
+	readyPods := make(map[string]struct{})
+	for _, ns := range c.Store.Namespaces {
+		if !ns.Relevant {
+			continue
+		}
+		for _, endpoints := range ns.Endpoints {
+			for _, portEndpoints := range endpoints.Ports {
+				for _, srv := range portEndpoints.HAProxySrvs {
+					pod := endpoints.Pods[srv.Address]
+					if _, ok := up[portEndpoints.BackendName][srv.Name]; !ok || srv.Address == "" || pod == "" {
+						continue
+					}
+					key := ns.Name + "/" + pod
+					if _, ok := c.readyPods[key]; !ok {
+						if err = c.setPodReadinessGate(ns.Name, pod); err != nil {
+							logger.Errorf("pod readiness gate: pod '%s': %s", key, err)
+							continue
+						}
+					}
+					readyPods[key] = struct{}{}
+				}
+			}
+		}
+	}
+	// pods no longer backend servers are forgotten
+	c.readyPods = readyPods
+}
+
+// setPodReadinessGate sets the "pod-readiness-gate" condition of a pod to True,
+// pods not declaring it as readiness gate are left unchanged.
+func (c *HAProxyController) setPodReadinessGate(namespace, name string) error {
+	conditionType := corev1.PodConditionType(c.OSArgs.PodReadinessGate)
+	pods := c.k8s.API.CoreV1().Pods(namespace)
+	pod, err := pods.Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	gated := false
+	for _, gate := range pod.Spec.ReadinessGates {
+		gated = gated || gate.ConditionType == conditionType
+	}
+	if !gated {
+		return nil
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return nil
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []corev1.PodCondition{{
+				Type:               conditionType,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "BackendServerUp",
+				Message:            "HAProxy backend server of the pod is up",
+			}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = pods.Patch(context.Background(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "status")
+	if err == nil {
+		logger.Infof("pod readiness gate: pod '%s/%s' condition '%s' set", namespace, name, conditionType)
+	}
+	return err
+}
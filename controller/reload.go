@@ -0,0 +1,101 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/metrics"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// reloadWindow is the period over which "max-reloads-per-minute" is enforced
+const reloadWindow = time.Minute
+
+// reloadAllowed returns true if a reload fits in the "max-reloads-per-minute" budget
+func (c *HAProxyController) reloadAllowed() bool {
+	if c.OSArgs.MaxReloadsPerMinute <= 0 {
+		return true
+	}
+	now := time.Now()
+	i := 0
+	for i < len(c.reloads) && now.Sub(c.reloads[i]) >= reloadWindow {
+		i++
+	}
+	c.reloads = c.reloads[i:]
+	return len(c.reloads) < c.OSArgs.MaxReloadsPerMinute
+}
+
+// reloadDue returns true if a delayed reload can now be done
+func (c *HAProxyController) reloadDue() bool {
+	return c.reloadPending && c.reloadAllowed()
+}
+
+// delayReload records a reload exceeding the budget, changes made until the budget allows it
+// are applied by a single reload.
+func (c *HAProxyController) delayReload() {
+	if !c.reloadPending {
+		logger.Warningf("budget of %d HAProxy reloads per minute reached, reload delayed by %s", c.OSArgs.MaxReloadsPerMinute, time.Until(c.reloads[0].Add(reloadWindow)).Round(time.Second))
+	}
+	c.reloadPending = true
+}
+
+// reloadLoggedReasons is the maximum number of reload reasons logged
+const reloadLoggedReasons = 5
+
+// reloadDone records a reload, or restart, in the budget and reports its reasons
+func (c *HAProxyController) reloadDone(action string) []utils.ReloadReason {
+	c.reloadPending = false
+	if c.OSArgs.MaxReloadsPerMinute > 0 {
+		c.reloads = append(c.reloads, time.Now())
+	}
+	reasons := utils.ReloadReasons()
+	if len(reasons) == 0 {
+		reasons = []utils.ReloadReason{{Reason: "unknown"}}
+	}
+	counted := make(map[string]struct{}, len(reasons))
+	details := make([]string, 0, reloadLoggedReasons+1)
+	for i, r := range reasons {
+		if _, ok := counted[r.Reason]; !ok {
+			counted[r.Reason] = struct{}{}
+			metrics.ReloadTotal.Inc(r.Reason)
+		}
+		if i == reloadLoggedReasons {
+			details = append(details, fmt.Sprintf("and %d more", len(reasons)-i))
+			continue
+		}
+		if i < reloadLoggedReasons {
+			details = append(details, r.String())
+		}
+	}
+	logger.Infof("HAProxy %s: %s", action, strings.Join(details, ", "))
+	return reasons
+}
+
+// haproxyReload reloads, or restarts, HAProxy and notifies the result
+func (c *HAProxyController) haproxyReload(action string) (err error) {
+	start := time.Now()
+	if err = c.haproxyService(action); err != nil {
+		logger.Error(err)
+		c.notify(action, utils.PendingReloadReasons(), time.Since(start), err)
+		return
+	}
+	duration := time.Since(start)
+	c.haproxyLoaded()
+	c.notify(action, c.reloadDone(action+"ed"), duration, nil)
+	return
+}
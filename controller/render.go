@@ -0,0 +1,132 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/admin"
+	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/route"
+	"github.com/haproxytech/kubernetes-ingress/controller/service"
+)
+
+// renderQuietPeriod is how long Render waits for k8s events once caches are synced
+const renderQuietPeriod = time.Second
+
+// renderProcess stands for HAProxy process when rendering configuration
+type renderProcess struct{}
+
+func (renderProcess) HaproxyService(action string) error { return nil }
+
+func (renderProcess) UseAuxFile(useAuxFile bool) {}
+
+func (renderProcess) Status() error { return nil }
+
+// Render runs a single configuration sync against the cluster without running HAProxy,
+// the generated configuration is left in the configuration directory.
+// It is used by the binary commands to debug configuration generation locally.
+func (c *HAProxyController) Render(timeout time.Duration) (err error) {
+	logger.SetLevel(c.OSArgs.LogLevel.LogLevel)
+	c.Cfg.InternalBinds = c.OSArgs.InternalIngressClass != ""
+	c.Cfg.SSLPassthroughPort = c.OSArgs.SSLPassthroughBindPort != 0
+	route.InternalBinds = c.Cfg.InternalBinds
+	service.BackendNameStrategy = c.OSArgs.BackendNameStrategy
+	if err = c.Cfg.Init(); err != nil {
+		return err
+	}
+	annotations.SetSecretDir(c.Cfg.Env.SecretDir)
+	annotations.SetPatternDir(c.Cfg.Env.PatternDir)
+	if c.OSArgs.HealthzFailOnSyncError {
+		if err = ioutil.WriteFile(c.healthzSyncFailedACL(), []byte("\n"), 0644); err != nil { //nolint:gosec
+			return err
+		}
+	}
+	if c.OSArgs.AdminPort != 0 {
+		if err = ioutil.WriteFile(c.healthzDrainACL(), []byte("\n"), 0644); err != nil { //nolint:gosec
+			return err
+		}
+	}
+	c.Client, err = api.Init(c.Cfg.Env.TransactionDir, c.Cfg.Env.MainCFGFile, c.Cfg.Env.HAProxyBinary, c.Cfg.Env.RuntimeSocket, c.Cfg.Env.MasterSocket)
+	if err != nil {
+		return err
+	}
+	c.initHandlers()
+	c.haproxyProcess = renderProcess{}
+	c.introspection = &admin.Introspection{}
+	if c.k8s, err = c.kubernetesClient(); err != nil {
+		return err
+	}
+
+	c.eventChan = make(chan SyncDataEvent, watch.DefaultChanSize*6)
+	c.endpointsChan = make(chan SyncDataEvent, watch.DefaultChanSize*6)
+	stop := make(chan struct{})
+	defer close(stop)
+	informersSynced := c.startInformers(stop)
+	if err = c.renderWait(stop, informersSynced, timeout); err != nil {
+		return err
+	}
+
+	c.updateHAProxy()
+	if stats := c.Client.APITransactionStats(); stats.ConsecutiveFailures > 0 {
+		return fmt.Errorf("configuration sync failed: %s", stats.LastError)
+	}
+	return nil
+}
+
+// renderWait processes k8s events until caches are synced
+// and no event is received for renderQuietPeriod.
+func (c *HAProxyController) renderWait(stop chan struct{}, informersSynced []cache.InformerSynced, timeout time.Duration) error {
+	synced := make(chan bool, 1)
+	go func() {
+		synced <- cache.WaitForCacheSync(stop, informersSynced...)
+	}()
+	deadline := time.After(timeout)
+	// nil until caches are synced
+	var quiet <-chan time.Time
+	for {
+		select {
+		case ok := <-synced:
+			if !ok {
+				return errors.New("caches are not populated due to an underlying error")
+			}
+			quiet = time.After(renderQuietPeriod)
+		case job := <-c.eventChan:
+			c.processEvent(job)
+		case job := <-c.endpointsChan:
+			c.processEvent(job)
+		case <-quiet:
+			return nil
+		case <-deadline:
+			return fmt.Errorf("k8s caches not synced after %s", timeout)
+		}
+		if quiet != nil {
+			quiet = time.After(renderQuietPeriod)
+		}
+	}
+}
+
+// Introspection returns the state of the last configuration sync,
+// available when the admin API is enabled or after Render.
+func (c *HAProxyController) Introspection() *admin.Introspection {
+	return c.introspection
+}
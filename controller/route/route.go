@@ -15,6 +15,8 @@
 package route
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 
@@ -22,6 +24,7 @@ import (
 
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
 	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
@@ -34,14 +37,25 @@ const (
 )
 
 var CustomRoutes = make(map[string]string)
+
+// customRoutesActive holds the backends of the custom routes added during the current sync
+var customRoutesActive = make(map[string]struct{})
 var logger = utils.GetLogger()
 
+// InternalBinds is set when internal ingresses are exposed via dedicated binds,
+// custom routes are then scoped to the binds of their ingress.
+var InternalBinds bool
+
 type Route struct {
 	Host           string
 	Path           *store.IngressPath
 	HAProxyRules   []haproxy.RuleID
 	BackendName    string
 	SSLPassthrough bool
+	// StickyCookie keeps clients on the backend selected by a custom route
+	StickyCookie string
+	// Internal routes are only reachable via internal binds
+	Internal bool
 }
 
 // AddHostPathRoute adds Host/Path ingress route to haproxy Map files used for backend switching.
@@ -66,46 +80,67 @@ func AddHostPathRoute(route Route, mapFiles *haproxy.Maps) error {
 		return nil
 	}
 	// HTTP
-	if route.Host != "" {
-		mapFiles.AppendRow(haproxy.MAP_HOST, route.Host+"\t\t\t"+route.Host)
-	} else if route.Path.Path == "" {
+	if route.Host == "" && route.Path.Path == "" {
 		return fmt.Errorf("neither Host nor Path are provided for backend %v,", route.BackendName)
 	}
+	host := hostKey(route)
+	if route.Host != "" {
+		mapFiles.AppendRow(haproxy.MAP_HOST, host+"\t\t\t"+host)
+	}
 
 	path := route.Path.Path
 	switch {
 	case route.Path.PathTypeMatch == store.PATH_TYPE_EXACT:
-		mapFiles.AppendRow(haproxy.MAP_PATH_EXACT, route.Host+path+"\t\t\t"+value)
+		mapFiles.AppendRow(haproxy.MAP_PATH_EXACT, host+path+"\t\t\t"+value)
 	case path == "" || path == "/":
-		mapFiles.AppendRow(haproxy.MAP_PATH_PREFIX, route.Host+"/"+"\t\t\t"+value)
+		mapFiles.AppendRow(haproxy.MAP_PATH_PREFIX, host+"/"+"\t\t\t"+value)
 	case route.Path.PathTypeMatch == store.PATH_TYPE_PREFIX:
 		path = strings.TrimSuffix(path, "/")
-		mapFiles.AppendRow(haproxy.MAP_PATH_EXACT, route.Host+path+"\t\t\t"+value)
-		mapFiles.AppendRow(haproxy.MAP_PATH_PREFIX, route.Host+path+"/"+"\t\t\t"+value)
+		mapFiles.AppendRow(haproxy.MAP_PATH_EXACT, host+path+"\t\t\t"+value)
+		mapFiles.AppendRow(haproxy.MAP_PATH_PREFIX, host+path+"/"+"\t\t\t"+value)
 	case route.Path.PathTypeMatch == store.PATH_TYPE_IMPLEMENTATION_SPECIFIC:
 		path = strings.TrimSuffix(path, "/")
-		mapFiles.AppendRow(haproxy.MAP_PATH_EXACT, route.Host+path+"\t\t\t"+value)
-		mapFiles.AppendRow(haproxy.MAP_PATH_PREFIX, route.Host+path+"\t\t\t"+value)
+		mapFiles.AppendRow(haproxy.MAP_PATH_EXACT, host+path+"\t\t\t"+value)
+		mapFiles.AppendRow(haproxy.MAP_PATH_PREFIX, host+path+"\t\t\t"+value)
 	default:
 		return fmt.Errorf("unknown path type '%s' with backend '%s'", route.Path.PathTypeMatch, route.BackendName)
 	}
 	return nil
 }
 
+// AddHostDefaultRoute adds a Host route to haproxy Map files used for backend switching
+// when no Path of the Host matches.
+func AddHostDefaultRoute(route Route, mapFiles *haproxy.Maps) error {
+	if route.BackendName == "" {
+		return fmt.Errorf("backendName missing")
+	}
+	if route.Host == "" {
+		return fmt.Errorf("host missing for default backend %s", route.BackendName)
+	}
+	// Wildcard host
+	if route.Host[0] == '*' {
+		route.Host = route.Host[1:]
+	}
+	value := route.BackendName
+	for _, id := range route.HAProxyRules {
+		value += "." + string(id)
+	}
+	host := hostKey(route)
+	mapFiles.AppendRow(haproxy.MAP_HOST, host+"\t\t\t"+host)
+	mapFiles.AppendRow(haproxy.MAP_HOST_DEFAULT, host+"\t\t\t"+value)
+	return nil
+}
+
 // AddCustomRoute adds an ingress route with specific ACL via use_backend haproxy directive
 func AddCustomRoute(route Route, routeACLAnn string, api api.HAProxyClient) (reload bool, err error) {
-	var routeCond string
-	if route.Host != "" {
-		routeCond = fmt.Sprintf("{ var(txn.host) %s } ", route.Host)
-	}
-	if route.Path.Path != "" {
-		if route.Path.PathTypeMatch == store.PATH_TYPE_EXACT {
-			routeCond = fmt.Sprintf("%s { path %s } ", routeCond, route.Path.Path)
-		} else {
-			routeCond = fmt.Sprintf("%s { path -m beg %s } ", routeCond, route.Path.Path)
-		}
+	routeCond := fmt.Sprintf("%s { %s } ", HostPathCond(route), routeACLAnn)
+	if route.StickyCookie != "" {
+		// Clients holding the cookie stick to the backend it names,
+		// the others are routed according to the ACL.
+		routeCond = fmt.Sprintf("%s { req.cook(%s) -m str %s } || %s !{ req.cook(%s) -m found } { %s } ",
+			HostPathCond(route), route.StickyCookie, stickyCookieValue(route.BackendName),
+			HostPathCond(route), route.StickyCookie, routeACLAnn)
 	}
-	routeCond = fmt.Sprintf("%s { %s } ", routeCond, routeACLAnn)
 
 	for _, frontend := range []string{FrontendHTTP, FrontendHTTPS} {
 		err = api.BackendSwitchingRuleCreate(frontend, models.BackendSwitchingRule{
@@ -118,14 +153,87 @@ func AddCustomRoute(route Route, routeACLAnn string, api api.HAProxyClient) (rel
 			return
 		}
 	}
+	customRoutesActive[route.BackendName] = struct{}{}
 	if acl := CustomRoutes[route.BackendName]; acl != routeCond {
 		CustomRoutes[route.BackendName] = routeCond
 		reload = true
 		logger.Debugf("Custom Route to backend '%s' added, reload required", route.BackendName)
+		utils.ReloadRequired("route_updated", route.BackendName, routeCond)
 	}
 	return reload, err
 }
 
+// StickyCookieRules returns the frontend rules setting the sticky cookie of a custom route
+// with an opaque value of the backend which served the request, when the client did not send it.
+func StickyCookieRules(route Route) []haproxy.Rule {
+	varName := "sticky_" + utils.Hash([]byte(route.StickyCookie+HostPathCond(route)))
+	return []haproxy.Rule{
+		rules.ReqSetVar{
+			Name:       varName,
+			Scope:      "txn",
+			Expression: "bool(true)",
+			CondTest:   fmt.Sprintf("%s !{ req.cook(%s) -m found }", HostPathCond(route), route.StickyCookie),
+		},
+		rules.ResSetCookie{
+			Name:     route.StickyCookie,
+			Value:    "%[be_name,sha2(256),bytes(0,8),hex]",
+			CondTest: fmt.Sprintf("{ var(txn.%s) -m bool }", varName),
+		},
+	}
+}
+
+// stickyCookieValue returns the sticky cookie value of a backend, computed by HAProxy
+// in StickyCookieRules, so that internal backend names are not disclosed to clients
+func stickyCookieValue(backendName string) string {
+	sum := sha256.Sum256([]byte(backendName))
+	return strings.ToUpper(hex.EncodeToString(sum[:8]))
+}
+
+// hostKey returns the key of the route host in map files
+func hostKey(route Route) string {
+	if route.Internal {
+		return haproxy.INTERNAL_HOST_PREFIX + route.Host
+	}
+	return route.Host
+}
+
+// HostPathCond returns the condition matching the requests of the route host and path
+func HostPathCond(route Route) (cond string) {
+	if InternalBinds {
+		cond = fmt.Sprintf("{ var(txn.host_match) -m beg %s } ", haproxy.INTERNAL_HOST_PREFIX)
+		if !route.Internal {
+			cond = "!" + cond
+		}
+	}
+	if route.Host != "" {
+		cond = fmt.Sprintf("%s{ var(txn.host) %s } ", cond, route.Host)
+	}
+	if route.Path.Path != "" {
+		if route.Path.PathTypeMatch == store.PATH_TYPE_EXACT {
+			cond = fmt.Sprintf("%s { path %s } ", cond, route.Path.Path)
+		} else {
+			cond = fmt.Sprintf("%s { path -m beg %s } ", cond, route.Path.Path)
+		}
+	}
+	return cond
+}
+
+// CustomRoutesClean deletes the custom routes which were not added during the current sync,
+// such as the ones of canary ingresses which were deleted or lost their canary annotations.
+func CustomRoutesClean() (reload bool) {
+	for backendName := range CustomRoutes {
+		if _, ok := customRoutesActive[backendName]; ok {
+			continue
+		}
+		delete(CustomRoutes, backendName)
+		logger.Debugf("Custom Route to backend '%s' deleted, reload required", backendName)
+		utils.ReloadRequired("route_deleted", backendName, "")
+		reload = true
+	}
+	customRoutesActive = make(map[string]struct{})
+	return reload
+}
+
 func CustomRoutesReset(api api.HAProxyClient) (err error) {
 	for _, frontend := range []string{FrontendHTTP, FrontendHTTPS} {
 		api.BackendSwitchingRuleDeleteAll(frontend)
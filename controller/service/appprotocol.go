@@ -0,0 +1,44 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import "strings"
+
+// appProtocolAnnotations returns the server annotation values implied by the appProtocol of the service port.
+// They take precedence over ConfigMap values but not over service and ingress annotations.
+func (s *SvcContext) appProtocolAnnotations() map[string]string {
+	if s.path.SvcPortResolved == nil {
+		return nil
+	}
+	appProtocol := strings.ToLower(s.path.SvcPortResolved.AppProtocol)
+	switch appProtocol {
+	case "":
+		return nil
+	case "http":
+		return map[string]string{"server-ssl": "false", "server-proto": "h1"}
+	case "https":
+		return map[string]string{"server-ssl": "true"}
+	case "h2c", "kubernetes.io/h2c", "grpc":
+		return map[string]string{"server-ssl": "false", "server-proto": "h2"}
+	case "tcp":
+		if !s.tcpService {
+			logger.Warningf("service %s/%s: appProtocol 'tcp' of port '%s' ignored, ingress backends are in http mode", s.service.Namespace, s.service.Name, s.backendName)
+		}
+		return nil
+	default:
+		logger.Debugf("service %s/%s: appProtocol '%s' not supported, ignored", s.service.Namespace, s.service.Name, appProtocol)
+		return nil
+	}
+}
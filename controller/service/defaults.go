@@ -0,0 +1,68 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+// applyNamespaceDefaults sets the timeouts, log tag and balance of the namespace scoped Defaults CR
+// of the service namespace. They are layered over the global Defaults and ConfigMap annotations,
+// so only the ones not set by the ingress or service annotations are applied.
+func (s *SvcContext) applyNamespaceDefaults(backend *models.Backend, k8s store.K8s) {
+	defaults, ok := k8s.CR.NamespaceDefaults[s.service.Namespace]
+	if !ok {
+		return
+	}
+	annotated := func(names ...string) bool {
+		for _, name := range names {
+			if _, ok := s.GetServiceAnnotations()[name]; ok {
+				return true
+			}
+			if _, ok := s.ingress.Annotations[name]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	for _, field := range []struct {
+		backend    **int64
+		defaults   *int64
+		annotation string
+	}{
+		{&backend.CheckTimeout, defaults.CheckTimeout, "timeout-check"},
+		{&backend.ConnectTimeout, defaults.ConnectTimeout, ""},
+		{&backend.HTTPKeepAliveTimeout, defaults.HTTPKeepAliveTimeout, ""},
+		{&backend.HTTPRequestTimeout, defaults.HTTPRequestTimeout, ""},
+		{&backend.QueueTimeout, defaults.QueueTimeout, "timeout-queue"},
+		{&backend.ServerTimeout, defaults.ServerTimeout, "timeout-server"},
+		{&backend.TunnelTimeout, defaults.TunnelTimeout, "timeout-tunnel"},
+	} {
+		if field.defaults != nil && !annotated(field.annotation) {
+			v := *field.defaults
+			*field.backend = &v
+		}
+	}
+	if defaults.LogTag != "" {
+		backend.LogTag = defaults.LogTag
+	}
+	// hash-key overrides load-balance
+	if defaults.Balance != nil && !annotated("load-balance", "hash-key") {
+		balance := *defaults.Balance
+		backend.Balance = &balance
+	}
+}
@@ -27,12 +27,13 @@ import (
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy"
 	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/api"
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
 
 // HandleEndpoints lookups the IngressPath related endpoints and handles corresponding backend servers configuration in HAProxy
 func (s *SvcContext) HandleEndpoints(client api.HAProxyClient, store store.K8s, certs *haproxy.Certificates) (reload bool) {
 	var srvsScaled, srvsActiveAnn bool
-	endpoints, err := s.getEndpoints(store)
+	endpoints, err := s.GetEndpoints(store)
 	if err != nil {
 		logger.Warningf("Ingress '%s/%s': %s", s.ingress.Namespace, s.ingress.Name, err)
 		return
@@ -46,21 +47,63 @@ func (s *SvcContext) HandleEndpoints(client api.HAProxyClient, store store.K8s,
 	srv, _ := client.ServerGet("SRV_1", s.backendName)
 	srvsActiveAnn = s.handleSrvAnnotations(&srv, store, certs)
 	for _, srvSlot := range endpoints.HAProxySrvs {
+		// HAProxy logs and stats only know server slots
+		if pod := store.PodName(s.service.Namespace, s.service.Name, srvSlot.Address); srvSlot.Modified && pod != "" {
+			logger.Infof("service %s/%s: server '%s/%s' set to pod '%s' (%s)", s.service.Namespace, s.service.Name, s.backendName, srvSlot.Name, pod, srvSlot.Address)
+		}
 		if srvSlot.Modified || srvsActiveAnn {
-			s.updateHAProxySrv(client, srv, *srvSlot, endpoints.Port)
+			s.updateHAProxySrv(client, srv, *srvSlot, endpoints.AddrPort(srvSlot.Address))
 		}
 	}
+	s.handleSrvAdminState(client, endpoints)
 	return srvsScaled || srvsActiveAnn
 }
 
+// handleSrvAdminState enforces via runtime API the state of active servers
+// set by "backend-server-state" service annotation, servers of pods waiting for their readiness gate are drained.
+// Non ready states are applied at each sync since servers configured by a reload are ready.
+func (s *SvcContext) handleSrvAdminState(client api.HAProxyClient, endpoints *store.PortEndpoints) {
+	state := annotations.GetValue("backend-server-state", s.GetServiceAnnotations())
+	switch state {
+	case "ready", "drain", "maint":
+	default:
+		logger.Errorf("service %s/%s: annotation 'backend-server-state': invalid value '%s'", s.service.Namespace, s.service.Name, state)
+		return
+	}
+	if state == "ready" && (endpoints.SrvAdminState == "" || endpoints.SrvAdminState == "ready") && len(endpoints.AddrNotReady) == 0 {
+		return
+	}
+	previous := endpoints.SrvAdminState
+	endpoints.SrvAdminState = state
+	for _, srv := range endpoints.HAProxySrvs {
+		if srv.Address == "" {
+			continue
+		}
+		srvState := endpoints.SrvState(srv.Address)
+		if err := client.SetServerState(s.backendName, srv.Name, srvState); err != nil {
+			logger.Errorf("service %s/%s: unable to set server '%s/%s' state to %s: %s", s.service.Namespace, s.service.Name, s.backendName, srv.Name, srvState, err)
+			endpoints.SrvAdminState = previous
+			return
+		}
+	}
+	if previous != state && (previous != "" || state != "ready") {
+		logger.Infof("service %s/%s: servers of backend '%s' set to %s", s.service.Namespace, s.service.Name, s.backendName, state)
+	}
+}
+
 func (s *SvcContext) handleSrvAnnotations(srv *models.Server, store store.K8s, certs *haproxy.Certificates) bool {
 	var err error
 	oldSrv := *srv
+	appProtocolAnn := s.appProtocolAnnotations()
 	for _, a := range annotations.GetServerAnnotations(srv, store, certs) {
-		annValue := annotations.GetValue(a.GetName(), s.service.Annotations, s.ingress.Annotations, s.store.ConfigMaps.Main.Annotations)
-		err = a.Process(annValue)
+		var annValue string
+		annValue, err = s.template().GetValue(a.GetName(), s.GetServiceAnnotations(), s.ingress.Annotations, appProtocolAnn, s.store.ConfigMaps.Main.Annotations)
+		if err == nil {
+			err = annotations.Process(a, annValue)
+		}
 		if err != nil {
 			logger.Errorf("service %s/%s: annotation '%s': %s", s.service.Namespace, s.service.Name, a.GetName(), err)
+			annotations.InvalidAnnotationEvent(s.annotationRef(a.GetName(), store), a.GetName(), err)
 		}
 	}
 	if s.newBackend {
@@ -69,6 +112,7 @@ func (s *SvcContext) handleSrvAnnotations(srv *models.Server, store store.K8s, c
 	result := deep.Equal(&oldSrv, srv)
 	if len(result) != 0 {
 		logger.Debugf("Ingress '%s/%s': server options for backend '%s' were updated:%s\nReload required", s.ingress.Namespace, s.ingress.Name, s.backendName, result)
+		utils.ReloadRequired("server_options_updated", s.backendName, strings.Join(result, ", "))
 		return true
 	}
 	return false
@@ -131,6 +175,7 @@ func (s *SvcContext) scaleHAProxySrvs(endpoints *store.PortEndpoints, k8sStore s
 	if flag {
 		reload = true
 		logger.Debugf("Server slots in backend '%s' scaled to match scale-server-slots value: %d, reload required", s.backendName, srvSlots)
+		utils.ReloadRequired("server_slots_scaled", s.backendName, "scale-server-slots")
 	}
 	// Configure remaining addresses in available HAProxySrvs
 	flag = false
@@ -153,11 +198,13 @@ func (s *SvcContext) scaleHAProxySrvs(endpoints *store.PortEndpoints, k8sStore s
 	if flag {
 		reload = true
 		logger.Debugf("Server slots in backend '%s' scaled to match available endpoints, reload required", s.backendName)
+		utils.ReloadRequired("server_slots_scaled", s.backendName, "endpoints")
 	}
 	return reload
 }
 
-func (s *SvcContext) getEndpoints(k8s store.K8s) (endpoints *store.PortEndpoints, err error) {
+// GetEndpoints returns the endpoints of the service port
+func (s *SvcContext) GetEndpoints(k8s store.K8s) (endpoints *store.PortEndpoints, err error) {
 	var ok bool
 	var e *store.Endpoints
 	if ns := k8s.Namespaces[s.service.Namespace]; ns != nil {
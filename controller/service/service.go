@@ -15,8 +15,11 @@
 package service
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/go-test/deep"
 
@@ -30,6 +33,20 @@ import (
 
 var logger = utils.GetLogger()
 
+// BackendNameStrategy is the naming scheme of service backends, see "backend-name-strategy" flag
+var BackendNameStrategy string
+
+var backendNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+type backendOwner struct {
+	service string
+	active  bool
+}
+
+// backendOwners are the services owning the backend names, a name keeps its owner
+// as long as the owner uses it so that collisions are resolved identically at each sync.
+var backendOwners = make(map[string]*backendOwner)
+
 type SvcContext struct {
 	store       store.K8s
 	ingress     *store.Ingress
@@ -38,6 +55,8 @@ type SvcContext struct {
 	tcpService  bool
 	newBackend  bool
 	backendName string
+	// annotations of the service with the ones of the service port applied
+	annotations map[string]string
 }
 
 func NewCtx(k8s store.K8s, ingress *store.Ingress, path *store.IngressPath, tcpService bool) (*SvcContext, error) {
@@ -68,8 +87,63 @@ func (s *SvcContext) GetService() *store.Service {
 	return s.service
 }
 
-// GetBackendName checks if servicePort provided in IngressPath exists and construct corresponding backend name
-// Backend name is in format "ServiceNS-ServiceName-PortName"
+// GetServiceAnnotations returns the service annotations to use for the backend of the service port.
+// Annotations prefixed by the name or the number of the port, ex: "http.timeout-server" or "8080.timeout-server",
+// override the corresponding service annotations so backends of a same service can be configured independently.
+func (s *SvcContext) GetServiceAnnotations() map[string]string {
+	if s.annotations != nil {
+		return s.annotations
+	}
+	sp := s.path.SvcPortResolved
+	if sp == nil {
+		return s.service.Annotations
+	}
+	prefixes := []string{strconv.FormatInt(sp.Port, 10) + "."}
+	if sp.Name != "" {
+		prefixes = append(prefixes, sp.Name+".")
+	}
+	s.annotations = make(map[string]string, len(s.service.Annotations))
+	for name, value := range s.service.Annotations {
+		s.annotations[name] = value
+	}
+	for _, prefix := range prefixes {
+		for name, value := range s.service.Annotations {
+			if strings.HasPrefix(name, prefix) {
+				s.annotations[strings.TrimPrefix(name, prefix)] = value
+			}
+		}
+	}
+	return s.annotations
+}
+
+// annotationRef returns the reference of the resource setting the annotation, for Kubernetes Events
+func (s *SvcContext) annotationRef(name string, k8s store.K8s) utils.ResourceRef {
+	if _, ok := s.GetServiceAnnotations()[name]; ok {
+		return s.service.Ref()
+	}
+	if _, ok := s.ingress.Annotations[name]; ok {
+		return s.ingress.Ref()
+	}
+	if _, ok := k8s.ConfigMaps.Main.Annotations[name]; ok {
+		return k8s.ConfigMaps.Main.Ref()
+	}
+	// set from service port appProtocol
+	return s.service.Ref()
+}
+
+// template returns the template the annotation values of the service are expanded with
+func (s *SvcContext) template() annotations.Template {
+	return annotations.Template{
+		Namespace: s.service.Namespace,
+		Ingress:   s.ingress.Name,
+		Service:   s.service.Name,
+		K8s:       s.store,
+	}
+}
+
+// GetBackendName checks if servicePort provided in IngressPath exists and construct corresponding backend name.
+// Backend name is set by the "backend-name" annotation or else by the "backend-name-strategy",
+// a name already used by another service is rejected.
 func (s *SvcContext) GetBackendName() (string, error) {
 	if s.backendName != "" {
 		return s.backendName, nil
@@ -91,14 +165,70 @@ func (s *SvcContext) GetBackendName() (string, error) {
 		return "", fmt.Errorf("service %s: no service port matching '%d'", s.service.Name, s.path.SvcPortInt)
 	}
 	s.path.SvcPortResolved = &svcPort
-	if svcPort.Name != "" {
-		s.backendName = fmt.Sprintf("%s-%s-%s", s.service.Namespace, s.service.Name, svcPort.Name)
-	} else {
-		s.backendName = fmt.Sprintf("%s-%s-%s", s.service.Namespace, s.service.Name, strconv.Itoa(int(svcPort.Port)))
+	port := svcPort.Name
+	if port == "" {
+		port = strconv.Itoa(int(svcPort.Port))
+	}
+	name, err := s.backendNameAnnotation(port)
+	if err != nil {
+		logger.Errorf("service '%s/%s': annotation 'backend-name': %s", s.service.Namespace, s.service.Name, err)
+		annotations.InvalidAnnotationEvent(s.service.Ref(), "backend-name", err)
+	}
+	if name == "" {
+		name = defaultBackendName(s.service.Namespace, s.service.Name, port)
+	}
+	owner := s.service.Namespace + "/" + s.service.Name
+	if o, ok := backendOwners[name]; ok && o.service != owner {
+		return "", fmt.Errorf("service %s: backend name '%s' already used by service '%s'", s.service.Name, name, o.service)
 	}
+	backendOwners[name] = &backendOwner{service: owner, active: true}
+	s.backendName = name
 	return s.backendName, nil
 }
 
+// backendNameAnnotation returns the backend name set by the "backend-name" annotation.
+// When the annotation is not prefixed by the port, the port is appended to the name
+// of the backends of services with several ports.
+func (s *SvcContext) backendNameAnnotation(port string) (string, error) {
+	name := s.GetServiceAnnotations()["backend-name"]
+	if name == "" {
+		return "", nil
+	}
+	if !backendNameRegex.MatchString(name) {
+		return "", fmt.Errorf("incorrect backend name '%s'", name)
+	}
+	if len(s.service.Ports) > 1 && name == s.service.Annotations["backend-name"] {
+		name += "-" + port
+	}
+	return name, nil
+}
+
+// defaultBackendName returns the backend name of a service port according to the "backend-name-strategy":
+// "legacy" names can collide, "separator" names cannot since "_" is not allowed in Kubernetes names,
+// and "hash" names keep the legacy format with a suffix identifying the service port.
+func defaultBackendName(namespace, service, port string) string {
+	switch BackendNameStrategy {
+	case "separator":
+		return fmt.Sprintf("%s_%s_%s", namespace, service, port)
+	case "hash":
+		sum := sha256.Sum256([]byte(namespace + "/" + service + "/" + port))
+		return fmt.Sprintf("%s-%s-%s-%x", namespace, service, port, sum[:4])
+	default:
+		return fmt.Sprintf("%s-%s-%s", namespace, service, port)
+	}
+}
+
+// BackendNamesClean releases the backend names not used since the previous call, it is called once per sync.
+func BackendNamesClean() {
+	for name, owner := range backendOwners {
+		if !owner.active {
+			delete(backendOwners, name)
+			continue
+		}
+		owner.active = false
+	}
+}
+
 // HandleBackend processes a Service Context and creates/updates corresponding backend configuration in HAProxy
 func (s *SvcContext) HandleBackend(client api.HAProxyClient, store store.K8s) (reload bool, backendName string, err error) {
 	if backendName, err = s.GetBackendName(); err != nil {
@@ -124,14 +254,20 @@ func (s *SvcContext) HandleBackend(client api.HAProxyClient, store store.K8s) (r
 		s.newBackend = true
 		reload = true
 		logger.Debugf("Ingress '%s/%s': new backend '%s', reload required", s.ingress.Namespace, s.ingress.Name, backendName)
+		utils.ReloadRequired("backend_created", backendName, "")
 	}
-	for _, a := range annotations.GetBackendAnnotations(backend) {
-		annValue := annotations.GetValue(a.GetName(), s.service.Annotations, s.ingress.Annotations, store.ConfigMaps.Main.Annotations)
-		err = a.Process(annValue)
+	for _, a := range annotations.GetBackendAnnotations(backend, store, s.service.Namespace) {
+		var annValue string
+		annValue, err = s.template().GetValue(a.GetName(), s.GetServiceAnnotations(), s.ingress.Annotations, store.ConfigMaps.Main.Annotations)
+		if err == nil {
+			err = annotations.Process(a, annValue)
+		}
 		if err != nil {
 			logger.Errorf("service '%s/%s': annotation '%s': %s", s.service.Namespace, s.service.Name, a.GetName(), err)
+			annotations.InvalidAnnotationEvent(s.annotationRef(a.GetName(), store), a.GetName(), err)
 		}
 	}
+	s.applyNamespaceDefaults(backend, store)
 	// Update Backend
 	result := deep.Equal(oldBackend, backend)
 	if len(result) != 0 {
@@ -140,9 +276,13 @@ func (s *SvcContext) HandleBackend(client api.HAProxyClient, store store.K8s) (r
 		}
 		reload = true
 		logger.Debugf("Ingress '%s/%s': backend '%s' updated: %s\nReload required", s.ingress.Namespace, s.ingress.Name, backend.Name, result)
+		utils.ReloadRequired("backend_updated", backend.Name, strings.Join(result, ", "))
 	}
 	change, errSnipp := annotations.UpdateBackendCfgSnippet(client, backend.Name)
 	logger.Error(errSnipp)
+	if change {
+		utils.ReloadRequired("config_snippet_updated", backend.Name, "")
+	}
 	reload = reload || change
 
 	return reload, backendName, nil
@@ -0,0 +1,46 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strconv"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/handler"
+)
+
+// handleSNIServices routes the SNI hostnames of the tcp-services ConfigMap to their services
+// via the ssl-passthrough of the HTTPS frontend, other hostnames keep being offloaded.
+func (c *HAProxyController) handleSNIServices() {
+	cm := c.Store.ConfigMaps.TCPServices
+	if cm == nil || cm.Status == DELETED {
+		return
+	}
+	for host, value := range cm.Annotations {
+		if _, err := strconv.Atoi(host); err == nil {
+			continue
+		}
+		ingress, path, err := handler.SNIService(c.Store, host, value)
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+		reload, err := c.handleIngressPath(ingress, host, path, nil)
+		if err != nil {
+			logger.Errorf("tcp-services: SNI '%s': %s", host, err)
+			continue
+		}
+		c.reload = c.reload || reload
+	}
+}
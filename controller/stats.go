@@ -0,0 +1,211 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/haproxytech/client-native/v2/models"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/metrics"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+// backendLabels are the Kubernetes objects a backend is configured from
+type backendLabels struct {
+	namespace string
+	ingress   string
+	service   string
+	// pod names by server name
+	pods map[string]string
+	// ingresses sorted by name
+	ingresses []*store.Ingress
+}
+
+// collectStats exports, every "stats-metrics-period", HAProxy stats as admin API metrics
+// labeled with the Kubernetes objects the backends and servers are configured from,
+// which HAProxy own exporter cannot do.
+func (c *HAProxyController) collectStats() {
+	if c.OSArgs.StatsMetricsPeriod == 0 || !c.ready || time.Since(c.lastStatsSync) < c.OSArgs.StatsMetricsPeriod {
+		return
+	}
+	c.lastStatsSync = time.Now()
+	info, err := c.Client.InfoGet()
+	if err != nil {
+		logger.Errorf("stats metrics: %s", err)
+		return
+	}
+	stats, err := c.Client.StatsGet()
+	if err != nil {
+		logger.Errorf("stats metrics: %s", err)
+		return
+	}
+	for _, g := range metrics.StatsGauges {
+		g.Reset()
+	}
+	// HAProxy runs a single process, with several ones the last values are kept
+	for _, i := range info {
+		if i.Info == nil {
+			continue
+		}
+		setGauge(metrics.ProcessUptime, i.Info.Uptime)
+		setGauge(metrics.ProcessConnections, i.Info.CurrConns)
+		setGauge(metrics.ProcessIdle, i.Info.IdlePct)
+	}
+	labels := c.backendLabels()
+	for _, collection := range stats {
+		for _, stat := range collection.Stats {
+			if stat.Stats == nil {
+				continue
+			}
+			switch stat.Type {
+			case "frontend":
+				exportFrontendStats(stat)
+			case "backend":
+				exportBackendStats(stat, labels[stat.Name])
+			case "server":
+				exportServerStats(stat, labels[stat.BackendName])
+			}
+		}
+	}
+}
+
+// backendLabels returns the labels of the backends of services by backend name
+func (c *HAProxyController) backendLabels() map[string]*backendLabels {
+	labels := make(map[string]*backendLabels)
+	for _, ns := range c.Store.Namespaces {
+		if !ns.Relevant {
+			continue
+		}
+		ingresses := make(map[string][]*store.Ingress)
+		for _, ingress := range ns.Ingresses {
+			if ingress.Status == DELETED {
+				continue
+			}
+			services := make(map[string]struct{})
+			if ingress.DefaultBackend != nil {
+				services[ingress.DefaultBackend.SvcName] = struct{}{}
+			}
+			for _, rule := range ingress.Rules {
+				for _, path := range rule.Paths {
+					services[path.SvcName] = struct{}{}
+				}
+			}
+			for service := range services {
+				ingresses[service] = append(ingresses[service], ingress)
+			}
+		}
+		for _, endpoints := range ns.Endpoints {
+			svcIngresses := ingresses[endpoints.Service]
+			sort.Slice(svcIngresses, func(i, j int) bool { return svcIngresses[i].Name < svcIngresses[j].Name })
+			names := make([]string, 0, len(svcIngresses))
+			for _, ingress := range svcIngresses {
+				names = append(names, ingress.Name)
+			}
+			for _, portEndpoints := range endpoints.Ports {
+				if portEndpoints.BackendName == "" {
+					continue
+				}
+				backend := &backendLabels{
+					namespace: ns.Name,
+					ingress:   strings.Join(names, ","),
+					service:   endpoints.Service,
+					pods:      make(map[string]string),
+					ingresses: svcIngresses,
+				}
+				for _, srv := range portEndpoints.HAProxySrvs {
+					if srv.Address != "" {
+						backend.pods[srv.Name] = endpoints.Pods[srv.Address]
+					}
+				}
+				labels[portEndpoints.BackendName] = backend
+			}
+		}
+	}
+	return labels
+}
+
+func exportFrontendStats(stat *models.NativeStat) {
+	s := stat.Stats
+	setGauge(metrics.FrontendSessions, s.Scur, stat.Name)
+	setGauge(metrics.FrontendSessionsTotal, s.Stot, stat.Name)
+	setGauge(metrics.FrontendRequestsTotal, s.ReqTot, stat.Name)
+	setGauge(metrics.FrontendBytesIn, s.Bin, stat.Name)
+	setGauge(metrics.FrontendBytesOut, s.Bout, stat.Name)
+	setResponses(metrics.FrontendResponses, s, stat.Name)
+}
+
+func exportBackendStats(stat *models.NativeStat, backend *backendLabels) {
+	if backend == nil {
+		backend = &backendLabels{}
+	}
+	s := stat.Stats
+	labels := []string{stat.Name, backend.namespace, backend.ingress, backend.service}
+	setGauge(metrics.BackendSessions, s.Scur, labels...)
+	setGauge(metrics.BackendSessionsTotal, s.Stot, labels...)
+	setGauge(metrics.BackendQueue, s.Qcur, labels...)
+	setGauge(metrics.BackendResponseTime, s.Rtime, labels...)
+	setGauge(metrics.BackendConnectionErrors, s.Econ, labels...)
+	setGauge(metrics.BackendResponseErrors, s.Eresp, labels...)
+	setResponses(metrics.BackendResponses, s, labels...)
+}
+
+// exportServerStats skips the disabled servers of scale-server-slots,
+// they have no address and would only add series.
+func exportServerStats(stat *models.NativeStat, backend *backendLabels) {
+	s := stat.Stats
+	pod, ok := "", true
+	if backend != nil {
+		pod, ok = backend.pods[stat.Name]
+	} else {
+		backend = &backendLabels{}
+	}
+	if !ok && s.Status == "MAINT" {
+		return
+	}
+	labels := []string{stat.BackendName, stat.Name, backend.namespace, backend.service, pod}
+	up := 0
+	if strings.HasPrefix(s.Status, "UP") || s.Status == "no check" {
+		up = 1
+	}
+	metrics.ServerUp.Set(up, labels...)
+	setGauge(metrics.ServerSessions, s.Scur, labels...)
+	setGauge(metrics.ServerSessionsTotal, s.Stot, labels...)
+	setGauge(metrics.ServerResponseTime, s.Rtime, labels...)
+	setGauge(metrics.ServerConnectionErrors, s.Econ, labels...)
+}
+
+// setResponses sets the HTTP responses of a proxy by status class
+func setResponses(g *metrics.GaugeVec, s *models.NativeStatStats, labels ...string) {
+	for code, value := range map[string]*int64{
+		"1xx":   s.Hrsp1xx,
+		"2xx":   s.Hrsp2xx,
+		"3xx":   s.Hrsp3xx,
+		"4xx":   s.Hrsp4xx,
+		"5xx":   s.Hrsp5xx,
+		"other": s.HrspOther,
+	} {
+		setGauge(g, value, append(append([]string{}, labels...), code)...)
+	}
+}
+
+// setGauge sets the gauge to a stat value, stats not reported by HAProxy are skipped
+func setGauge(g *metrics.GaugeVec, value *int64, labels ...string) {
+	if value != nil {
+		g.Set(int(*value), labels...)
+	}
+}
@@ -16,20 +16,30 @@ import (
 	"github.com/haproxytech/kubernetes-ingress/controller/store"
 )
 
-func UpdateIngress(client *kubernetes.Clientset, k store.K8s, channel chan SyncIngress) {
+// UpdateIngress updates the status of ingresses with the addresses of the publish service of their kind,
+// internal or not as reported by isInternal.
+func UpdateIngress(client *kubernetes.Clientset, k store.K8s, channel chan SyncIngress, isInternal func(*store.Ingress) bool) {
 	addresses := []string{}
+	internalAddresses := []string{}
 	for status := range channel {
+		curAddr := &addresses
+		if status.Internal {
+			curAddr = &internalAddresses
+		}
 		// Published Service updated: Update all Ingresses
-		if status.Service != nil && getServiceAddresses(status.Service, &addresses) {
+		if status.Service != nil && getServiceAddresses(status.Service, curAddr) {
 			logger.Debug("Addresses of Ingress Controller service changed, status of all ingress resources are going to be updated")
 			for _, ns := range k.Namespaces {
 				for _, ingress := range k.Namespaces[ns.Name].Ingresses {
-					logger.Error(updateIngressStatus(client, ingress, addresses))
+					if isInternal(ingress) != status.Internal {
+						continue
+					}
+					logger.Error(updateIngressStatus(client, ingress, *curAddr))
 				}
 			}
 		}
 		if status.Ingress != nil {
-			logger.Error(updateIngressStatus(client, status.Ingress, addresses))
+			logger.Error(updateIngressStatus(client, status.Ingress, *curAddr))
 		}
 	}
 }
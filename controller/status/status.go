@@ -11,4 +11,6 @@ var logger = utils.GetLogger()
 type SyncIngress struct {
 	Service *corev1.Service
 	Ingress *store.Ingress
+	// Internal is set for the publish service of internal ingresses and for internal ingresses
+	Internal bool
 }
@@ -16,6 +16,7 @@ package store
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
@@ -85,11 +86,14 @@ type ingressNetworkingV1Beta1Strategy struct {
 
 func (n ingressNetworkingV1Beta1Strategy) ConvertIngress() *Ingress {
 	return &Ingress{
-		APIVersion:  NETWORKINGV1BETA1,
-		Namespace:   n.ig.GetNamespace(),
-		Name:        n.ig.GetName(),
-		Class:       getIgClass(n.ig.Spec.IngressClassName),
-		Annotations: CopyAnnotations(n.ig.GetAnnotations()),
+		APIVersion:            NETWORKINGV1BETA1,
+		Namespace:             n.ig.GetNamespace(),
+		Name:                  n.ig.GetName(),
+		UID:                   string(n.ig.GetUID()),
+		Created:               n.ig.GetCreationTimestamp().Time,
+		Class:                 getIgClass(n.ig.Spec.IngressClassName),
+		Annotations:           CopyAnnotations(n.ig.GetAnnotations()),
+		ControllerAnnotations: ControllerAnnotations(n.ig.GetAnnotations()),
 		Rules: func(ingressRules []networkingv1beta1.IngressRule) map[string]*IngressRule {
 			rules := make(map[string]*IngressRule)
 			for _, k8sRule := range ingressRules {
@@ -182,10 +186,13 @@ type ingressExtensionsStrategy struct {
 
 func (e ingressExtensionsStrategy) ConvertIngress() *Ingress {
 	return &Ingress{
-		APIVersion:  EXTENSIONSV1BETA1,
-		Namespace:   e.ig.GetNamespace(),
-		Name:        e.ig.GetName(),
-		Annotations: CopyAnnotations(e.ig.GetAnnotations()),
+		APIVersion:            EXTENSIONSV1BETA1,
+		Namespace:             e.ig.GetNamespace(),
+		Name:                  e.ig.GetName(),
+		UID:                   string(e.ig.GetUID()),
+		Created:               e.ig.GetCreationTimestamp().Time,
+		Annotations:           CopyAnnotations(e.ig.GetAnnotations()),
+		ControllerAnnotations: ControllerAnnotations(e.ig.GetAnnotations()),
 		Rules: func(ingressRules []extensionsv1beta1.IngressRule) map[string]*IngressRule {
 			rules := make(map[string]*IngressRule)
 			for _, k8sRule := range ingressRules {
@@ -265,11 +272,14 @@ type ingressNetworkingV1Strategy struct {
 
 func (n ingressNetworkingV1Strategy) ConvertIngress() *Ingress {
 	return &Ingress{
-		APIVersion:  NETWORKINGV1,
-		Namespace:   n.ig.GetNamespace(),
-		Name:        n.ig.GetName(),
-		Class:       getIgClass(n.ig.Spec.IngressClassName),
-		Annotations: CopyAnnotations(n.ig.GetAnnotations()),
+		APIVersion:            NETWORKINGV1,
+		Namespace:             n.ig.GetNamespace(),
+		Name:                  n.ig.GetName(),
+		UID:                   string(n.ig.GetUID()),
+		Created:               n.ig.GetCreationTimestamp().Time,
+		Class:                 getIgClass(n.ig.Spec.IngressClassName),
+		Annotations:           CopyAnnotations(n.ig.GetAnnotations()),
+		ControllerAnnotations: ControllerAnnotations(n.ig.GetAnnotations()),
 		Rules: func(ingressRules []networkingv1.IngressRule) map[string]*IngressRule {
 			rules := make(map[string]*IngressRule)
 			for _, k8sRule := range ingressRules {
@@ -361,12 +371,30 @@ func getIgClass(className *string) string {
 	return *className
 }
 
+// AnnotationPrefixes are the prefixes of the controller annotations
+var AnnotationPrefixes = []string{"haproxy.org/", "haproxy.com/"}
+
+// ControllerAnnotations returns the sorted names, without prefix, of the annotations set with a controller prefix
+func ControllerAnnotations(in map[string]string) []string {
+	var names []string
+	for name := range in {
+		for _, prefix := range AnnotationPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, Intern(name))
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // CopyAnnotations returns a copy of annotations map and removes prefixe from annotations name
 func CopyAnnotations(in map[string]string) map[string]string {
 	out := make(map[string]string, len(in))
 	for name, value := range in {
 		split := strings.SplitN(name, "/", 2)
-		out[split[len(split)-1]] = value
+		out[Intern(split[len(split)-1])] = value
 	}
 	return out
 }
@@ -0,0 +1,70 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+// NewPortEndpoints returns the PortEndpoints of the given addresses and their ports.
+// Port is set to the port shared by most addresses (defaultPort without addresses), ports of the
+// other addresses, e.g. named target ports resolved differently by pods during a migration, are kept in AddrPorts.
+func NewPortEndpoints(defaultPort int64, addrPorts map[string]int64) *PortEndpoints {
+	count := make(map[int64]int)
+	for _, port := range addrPorts {
+		count[port]++
+	}
+	port := defaultPort
+	for p, n := range count {
+		if n > count[port] || (n == count[port] && p < port) {
+			port = p
+		}
+	}
+	endpoints := &PortEndpoints{
+		Port:        port,
+		AddrCount:   len(addrPorts),
+		AddrNew:     make(map[string]struct{}, len(addrPorts)),
+		HAProxySrvs: make([]*HAProxySrv, 0, len(addrPorts)),
+	}
+	for addr, p := range addrPorts {
+		endpoints.AddrNew[addr] = struct{}{}
+		if p != port {
+			if endpoints.AddrPorts == nil {
+				endpoints.AddrPorts = make(map[string]int64)
+			}
+			endpoints.AddrPorts[addr] = p
+		}
+	}
+	return endpoints
+}
+
+// AddrPort returns the port of the given address
+func (e *PortEndpoints) AddrPort(addr string) int64 {
+	if port, ok := e.AddrPorts[addr]; ok {
+		return port
+	}
+	return e.Port
+}
+
+// SrvState returns the runtime admin state of the server of the given address: maint without address,
+// the state set by "backend-server-state" annotation, or drain for pods waiting for their readiness gate.
+func (e *PortEndpoints) SrvState(addr string) string {
+	if addr == "" {
+		return "maint"
+	}
+	if e.SrvAdminState != "" && e.SrvAdminState != "ready" {
+		return e.SrvAdminState
+	}
+	if _, ok := e.AddrNotReady[addr]; ok {
+		return "drain"
+	}
+	return "ready"
+}
@@ -18,7 +18,10 @@ func (k *K8s) EventNamespace(ns *Namespace, data *Namespace) (updateRequired boo
 	updateRequired = false
 	switch data.Status {
 	case ADDED:
-		_ = k.GetNamespace(data.Name)
+		k.GetNamespace(data.Name).Annotations = data.Annotations
+	case MODIFIED:
+		k.GetNamespace(data.Name).Annotations = data.Annotations
+		updateRequired = true
 	case DELETED:
 		_, ok := k.Namespaces[data.Name]
 		if ok {
@@ -213,6 +216,8 @@ func (k *K8s) EventEndpoints(ns *Namespace, data *Endpoints, syncHAproxySrvs fun
 			return false
 		}
 		if oldEndpoints.Equal(newEndpoints) {
+			// an address may be reused by another pod
+			oldEndpoints.Pods = newEndpoints.Pods
 			return false
 		}
 		for portName, oldPortEdpts := range oldEndpoints.Ports {
@@ -234,6 +239,7 @@ func (k *K8s) EventEndpoints(ns *Namespace, data *Endpoints, syncHAproxySrvs fun
 				data.Status = MODIFIED
 				return k.EventEndpoints(ns, data, syncHAproxySrvs)
 			}
+			old.Pods = data.Pods
 			return updateRequired
 		}
 		ns.Endpoints[data.Service] = data
@@ -300,6 +306,8 @@ func (k *K8s) EventConfigMap(ns *Namespace, data *ConfigMap) (updateRequired boo
 		cm = k.ConfigMaps.Errorfiles
 	case k.ConfigMaps.PatternFiles.Namespace == ns.Name && k.ConfigMaps.PatternFiles.Name == data.Name:
 		cm = k.ConfigMaps.PatternFiles
+	case k.ConfigMaps.HostOwnership.Namespace == ns.Name && k.ConfigMaps.HostOwnership.Name == data.Name:
+		cm = k.ConfigMaps.HostOwnership
 	default:
 		return false
 	}
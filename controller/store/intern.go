@@ -0,0 +1,58 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import "sync"
+
+// internPoolSize is the number of strings above which the intern pool is reset
+const internPoolSize = 16384
+
+// internPool deduplicates strings repeated across k8s objects (namespaces, label and annotation keys, port names)
+// so a single copy of each of them is kept in memory.
+// Keys are set by users and namespaces come and go, so the pool is reset when it is full:
+// the strings still in use are pooled again while the ones of deleted objects are released.
+var internPool = struct {
+	sync.Mutex
+	strings map[string]string
+}{strings: make(map[string]string)}
+
+// Intern returns the pooled copy of s
+func Intern(s string) string {
+	if s == "" {
+		return s
+	}
+	internPool.Lock()
+	defer internPool.Unlock()
+	if pooled, ok := internPool.strings[s]; ok {
+		return pooled
+	}
+	if len(internPool.strings) >= internPoolSize {
+		internPool.strings = make(map[string]string)
+	}
+	internPool.strings[s] = s
+	return s
+}
+
+// InternKeys returns a copy of m whose keys are pooled
+func InternKeys(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return m
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[Intern(k)] = v
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import "github.com/haproxytech/kubernetes-ingress/controller/utils"
+
+// crAPIVersion is the API version of the controller custom resources
+const crAPIVersion = "core.haproxy.org/v1alpha1"
+
+// Ref returns the reference of the ingress used for Kubernetes Events
+func (i *Ingress) Ref() utils.ResourceRef {
+	return utils.ResourceRef{APIVersion: i.APIVersion, Kind: "Ingress", Namespace: i.Namespace, Name: i.Name, UID: i.UID}
+}
+
+// Ref returns the reference of the service used for Kubernetes Events
+func (s *Service) Ref() utils.ResourceRef {
+	return utils.ResourceRef{APIVersion: "v1", Kind: "Service", Namespace: s.Namespace, Name: s.Name, UID: s.UID}
+}
+
+// Ref returns the reference of the ConfigMap used for Kubernetes Events
+func (c *ConfigMap) Ref() utils.ResourceRef {
+	return utils.ResourceRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: c.Namespace, Name: c.Name, UID: c.UID}
+}
+
+// Ref returns the reference of the BlueGreen CR used for Kubernetes Events
+func (b *BlueGreen) Ref() utils.ResourceRef {
+	return utils.ResourceRef{APIVersion: crAPIVersion, Kind: "BlueGreen", Namespace: b.Namespace, Name: b.Name, UID: b.UID}
+}
+
+// Ref returns the reference of the TrafficSplit CR used for Kubernetes Events
+func (t *TrafficSplit) Ref() utils.ResourceRef {
+	return utils.ResourceRef{APIVersion: crAPIVersion, Kind: "TrafficSplit", Namespace: t.Namespace, Name: t.Name, UID: t.UID}
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/haproxytech/client-native/v2/models"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// CR_INGRESS_CLASS_LABEL selects the Global and Defaults CRs of the controllers of an ingress class
+const CR_INGRESS_CLASS_LABEL = "haproxy.org/ingress-class" //nolint:golint,stylecheck
+
+// ControllerCR is a Global or Defaults custom resource
+type ControllerCR struct {
+	// Name is the namespace/name of the CR
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	Created   time.Time
+	Global    *models.Global
+	Defaults  *models.Defaults
+}
+
+// SelectCR returns the Global or Defaults CR used by the controller and the other CRs it selects, which are ignored.
+// CRs are selected by the "cr-selector" when set, or else by their ingress class label; CRs labeled with the ingress
+// class of the controller are then used over the ones without ingress class label, which apply to all controllers.
+// The oldest CR is used when several ones are selected.
+func (c CustomResources) SelectCR(crs map[string]*ControllerCR) (selected *ControllerCR, ignored []string) {
+	var candidates []*ControllerCR
+	for _, cr := range crs {
+		if c.selects(cr) {
+			candidates = append(candidates, cr)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if c.specific(a) != c.specific(b) {
+			return c.specific(a)
+		}
+		if !a.Created.Equal(b.Created) {
+			return a.Created.Before(b.Created)
+		}
+		return a.Name < b.Name
+	})
+	for _, cr := range candidates[1:] {
+		ignored = append(ignored, cr.Name)
+	}
+	return candidates[0], ignored
+}
+
+func (c CustomResources) selects(cr *ControllerCR) bool {
+	if c.Selector != nil {
+		return c.Selector.Matches(labels.Set(cr.Labels))
+	}
+	class, ok := cr.Labels[CR_INGRESS_CLASS_LABEL]
+	return !ok || class == c.IngressClass
+}
+
+// specific returns true if the CR is explicitly selected for the controller
+func (c CustomResources) specific(cr *ControllerCR) bool {
+	if c.Selector != nil {
+		return true
+	}
+	_, ok := cr.Labels[CR_INGRESS_CLASS_LABEL]
+	return ok
+}
@@ -17,8 +17,10 @@ package store
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/haproxytech/client-native/v2/models"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/haproxytech/kubernetes-ingress/controller/utils"
 )
@@ -29,11 +31,30 @@ type K8s struct {
 	NamespacesAccess NamespacesWatch
 	ConfigMaps       ConfigMaps
 	CR               CustomResources
+	// SameNamespaceRefs restricts the references of ingresses and services to their namespace
+	SameNamespaceRefs bool
 }
 
 type CustomResources struct {
+	// Global and Defaults are the configurations of the CRs selected by the controller, see SelectCR
 	Global   *models.Global
 	Defaults *models.Defaults
+	// Globals and DefaultsCRs are all the Global and Defaults CRs by namespace/name
+	Globals     map[string]*ControllerCR
+	DefaultsCRs map[string]*ControllerCR
+	// NamespaceDefaults are the configurations of the namespace scoped Defaults CRs selected
+	// by namespace, NamespaceDefaultsCRs are all of them by namespace/name
+	NamespaceDefaults    map[string]*models.Defaults
+	NamespaceDefaultsCRs map[string]*ControllerCR
+	// Selector selects the Global and Defaults CRs of the controller, they are selected
+	// by their ingress class label when it is nil
+	Selector      labels.Selector
+	IngressClass  string
+	Denylists     map[string][]*DenylistEntry
+	BlueGreens    map[string]*BlueGreen
+	TrafficSplits map[string]*TrafficSplit
+	// AnnotationPolicies are only read from the namespace of the controller ConfigMap
+	AnnotationPolicies map[string]*AnnotationPolicy
 }
 
 type NamespacesWatch struct {
@@ -44,9 +65,10 @@ type NamespacesWatch struct {
 var logger = utils.GetLogger()
 
 func NewK8sStore(args utils.OSArgs) K8s {
-	return K8s{
-		Namespaces:     make(map[string]*Namespace),
-		IngressClasses: make(map[string]*IngressClass),
+	k := K8s{
+		Namespaces:        make(map[string]*Namespace),
+		IngressClasses:    make(map[string]*IngressClass),
+		SameNamespaceRefs: args.ConfigMapAccessMode == "namespace",
 		NamespacesAccess: NamespacesWatch{
 			Whitelist: map[string]struct{}{},
 			Blacklist: map[string]struct{}{},
@@ -68,9 +90,49 @@ func NewK8sStore(args utils.OSArgs) K8s {
 				Namespace: args.ConfigMapPatternFiles.Namespace,
 				Name:      args.ConfigMapPatternFiles.Name,
 			},
+			HostOwnership: &ConfigMap{
+				Namespace: args.ConfigMapHostOwnership.Namespace,
+				Name:      args.ConfigMapHostOwnership.Name,
+			},
+		},
+		CR: CustomResources{
+			Globals:              make(map[string]*ControllerCR),
+			DefaultsCRs:          make(map[string]*ControllerCR),
+			NamespaceDefaults:    make(map[string]*models.Defaults),
+			NamespaceDefaultsCRs: make(map[string]*ControllerCR),
+			IngressClass:         args.IngressClass,
+			Denylists:            make(map[string][]*DenylistEntry),
+			BlueGreens:           make(map[string]*BlueGreen),
+			TrafficSplits:        make(map[string]*TrafficSplit),
+			AnnotationPolicies:   make(map[string]*AnnotationPolicy),
 		},
-		CR: CustomResources{},
 	}
+	if args.CRSelector != "" {
+		selector, err := labels.Parse(args.CRSelector)
+		if err != nil {
+			logger.Panicf("cr-selector: %s", err)
+		}
+		k.CR.Selector = selector
+	}
+	return k
+}
+
+// ExpireDenylists flags as DELETED Denylist entries whose expiry date passed
+// and returns true if any entry expired since last call.
+func (k K8s) ExpireDenylists() (expired bool) {
+	now := time.Now()
+	for _, entries := range k.CR.Denylists {
+		for _, entry := range entries {
+			if entry.Status == DELETED || entry.Expires == nil {
+				continue
+			}
+			if now.After(*entry.Expires) {
+				entry.Status = DELETED
+				expired = true
+			}
+		}
+	}
+	return expired
 }
 
 func (k K8s) Clean() {
@@ -180,6 +242,18 @@ func (k K8s) GetNamespace(name string) *Namespace {
 	return newNamespace
 }
 
+// PodName returns the name of the pod behind an endpoint address of a service, "" when unknown
+func (k K8s) PodName(namespace, service, addr string) string {
+	ns, ok := k.Namespaces[namespace]
+	if !ok {
+		return ""
+	}
+	if endpoints, ok := ns.Endpoints[service]; ok {
+		return endpoints.Pods[addr]
+	}
+	return ""
+}
+
 // FetchSecret fetches secret with secretPath format "namespace/secretName"
 // if format is just "secretName" defaultNs param will be used.
 func (k K8s) FetchSecret(secretPath, defaultNs string) (*Secret, error) {
@@ -192,6 +266,9 @@ func (k K8s) FetchSecret(secretPath, defaultNs string) (*Secret, error) {
 	} else {
 		secretName = parts[0] // only secretname is here
 	}
+	if err := k.CheckNamespaceRef(defaultNs, secretNamespace); err != nil {
+		return nil, err
+	}
 	ns, namespaceOK := k.Namespaces[secretNamespace]
 	if !namespaceOK {
 		return nil, fmt.Errorf("namespace '%s' does not exist", secretNamespace)
@@ -203,6 +280,23 @@ func (k K8s) FetchSecret(secretPath, defaultNs string) (*Secret, error) {
 	return secret, nil
 }
 
+// CheckNamespaceRef returns an error when "configmap-access-mode" forbids a resource of namespace "from"
+// to reference a resource of namespace "to". Resources of the controller, like its ConfigMap, have no namespace.
+func (k K8s) CheckNamespaceRef(from, to string) error {
+	if !k.SameNamespaceRefs || from == "" || from == to {
+		return nil
+	}
+	return fmt.Errorf("namespace '%s' cannot be referenced from namespace '%s' (configmap-access-mode)", to, from)
+}
+
+// CheckPatternFilesRef returns an error when resources of a namespace cannot reference pattern files
+func (k K8s) CheckPatternFilesRef(namespace string) error {
+	if k.ConfigMaps.PatternFiles == nil {
+		return nil
+	}
+	return k.CheckNamespaceRef(namespace, k.ConfigMaps.PatternFiles.Namespace)
+}
+
 func (k K8s) isRelevantNamespace(namespace string) bool {
 	if namespace == "" {
 		return false
@@ -17,7 +17,7 @@ package store
 import "bytes"
 
 func (a *ServicePort) Equal(b *ServicePort) bool {
-	if a.Name != b.Name || a.Protocol != b.Protocol || a.Port != b.Port {
+	if a.Name != b.Name || a.Protocol != b.Protocol || a.AppProtocol != b.AppProtocol || a.Port != b.Port {
 		return false
 	}
 	return true
@@ -123,6 +123,18 @@ func (a *Ingress) Equal(b *Ingress) bool {
 	if a.DefaultBackend != b.DefaultBackend && !a.DefaultBackend.Equal(b.DefaultBackend) {
 		return false
 	}
+	return annotationsEqual(a.Annotations, a.DeniedAnnotations, b.Annotations, b.DeniedAnnotations) &&
+		stringsEqual(a.ControllerAnnotations, b.ControllerAnnotations)
+}
+
+// Equal compares two namespaces, ignores statuses and namespace resources
+func (a *Namespace) Equal(b *Namespace) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a.Name != b.Name {
+		return false
+	}
 	if len(a.Annotations) != len(b.Annotations) {
 		return false
 	}
@@ -143,21 +155,16 @@ func (a *Service) Equal(b *Service) bool {
 	if a.Name != b.Name {
 		return false
 	}
-	if len(a.Annotations) != len(b.Annotations) {
+	if !annotationsEqual(a.Annotations, a.DeniedAnnotations, b.Annotations, b.DeniedAnnotations) ||
+		!stringsEqual(a.ControllerAnnotations, b.ControllerAnnotations) {
 		return false
 	}
-	for name, value1 := range a.Annotations {
-		value2 := b.Annotations[name]
-		if value1 != value2 {
-			return false
-		}
-	}
 	if len(a.Ports) != len(b.Ports) {
 		return false
 	}
 	for index, p1 := range a.Ports {
 		p2 := b.Ports[index]
-		if p1.Name != p2.Name || p1.Protocol != p2.Protocol || p1.Port != p2.Port {
+		if p1.Name != p2.Name || p1.Protocol != p2.Protocol || p1.AppProtocol != p2.AppProtocol || p1.Port != p2.Port {
 			return false
 		}
 	}
@@ -245,6 +252,22 @@ func (oldE *PortEndpoints) Equal(newE *PortEndpoints) bool {
 	if oldE.AddrCount != newE.AddrCount {
 		return false
 	}
+	if len(oldE.AddrPorts) != len(newE.AddrPorts) {
+		return false
+	}
+	for addr, port := range oldE.AddrPorts {
+		if newE.AddrPorts[addr] != port {
+			return false
+		}
+	}
+	if len(oldE.AddrNotReady) != len(newE.AddrNotReady) {
+		return false
+	}
+	for addr := range oldE.AddrNotReady {
+		if _, ok := newE.AddrNotReady[addr]; !ok {
+			return false
+		}
+	}
 	for _, srv := range oldE.HAProxySrvs {
 		if srv.Address == "" {
 			continue
@@ -260,3 +283,35 @@ func (oldE *PortEndpoints) Equal(newE *PortEndpoints) bool {
 	}
 	return true
 }
+
+// annotationsEqual compares the annotations of two resources as set in Kubernetes,
+// i.e. including the ones denied by AnnotationPolicies.
+func annotationsEqual(a, deniedA, b, deniedB map[string]string) bool {
+	if len(a)+len(deniedA) != len(b)+len(deniedB) {
+		return false
+	}
+	for _, annotations := range []map[string]string{a, deniedA} {
+		for name, value1 := range annotations {
+			value2, ok := b[name]
+			if !ok {
+				value2, ok = deniedB[name]
+			}
+			if !ok || value1 != value2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
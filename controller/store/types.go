@@ -14,12 +14,15 @@
 
 package store
 
+import "time"
+
 // ServicePort describes port of a service
 type ServicePort struct {
-	Name     string
-	Protocol string
-	Port     int64
-	Status   Status
+	Name        string
+	Protocol    string
+	AppProtocol string
+	Port        int64
+	Status      Status
 }
 
 type HAProxySrv struct {
@@ -37,6 +40,11 @@ type PortEndpoints struct {
 	AddrCount       int
 	AddrNew         map[string]struct{}
 	HAProxySrvs     []*HAProxySrv
+	SrvAdminState   string // Runtime state set by "backend-server-state" annotation
+	// Ports of addresses not using Port
+	AddrPorts map[string]int64
+	// Addresses of pods waiting for their readiness gate, their servers are drained
+	AddrNotReady map[string]struct{}
 }
 
 // Endpoints describes endpoints of a service
@@ -45,29 +53,38 @@ type Endpoints struct {
 	Service   string
 	Ports     map[string]*PortEndpoints
 	Status    Status
+	// Pod names by address, used as labels of HAProxy stats metrics
+	Pods map[string]string
 }
 
 // Service is useful data from k8s structures about service
 type Service struct {
 	Namespace   string
 	Name        string
+	UID         string
 	Ports       []ServicePort
 	Addresses   []string // Used only for publish-service
 	DNS         string
 	Annotations map[string]string
 	Status      Status
+
+	// DeniedAnnotations are the annotations removed by AnnotationPolicies
+	DeniedAnnotations map[string]string
+	// ControllerAnnotations are the names, with their prefix, of the annotations set with a controller prefix
+	ControllerAnnotations []string
 }
 
 // Namespace is useful data from k8s structures about namespace
 type Namespace struct {
-	_         [0]int
-	Name      string
-	Relevant  bool
-	Ingresses map[string]*Ingress
-	Endpoints map[string]*Endpoints
-	Services  map[string]*Service
-	Secret    map[string]*Secret
-	Status    Status
+	_           [0]int
+	Name        string
+	Relevant    bool
+	Annotations map[string]string
+	Ingresses   map[string]*Ingress
+	Endpoints   map[string]*Endpoints
+	Services    map[string]*Service
+	Secret      map[string]*Secret
+	Status      Status
 }
 
 type IngressClass struct {
@@ -102,12 +119,19 @@ type Ingress struct {
 	APIVersion     string
 	Namespace      string
 	Name           string
+	UID            string
+	Created        time.Time
 	Class          string
 	Annotations    map[string]string
 	Rules          map[string]*IngressRule
 	DefaultBackend *IngressPath
 	TLS            map[string]*IngressTLS
 	Status         Status
+
+	// DeniedAnnotations are the annotations removed by AnnotationPolicies
+	DeniedAnnotations map[string]string
+	// ControllerAnnotations are the names, with their prefix, of the annotations set with a controller prefix
+	ControllerAnnotations []string
 }
 
 // IngressTLS describes the transport layer security associated with an Ingress.
@@ -118,16 +142,18 @@ type IngressTLS struct {
 }
 
 type ConfigMaps struct {
-	Main         *ConfigMap
-	TCPServices  *ConfigMap
-	Errorfiles   *ConfigMap
-	PatternFiles *ConfigMap
+	Main          *ConfigMap
+	TCPServices   *ConfigMap
+	Errorfiles    *ConfigMap
+	PatternFiles  *ConfigMap
+	HostOwnership *ConfigMap
 }
 
 // ConfigMap is useful data from k8s structures about configmap
 type ConfigMap struct {
 	Namespace   string
 	Name        string
+	UID         string
 	Loaded      bool
 	Annotations map[string]string
 	Status      Status
@@ -140,3 +166,56 @@ type Secret struct {
 	Data      map[string][]byte
 	Status    Status
 }
+
+// DenylistEntry is an IP address or CIDR range from a Denylist CR,
+// Expires is nil for entries without expiry date
+type DenylistEntry struct {
+	Address string
+	Expires *time.Time
+	Status  Status
+}
+
+// BlueGreen binds a host/path from a BlueGreen CR to the services
+// of the active and standby deployments
+type BlueGreen struct {
+	Namespace     string
+	Name          string
+	UID           string
+	Created       time.Time
+	Host          string
+	Active        *IngressPath
+	Standby       *IngressPath
+	StandbyWeight int64
+}
+
+// TrafficSplit binds a host/path from a TrafficSplit CR to weighted services
+type TrafficSplit struct {
+	Namespace string
+	Name      string
+	UID       string
+	Created   time.Time
+	Host      string
+	Backends  []*TrafficSplitBackend
+}
+
+// AnnotationPolicy forbids or bounds annotations of ingresses and services,
+// in all namespaces when Namespaces is empty
+type AnnotationPolicy struct {
+	Name       string
+	Namespaces map[string]struct{}
+	Forbidden  map[string]struct{}
+	Limits     map[string]AnnotationLimit
+}
+
+// AnnotationLimit is the maximum value of an annotation, only one of the fields is set
+type AnnotationLimit struct {
+	MaxTime  *int64 // milliseconds
+	MaxSize  *int64 // bytes
+	MaxValue *int64
+}
+
+// TrafficSplitBackend is a service of a TrafficSplit with its weight
+type TrafficSplitBackend struct {
+	Path   *IngressPath
+	Weight int64
+}
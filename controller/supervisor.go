@@ -0,0 +1,132 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/renameio"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/metrics"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// crashCaptures is the number of configurations kept in the "haproxy-crash-dir"
+const crashCaptures = 5
+
+// supervisor holds the configurations HAProxy ran with, see superviseHAProxy
+type supervisor struct {
+	// loaded is the configuration of the last HAProxy start, reload or restart
+	loaded []byte
+	// lastValid is the last loaded configuration HAProxy was found running with
+	lastValid []byte
+	crashes   int
+}
+
+// haproxyLoaded records the configuration HAProxy was just started, reloaded or restarted with
+func (c *HAProxyController) haproxyLoaded() {
+	config, err := ioutil.ReadFile(c.Cfg.Env.MainCFGFile)
+	if err != nil {
+		logger.Errorf("supervisor: %s", err)
+		return
+	}
+	c.supervisor.loaded = config
+}
+
+// superviseHAProxy checks, every sync period, that HAProxy runs. When it crashed, it is reported
+// with an "HAProxyCrashed" Warning Event on the controller pod and a metric, its configuration is
+// captured in the "haproxy-crash-dir", and HAProxy is started again with the last configuration
+// it was found running with. Changes made since are applied again with the next configuration change.
+func (c *HAProxyController) superviseHAProxy() {
+	if !c.ready {
+		return
+	}
+	status := c.haproxyProcess.Status()
+	if status == nil {
+		c.supervisor.lastValid = c.supervisor.loaded
+		return
+	}
+	c.supervisor.crashes++
+	logger.Errorf("HAProxy crashed: %s", status)
+	config := "current"
+	current, err := ioutil.ReadFile(c.Cfg.Env.MainCFGFile)
+	if err != nil {
+		logger.Errorf("supervisor: %s", err)
+	} else {
+		if c.OSArgs.HAProxyCrashDir != "" {
+			logger.Error(captureCrashConfig(c.OSArgs.HAProxyCrashDir, current))
+		}
+		if c.supervisor.lastValid != nil && !bytes.Equal(current, c.supervisor.lastValid) {
+			if err = renameio.WriteFile(c.Cfg.Env.MainCFGFile, c.supervisor.lastValid, 0644); err != nil {
+				logger.Errorf("supervisor: last valid configuration not restored: %s", err)
+			} else {
+				config = "last_valid"
+			}
+		}
+	}
+	metrics.HAProxyCrashes.Inc(config)
+	msg := fmt.Sprintf("HAProxy crashed (%d times since controller start): %s, restarted with the %s configuration", c.supervisor.crashes, status, strings.ReplaceAll(config, "_", " "))
+	utils.WarningEvent(controllerPodRef(), "HAProxyCrashed", msg)
+	c.notify("crash", nil, 0, status)
+	if err = c.haproxyService("start"); err != nil {
+		logger.Errorf("supervisor: HAProxy not started: %s", err)
+		return
+	}
+	logger.Warning(msg)
+	c.haproxyLoaded()
+}
+
+// captureCrashConfig writes the configuration HAProxy crashed with to the crash directory,
+// only the last crashCaptures ones are kept.
+func captureCrashConfig(dir string, config []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := filepath.Join(dir, "haproxy-"+time.Now().UTC().Format("20060102T150405")+".cfg")
+	if err := ioutil.WriteFile(name, config, 0600); err != nil {
+		return err
+	}
+	logger.Warningf("configuration HAProxy crashed with captured in '%s'", name)
+	files, err := filepath.Glob(filepath.Join(dir, "haproxy-*.cfg"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for len(files) > crashCaptures {
+		if err = os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// controllerPodRef refers to the pod of the controller, set via the downward API
+// POD_NAME and POD_NAMESPACE environment variables; Events are not recorded without them.
+func controllerPodRef() utils.ResourceRef {
+	return utils.ResourceRef{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Namespace:  os.Getenv("POD_NAMESPACE"),
+		Name:       os.Getenv("POD_NAME"),
+	}
+}
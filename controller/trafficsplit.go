@@ -0,0 +1,99 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/haproxy/rules"
+	"github.com/haproxytech/kubernetes-ingress/controller/route"
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// handleTrafficSplit routes the host/path of a TrafficSplit CR to its backends proportionally to their weights.
+// A random number drawn once per request selects a backend via custom routes,
+// the last weighted backend being the map files route used for the remaining requests.
+func (c *HAProxyController) handleTrafficSplit(ts *store.TrafficSplit) (reload bool, err error) {
+	if ns, ok := c.Store.Namespaces[ts.Namespace]; !ok || !ns.Relevant {
+		return
+	}
+	if len(ts.Backends) != 0 && c.crRouteRejected(ts.Ref(), ts.Host, ts.Backends[0].Path.Path) {
+		return
+	}
+	// TrafficSplit CR is handled as an Ingress without annotations
+	ingress := &store.Ingress{
+		Namespace:   ts.Namespace,
+		Name:        ts.Name,
+		Annotations: map[string]string{},
+	}
+	backends := make([]string, len(ts.Backends))
+	last := -1
+	var total int64
+	for i, b := range ts.Backends {
+		var backendReload bool
+		backendReload, backends[i], err = c.handleServiceBackend(ingress, b.Path)
+		if err != nil {
+			return
+		}
+		reload = reload || backendReload
+		if b.Weight > 0 {
+			total += b.Weight
+			last = i
+		}
+	}
+	if last == -1 {
+		return reload, fmt.Errorf("no backend with a positive weight")
+	}
+	// when weighted routing is denied all requests are routed to the last weighted backend
+	denied := total != ts.Backends[last].Weight && c.weightedRouteDenied(ts.Ref(), 100-ts.Backends[last].Weight*100/total)
+	varName := "split_" + utils.Hash([]byte(ts.Namespace+"/"+ts.Name))
+	if !denied {
+		// the random number is only drawn for the requests of the TrafficSplit host/path
+		cond := route.HostPathCond(route.Route{Host: ts.Host, Path: ts.Backends[last].Path})
+		for _, frontend := range []string{c.Cfg.FrontHTTP, c.Cfg.FrontHTTPS} {
+			logger.Error(c.Cfg.HAProxyRules.AddRule(rules.ReqSetVar{
+				Name:       varName,
+				Scope:      "txn",
+				Expression: fmt.Sprintf("rand(%d)", total),
+				CondTest:   cond,
+			}, false, frontend))
+		}
+	}
+	var lower int64
+	for i, b := range ts.Backends {
+		// custom routes of backends no longer weighted are deleted by route.CustomRoutesClean
+		if b.Weight == 0 || i == last || denied {
+			continue
+		}
+		var routeReload bool
+		routeReload, err = route.AddCustomRoute(route.Route{
+			Host:        ts.Host,
+			Path:        b.Path,
+			BackendName: backends[i],
+		}, fmt.Sprintf("var(txn.%s) -m int %d:%d", varName, lower, lower+b.Weight-1), c.Client)
+		if err != nil {
+			return
+		}
+		reload = reload || routeReload
+		lower += b.Weight
+	}
+	err = route.AddHostPathRoute(route.Route{
+		Host:        ts.Host,
+		Path:        ts.Backends[last].Path,
+		BackendName: backends[last],
+	}, c.Cfg.MapFiles)
+	return reload, err
+}
@@ -0,0 +1,189 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/store"
+)
+
+// trimFunc drops from an object received from k8s API the fields unused by the controller
+// before it is stored in the informer cache.
+type trimFunc func(obj runtime.Object)
+
+// trimmedInformer registers in factory the informer of objType with objects trimmed by trim.
+// This is the equivalent of informers transform functions, not available in this client-go version.
+func trimmedInformer(factory informers.SharedInformerFactory, namespace, resource string, objType runtime.Object, trim trimFunc) cache.SharedIndexInformer {
+	return factory.InformerFor(objType, func(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		lw := cache.NewListWatchFromClient(client.CoreV1().RESTClient(), resource, namespace, fields.Everything())
+		return cache.NewSharedIndexInformer(trimmedListWatch(lw, trim), objType, resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	})
+}
+
+// trimmedListWatch wraps lw so that listed and watched objects are trimmed by trim
+func trimmedListWatch(lw *cache.ListWatch, trim trimFunc) *cache.ListWatch {
+	list, watchFn := lw.ListFunc, lw.WatchFunc
+	lw.ListFunc = func(options metav1.ListOptions) (runtime.Object, error) {
+		obj, err := list(options)
+		if err != nil {
+			return nil, err
+		}
+		err = meta.EachListItem(obj, func(item runtime.Object) error {
+			trim(item)
+			return nil
+		})
+		return obj, err
+	}
+	lw.WatchFunc = func(options metav1.ListOptions) (watch.Interface, error) {
+		w, err := watchFn(options)
+		if err != nil {
+			return nil, err
+		}
+		return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+			if event.Type != watch.Error && event.Type != watch.Bookmark {
+				trim(event.Object)
+			}
+			return event, true
+		}), nil
+	}
+	return lw
+}
+
+// trimObjectMeta drops managed fields and last applied configuration, and interns namespace and labels
+func trimObjectMeta(obj runtime.Object) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	m.SetManagedFields(nil)
+	m.SetNamespace(store.Intern(m.GetNamespace()))
+	m.SetLabels(store.InternKeys(m.GetLabels()))
+	if annotations := m.GetAnnotations(); annotations != nil {
+		delete(annotations, corev1.LastAppliedConfigAnnotation)
+		m.SetAnnotations(store.InternKeys(annotations))
+	}
+}
+
+// trimEndpoints keeps only the endpoints ports and addresses IPs, with their node and pod names.
+// Not ready addresses are kept for the pods waiting for their readiness gate.
+func trimEndpoints(obj runtime.Object) {
+	trimObjectMeta(obj)
+	endpoints, ok := obj.(*corev1.Endpoints)
+	if !ok {
+		return
+	}
+	for i := range endpoints.Subsets {
+		subset := &endpoints.Subsets[i]
+		for _, addresses := range [][]corev1.EndpointAddress{subset.Addresses, subset.NotReadyAddresses} {
+			for j := range addresses {
+				address := &addresses[j]
+				*address = corev1.EndpointAddress{IP: address.IP, NodeName: internPtr(address.NodeName), TargetRef: trimTargetRef(address.TargetRef)}
+			}
+		}
+		for j := range subset.Ports {
+			subset.Ports[j].Name = store.Intern(subset.Ports[j].Name)
+		}
+	}
+}
+
+// trimEndpointSlice keeps only the addresses and readiness of endpoints, with their node and pod names.
+// Not ready endpoints are kept for the pods waiting for their readiness gate.
+func trimEndpointSlice(obj runtime.Object) {
+	trimObjectMeta(obj)
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return
+	}
+	for i, endpoint := range slice.Endpoints {
+		slice.Endpoints[i] = discoveryv1.Endpoint{
+			Addresses:  endpoint.Addresses,
+			Conditions: discoveryv1.EndpointConditions{Ready: endpoint.Conditions.Ready},
+			NodeName:   internPtr(endpoint.NodeName),
+			TargetRef:  trimTargetRef(endpoint.TargetRef),
+		}
+	}
+	for i := range slice.Ports {
+		if slice.Ports[i].Name != nil {
+			name := store.Intern(*slice.Ports[i].Name)
+			slice.Ports[i].Name = &name
+		}
+	}
+}
+
+// trimNode keeps only the node metadata, nodes are only selected by their labels
+func trimNode(obj runtime.Object) {
+	trimObjectMeta(obj)
+	if node, ok := obj.(*corev1.Node); ok {
+		node.Spec = corev1.NodeSpec{}
+		node.Status = corev1.NodeStatus{}
+	}
+}
+
+// trimTargetRef keeps only the kind and the name of the pod of an endpoint
+func trimTargetRef(ref *corev1.ObjectReference) *corev1.ObjectReference {
+	if ref == nil || ref.Kind != "Pod" {
+		return nil
+	}
+	return &corev1.ObjectReference{Kind: "Pod", Name: ref.Name}
+}
+
+func internPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	interned := store.Intern(*s)
+	return &interned
+}
+
+// endpointSlicesInformer registers in factory the EndpointSlices informer, trimmed by trimEndpointSlice
+// and indexed by service so that the slices of a service can be merged.
+func endpointSlicesInformer(factory informers.SharedInformerFactory, namespace string) cache.SharedIndexInformer {
+	return factory.InformerFor(&discoveryv1.EndpointSlice{}, func(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		lw := cache.NewListWatchFromClient(client.DiscoveryV1().RESTClient(), "endpointslices", namespace, fields.Everything())
+		return cache.NewSharedIndexInformer(trimmedListWatch(lw, trimEndpointSlice), &discoveryv1.EndpointSlice{}, resync, cache.Indexers{
+			cache.NamespaceIndex:      cache.MetaNamespaceIndexFunc,
+			endpointSliceServiceIndex: endpointSliceService,
+		})
+	})
+}
+
+// secretsFieldSelector excludes secret types never used by the controller,
+// they are usually the most numerous secrets of a cluster.
+var secretsFieldSelector = fields.AndSelectors(
+	fields.OneTermNotEqualSelector("type", string(corev1.SecretTypeServiceAccountToken)),
+	fields.OneTermNotEqualSelector("type", "helm.sh/release.v1"),
+).String()
+
+// secretsInformer registers in factory a typed secrets informer filtered by secretsFieldSelector
+func secretsInformer(factory informers.SharedInformerFactory, namespace string) cache.SharedIndexInformer {
+	return factory.InformerFor(&corev1.Secret{}, func(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		return coreinformers.NewFilteredSecretInformer(client, namespace, resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, func(options *metav1.ListOptions) {
+			options.FieldSelector = secretsFieldSelector
+		})
+	})
+}
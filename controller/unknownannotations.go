@@ -0,0 +1,70 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/controller/annotations"
+	"github.com/haproxytech/kubernetes-ingress/controller/metrics"
+	"github.com/haproxytech/kubernetes-ingress/controller/utils"
+)
+
+// reportUnknownAnnotations reports the annotations of ingresses and services set with a controller prefix
+// whose names are unknown, such as typos, which would otherwise be silently ignored. They are logged and
+// reported with an "UnknownAnnotation" Warning Event once, and exported by the unknown annotations metric.
+func (c *HAProxyController) reportUnknownAnnotations() {
+	metrics.UnknownAnnotations.Reset()
+	unknown := make(map[string]struct{})
+	for _, ns := range c.Store.Namespaces {
+		if !ns.Relevant {
+			continue
+		}
+		for _, ingress := range ns.Ingresses {
+			if ingress.Status != DELETED && c.igClassIsSupported(ingress) {
+				c.reportUnknown(unknown, ingress.Ref(), ingress.ControllerAnnotations)
+			}
+		}
+		for _, service := range ns.Services {
+			if service.Status != DELETED {
+				c.reportUnknown(unknown, service.Ref(), service.ControllerAnnotations)
+			}
+		}
+	}
+	c.unknownAnnotations = unknown
+}
+
+func (c *HAProxyController) reportUnknown(unknown map[string]struct{}, ref utils.ResourceRef, names []string) {
+	for _, name := range names {
+		// names are prefixed
+		annotation := name[strings.Index(name, "/")+1:]
+		if annotations.Known(annotation) {
+			continue
+		}
+		metrics.UnknownAnnotations.Set(1, ref.Kind, ref.Namespace, ref.Name, name)
+		key := fmt.Sprintf("%s/%s/%s/%s", ref.Kind, ref.Namespace, ref.Name, name)
+		unknown[key] = struct{}{}
+		if _, ok := c.unknownAnnotations[key]; ok {
+			continue
+		}
+		msg := fmt.Sprintf("unknown annotation '%s' ignored", name)
+		if suggestion := annotations.Suggest(annotation); suggestion != "" {
+			msg += fmt.Sprintf(", did you mean '%s'?", suggestion)
+		}
+		logger.Warningf("%s '%s/%s': %s", ref.Kind, ref.Namespace, ref.Name, msg)
+		utils.WarningEvent(ref, "UnknownAnnotation", msg)
+	}
+}
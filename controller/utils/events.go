@@ -0,0 +1,90 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// eventTTL is how long an identical Event is not recorded again,
+// client-go aggregates Events repeated more often than that.
+const eventTTL = 10 * time.Minute
+
+// ResourceRef identifies the Kubernetes resource an Event is about
+type ResourceRef struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	UID        string
+}
+
+type event struct {
+	ref       ResourceRef
+	eventType string
+	reason    string
+	message   string
+}
+
+var events struct {
+	mu       sync.Mutex
+	recorder func(ref ResourceRef, eventType, reason, message string)
+	seen     map[event]time.Time
+}
+
+// SetEventRecorder sets the function creating Kubernetes Events,
+// Events are not recorded until it is set.
+func SetEventRecorder(recorder func(ref ResourceRef, eventType, reason, message string)) {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	events.recorder = recorder
+}
+
+// WarningEvent records a warning Event about a resource.
+// Configuration is synced periodically so an identical Event is recorded once per eventTTL,
+// or again once a normal Event about the same resource was recorded in between.
+func WarningEvent(ref ResourceRef, reason, message string) {
+	recordEvent(event{ref: ref, eventType: "Warning", reason: reason, message: message})
+}
+
+// NormalEvent records a normal Event about a resource, like WarningEvent identical Events are not repeated,
+// unless a warning Event about the same resource was recorded in between.
+func NormalEvent(ref ResourceRef, reason, message string) {
+	recordEvent(event{ref: ref, eventType: "Normal", reason: reason, message: message})
+}
+
+func recordEvent(e event) {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	if events.recorder == nil {
+		return
+	}
+	now := time.Now()
+	if events.seen == nil {
+		events.seen = make(map[event]time.Time)
+	}
+	if recorded, ok := events.seen[e]; ok && now.Sub(recorded) < eventTTL {
+		return
+	}
+	for seen, recorded := range events.seen {
+		// A warning resolved by a normal Event, or the other way around, is new when it happens again
+		if now.Sub(recorded) >= eventTTL || (seen.ref == e.ref && seen.eventType != e.eventType) {
+			delete(events.seen, seen)
+		}
+	}
+	events.seen[e] = now
+	events.recorder(e.ref, e.eventType, e.reason, e.message)
+}
@@ -75,6 +75,7 @@ type OSArgs struct { //nolint:maligned
 	ConfigMapErrorFiles        NamespaceValue `long:"configmap-errorfiles" description:"configmap used to define custom error pages associated to HTTP error codes" default:""`
 	ConfigMapPatternFiles      NamespaceValue `long:"configmap-patternfiles" description:"configmap used to provide a list of pattern files to use in haproxy configuration " default:""`
 	KubeConfig                 string         `long:"kubeconfig" default:"" description:"combined with -e. location of kube config file"`
+	CRSelector                 string         `long:"cr-selector" default:"" description:"label selector of the Global and Defaults custom resources of the controller, by default the ones labeled with its ingress class or without ingress class label"`
 	IngressClass               string         `long:"ingress.class" default:"" description:"ingress.class to monitor in multiple controllers environment"`
 	EmptyIngressClass          bool           `long:"empty-ingress-class" description:"empty-ingress-class manages the behavior in case an ingress has no explicit ingress class annotation. true: to process, false: to skip"`
 	PublishService             string         `long:"publish-service" default:"" description:"Takes the form namespace/name. The controller mirrors the address of this service's endpoints to the load-balancer status of all Ingress objects it satisfies"`
@@ -82,6 +83,8 @@ type OSArgs struct { //nolint:maligned
 	NamespaceBlacklist         []string       `long:"namespace-blacklist" description:"blacklisted namespaces"`
 	SyncPeriod                 time.Duration  `long:"sync-period" default:"5s" description:"Sets the period at which the controller syncs HAProxy configuration file"`
 	CacheResyncPeriod          time.Duration  `long:"cache-resync-period" default:"10m" description:"Sets the underlying Shared Informer resync period: resyncing controller with informers cache"`
+	KubeAPIQPS                 float32        `long:"kube-api-qps" default:"5" description:"maximum queries per second to the Kubernetes API"`
+	KubeAPIBurst               int            `long:"kube-api-burst" default:"10" description:"maximum burst of queries to the Kubernetes API"`
 	LogLevel                   LogLevelValue  `long:"log" default:"info" description:"level of log messages you can see"`
 	PprofEnabled               bool           `short:"p" description:"enable pprof over https"`
 	External                   bool           `short:"e" long:"external" description:"use as external Ingress Controller (out of k8s cluster)"`
@@ -92,11 +95,60 @@ type OSArgs struct { //nolint:maligned
 	DisableHTTPS               bool           `long:"disable-https" description:"toggle to disable the HTTPs frontend"`
 	HTTPBindPort               int64          `long:"http-bind-port" default:"80" description:"port to listen on for HTTP traffic"`
 	HTTPSBindPort              int64          `long:"https-bind-port" default:"443" description:"port to listen on for HTTPS traffic"`
+	SSLPassthroughBindPort     int64          `long:"ssl-passthrough-bind-port" default:"0" description:"dedicated port to listen on for SSL passthrough traffic. HTTPS port is shared if 0"`
+	SSLPassthroughChainAddr    string         `long:"ssl-passthrough-chain-address" default:"127.0.0.1" description:"IPv4 address the HTTPS frontend listens on for the traffic chained by the SSL passthrough frontend"`
+	SSLPassthroughChainPort    int64          `long:"ssl-passthrough-chain-port" default:"0" description:"port the HTTPS frontend listens on for the traffic chained by the SSL passthrough frontend. HTTPS port is used if 0"`
+	SSLPassthroughChainProxy   string         `long:"ssl-passthrough-chain-proxy" default:"v2" choice:"v1" choice:"v2" choice:"none" description:"PROXY protocol version sent by the SSL passthrough frontend to the HTTPS frontend"`
 	IPV4BindAddr               string         `long:"ipv4-bind-address" default:"0.0.0.0" description:"IPv4 address the Ingress Controller listens on (if enabled)"`
 	IPV6BindAddr               string         `long:"ipv6-bind-address" default:"::" description:"IPv6 address the Ingress Controller listens on (if enabled)"`
 	Program                    string         `long:"program" description:"path to HAProxy program. NOTE: works only with External mode"`
 	CfgDir                     string         `long:"config-dir" description:"path to HAProxy configuration directory. NOTE: works only in External mode"`
-	RuntimeDir                 string         `long:"runtime-dir" description:"path to HAProxy runtime directory. NOTE: works only in External mode"`
+	RuntimeDir                 string         `long:"runtime-dir" description:"path to HAProxy runtime directory"`
+	StateDir                   string         `long:"state-dir" description:"path to HAProxy servers state directory"`
+	CertDir                    string         `long:"certs-dir" description:"path to the directory of certificates. certs of HAProxy configuration directory if empty"`
+	MapDir                     string         `long:"maps-dir" description:"path to the directory of map files. maps of HAProxy configuration directory if empty"`
+	TransactionDir             string         `long:"transactions-dir" description:"path to the directory of configuration transactions. transactions of HAProxy configuration directory if empty"`
+	LocalNodeOnly              bool           `long:"localnode-only" description:"only use the endpoints of the node the controller runs on, node name is read from NODE_NAME environment variable or else is the hostname. All endpoints are used for services without endpoints on the node"`
+	EndpointsNodeSelector      string         `long:"endpoints-node-selector" default:"" description:"label selector of the nodes whose endpoints are used as backend servers, endpoints of other nodes are excluded"`
 	DisableServiceExternalName bool           `long:"disable-service-external-name" description:"disable forwarding to ExternalName Services due to CVE-2021-25740"`
 	UseWiths6Overlay           bool           `long:"with-s6-overlay" description:"use s6 overlay to start/stpop/reload HAProxy"`
+	AdminPort                  int64          `long:"admin-port" default:"0" description:"port of the admin API exposing HAProxy runtime data, requests are authenticated with Kubernetes tokens. Disabled if 0"`
+	AdminAddress               string         `long:"admin-address" default:"127.0.0.1" description:"address the admin API listens on, only reachable from the pod (e.g. via kubectl port-forward) by default"`
+	AdminTLSCert               string         `long:"admin-tls-cert" default:"" description:"path to the certificate the admin API is served with over HTTPS, requires --admin-tls-key. Plain HTTP if empty"`
+	AdminTLSKey                string         `long:"admin-tls-key" default:"" description:"path to the private key of --admin-tls-cert"`
+	WildcardCertsNamespace     string         `long:"wildcard-certificates-namespace" default:"" description:"namespace of TLS secrets automatically used for ingress hosts without TLS secret when their certificate matches the host"`
+	RestrictCrossNsSecrets     bool           `long:"restrict-cross-namespace-secrets" description:"only allow ingress TLS secrets from other namespaces when the secret namespace allows it via the tls-secret-allowed-namespaces annotation"`
+	InternalIngressClass       string         `long:"internal-ingress.class" default:"" description:"ingress.class of ingresses only exposed via the internal binds"`
+	InternalHTTPBindPort       int64          `long:"internal-http-bind-port" default:"0" description:"port to listen on for internal HTTP traffic. Disabled if 0"`
+	InternalHTTPSBindPort      int64          `long:"internal-https-bind-port" default:"0" description:"port to listen on for internal HTTPS traffic. Disabled if 0"`
+	InternalIPV4BindAddr       string         `long:"internal-ipv4-bind-address" default:"0.0.0.0" description:"IPv4 address the Ingress Controller listens on for internal traffic (if enabled)"`
+	InternalIPV6BindAddr       string         `long:"internal-ipv6-bind-address" default:"::" description:"IPv6 address the Ingress Controller listens on for internal traffic (if enabled)"`
+	InternalPublishService     string         `long:"internal-publish-service" default:"" description:"Takes the form namespace/name. The controller mirrors the address of this service's endpoints to the load-balancer status of internal Ingress objects"`
+	DriftCheckPeriod           time.Duration  `long:"drift-check-period" default:"0s" description:"Sets the period at which the controller compares HAProxy runtime state with the desired one. Disabled if 0"`
+	StatsMetricsPeriod         time.Duration  `long:"stats-metrics-period" default:"0s" description:"Sets the period at which HAProxy stats are exported as admin API metrics labeled with Kubernetes objects. Disabled if 0"`
+	PodReadinessGate           string         `long:"pod-readiness-gate" default:"" description:"condition type set to True on backend pods declaring it as readiness gate once their HAProxy server is up. Disabled if empty"`
+	AlertCheckPeriod           time.Duration  `long:"alert-check-period" default:"0s" description:"Sets the period at which backends error rate and queue are compared with the alert-error-rate and alert-queue thresholds of ingresses. Disabled if 0"`
+	AlertWebhook               string         `long:"alert-webhook" default:"" description:"URL alerts are posted to as JSON, in addition to ingress Events"`
+	NotificationWebhook        string         `long:"notification-webhook" default:"" description:"URL the result of HAProxy reloads, restarts and configuration syncs is posted to as JSON"`
+	DriftCorrection            bool           `long:"drift-correction" description:"restore the desired HAProxy runtime state when a drift is detected"`
+	DelayBindsUntilReady       bool           `long:"delay-binds-until-ready" description:"keep HTTP and HTTPS binds down until the initial configuration is applied"`
+	HandoffDir                 string         `long:"handoff-dir" default:"" description:"directory, usually a shared volume, where the configuration is saved for a replacement controller to start with it"`
+	MaxReloadsPerMinute        int            `long:"max-reloads-per-minute" default:"0" description:"maximum number of HAProxy reloads per minute, changes requiring a reload beyond it are applied by a single delayed reload. Unlimited if 0"`
+	HealthzBindPort            int64          `long:"healthz-bind-port" default:"1042" description:"port of the healthz frontend used for readiness and liveness probes"`
+	StatsBindPort              int64          `long:"stats-bind-port" default:"1024" description:"port of the stats frontend serving HAProxy stats page and Prometheus metrics"`
+	LocalPeerPort              int64          `long:"localpeer-port" default:"10000" description:"port the local HAProxy peer listens on to keep stick tables across reloads"`
+	PprofPort                  int64          `long:"pprof-port" default:"6060" description:"port the pprof server enabled with -p listens on locally"`
+	RuntimeSocket              string         `long:"runtime-socket" default:"" description:"path of HAProxy runtime API socket. haproxy-runtime-api.sock of the runtime directory if empty"`
+	MasterSocket               string         `long:"master-socket" default:"" description:"path of HAProxy master CLI socket, runtime commands are sent through it to the current worker when set"`
+	ValidateConfig             bool           `long:"validate-config" description:"check every HAProxy configuration, with the auxiliary one, with haproxy -c in a staging directory before applying it"`
+	HAProxyCrashDir            string         `long:"haproxy-crash-dir" default:"" description:"directory where the configurations HAProxy crashed with are captured, not captured if empty"`
+	CanaryRollout              NamespaceValue `long:"canary-rollout" default:"" description:"namespace/name of the ConfigMap coordinating the replicas applying risky changes first, disabled if empty"`
+	CanaryBakeTime             time.Duration  `long:"canary-bake-time" default:"5m" description:"time the canary replica must stay healthy before other replicas apply a risky change"`
+	HealthzPath                string         `long:"healthz-path" default:"/healthz" description:"path answered by the built-in healthz service"`
+	FrontendsHealthzPath       string         `long:"frontends-healthz-path" default:"" description:"path answered by the HTTP and HTTPS frontends on each of their ports for load balancer health checks, disabled if empty"`
+	HealthzFailOnSyncError     bool           `long:"healthz-fail-on-sync-error" description:"healthz reports a failure while the last HAProxy configuration apply failed"`
+	HostPathConflictPolicy     string         `long:"host-path-conflict-policy" default:"oldest" choice:"oldest" choice:"class" choice:"reject" description:"policy applied when ingresses of different namespaces claim the same host and path: the oldest ingress wins, ingresses with a class win over ingresses without class, or all claims are rejected"`
+	ConfigMapAccessMode        string         `long:"configmap-access-mode" default:"all" choice:"all" choice:"namespace" description:"namespaces whose secrets and pattern files can be referenced by ingress and service annotations: any namespace or only the namespace of the annotated resource. References of the controller ConfigMap are not restricted"`
+	BackendNameStrategy        string         `long:"backend-name-strategy" default:"legacy" choice:"legacy" choice:"separator" choice:"hash" description:"naming scheme of service backends: legacy '<namespace>-<service>-<port>' names, which can collide, or collision-free '<namespace>_<service>_<port>' or '<namespace>-<service>-<port>-<hash>' names"`
+	ConfigMapHostOwnership     NamespaceValue `long:"configmap-host-ownership" default:"" description:"configmap restricting the namespaces allowed to claim host patterns, ingresses claiming a host owned by other namespaces are ignored"`
 }
@@ -0,0 +1,77 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ReloadReason describes a change requiring an HAProxy reload
+type ReloadReason struct {
+	// Reason identifies the kind of change, ex: "backend_created".
+	// It is used as label value so it must not contain object names.
+	Reason string
+	// Object is the changed object, ex: a backend name
+	Object string
+	// Field is the changed field or a description of the change, optional
+	Field string
+}
+
+func (r ReloadReason) String() string {
+	if r.Field == "" {
+		return fmt.Sprintf("%s '%s'", r.Reason, r.Object)
+	}
+	return fmt.Sprintf("%s '%s' (%s)", r.Reason, r.Object, r.Field)
+}
+
+var reloadReasons struct {
+	mu      sync.Mutex
+	reasons []ReloadReason
+	seen    map[ReloadReason]struct{}
+}
+
+// ReloadRequired records a change requiring an HAProxy reload.
+// A change recorded again before the reload, ex: by a replayed sync, is ignored.
+func ReloadRequired(reason, object, field string) {
+	r := ReloadReason{Reason: reason, Object: object, Field: field}
+	reloadReasons.mu.Lock()
+	defer reloadReasons.mu.Unlock()
+	if reloadReasons.seen == nil {
+		reloadReasons.seen = make(map[ReloadReason]struct{})
+	}
+	if _, ok := reloadReasons.seen[r]; ok {
+		return
+	}
+	reloadReasons.seen[r] = struct{}{}
+	reloadReasons.reasons = append(reloadReasons.reasons, r)
+}
+
+// ReloadReasons returns the changes recorded since the last call, in recording order
+func ReloadReasons() []ReloadReason {
+	reloadReasons.mu.Lock()
+	defer reloadReasons.mu.Unlock()
+	reasons := reloadReasons.reasons
+	reloadReasons.reasons = nil
+	reloadReasons.seen = nil
+	return reasons
+}
+
+// PendingReloadReasons returns the changes recorded since the last call of ReloadReasons, without resetting them
+func PendingReloadReasons() []ReloadReason {
+	reloadReasons.mu.Lock()
+	defer reloadReasons.mu.Unlock()
+	return append([]ReloadReason(nil), reloadReasons.reasons...)
+}
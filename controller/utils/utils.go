@@ -16,7 +16,10 @@ package utils
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"hash/fnv"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -51,28 +54,44 @@ func ParseInt(data string) (v int64, err error) {
 	return
 }
 
+// MaxTime is the largest time value in milliseconds accepted by HAProxy (about 24 days)
+const MaxTime = math.MaxInt32
+
+var timeUnits = []struct {
+	suffix string
+	ms     int64
+}{
+	// "ms" must be checked before "m" and "s"
+	{"ms", 1},
+	{"s", 1000},
+	{"m", 60 * 1000},
+	{"h", 60 * 60 * 1000},
+	{"d", 24 * 60 * 60 * 1000},
+}
+
+// ParseTime parses a time value with an optional unit (ms, s, m, h, d), milliseconds by default,
+// and returns it in milliseconds. Negative values and values above MaxTime are rejected.
 func ParseTime(data string) (*int64, error) {
-	var v int64
-	var err error
+	number, unit := data, int64(1)
+	for _, u := range timeUnits {
+		if strings.HasSuffix(data, u.suffix) {
+			number, unit = strings.TrimSuffix(data, u.suffix), u.ms
+			break
+		}
+	}
+	v, err := strconv.ParseInt(number, 10, 64)
 	switch {
-	case strings.HasSuffix(data, "ms"):
-		v, err = strconv.ParseInt(strings.TrimSuffix(data, "ms"), 10, 64)
-	case strings.HasSuffix(data, "s"):
-		v, err = strconv.ParseInt(strings.TrimSuffix(data, "s"), 10, 64)
-		v *= 1000
-	case strings.HasSuffix(data, "m"):
-		v, err = strconv.ParseInt(strings.TrimSuffix(data, "m"), 10, 64)
-		v = v * 1000 * 60
-	case strings.HasSuffix(data, "h"):
-		v, err = strconv.ParseInt(strings.TrimSuffix(data, "h"), 10, 64)
-		v = v * 1000 * 60 * 60
-	case strings.HasSuffix(data, "d"):
-		v, err = strconv.ParseInt(strings.TrimSuffix(data, "d"), 10, 64)
-		v = v * 1000 * 60 * 60 * 24
-	default:
-		v, err = strconv.ParseInt(data, 10, 64)
+	case err != nil && errors.Is(err, strconv.ErrRange):
+		return nil, fmt.Errorf("time '%s' exceeds the maximum of %dms", data, MaxTime)
+	case err != nil:
+		return nil, fmt.Errorf("invalid time '%s': an integer with an optional unit (ms, s, m, h, d) is expected", data)
+	case v < 0:
+		return nil, fmt.Errorf("invalid time '%s': negative values are not allowed", data)
+	case v > MaxTime/unit:
+		return nil, fmt.Errorf("time '%s' exceeds the maximum of %dms", data, MaxTime)
 	}
-	return &v, err
+	v *= unit
+	return &v, nil
 }
 
 func ParseSize(size string) (*int64, error) {
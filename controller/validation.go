@@ -0,0 +1,76 @@
+// Copyright 2021 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// validateStaged checks, when "validate-config" is set, an HAProxy configuration with "haproxy -c" as HAProxy
+// would load it: in master-worker mode and with the auxiliary configuration. Files are copied in the "staging"
+// directory of the configuration directory, where the last refused configuration is kept for debugging.
+func (c *HAProxyController) validateStaged(config []byte) error {
+	if !c.OSArgs.ValidateConfig {
+		return nil
+	}
+	dir := filepath.Join(c.Cfg.Env.CfgDir, "staging")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	mainCfg := filepath.Join(dir, filepath.Base(c.Cfg.Env.MainCFGFile))
+	if err := ioutil.WriteFile(mainCfg, config, 0600); err != nil {
+		return err
+	}
+	args := []string{"-W", "-c", "-f", mainCfg}
+	if c.AuxCfgModTime != 0 {
+		aux, err := ioutil.ReadFile(c.Cfg.Env.AuxCFGFile)
+		if err != nil {
+			return err
+		}
+		auxCfg := filepath.Join(dir, filepath.Base(c.Cfg.Env.AuxCFGFile))
+		if err = ioutil.WriteFile(auxCfg, aux, 0600); err != nil {
+			return err
+		}
+		args = append(args, "-f", auxCfg)
+	}
+	//nolint:gosec //checks on HAProxyBinary should be done in configuration module.
+	cmd := exec.Command(c.Cfg.Env.HAProxyBinary, args...)
+	cmd.Dir = c.Cfg.Env.CfgDir
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("configuration refused by haproxy -c, kept in '%s': %s", dir, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// validateCurrent checks the current HAProxy configuration, see validateStaged
+func (c *HAProxyController) validateCurrent() error {
+	if !c.OSArgs.ValidateConfig {
+		return nil
+	}
+	config, err := ioutil.ReadFile(c.Cfg.Env.MainCFGFile)
+	if err != nil {
+		return err
+	}
+	return c.validateStaged(config)
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AnnotationPolicy is a specification for a AnnotationPolicy resource
+type AnnotationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AnnotationPolicySpec `json:"spec"`
+}
+
+// AnnotationPolicySpec defines the annotations forbidden or bounded
+// in ingresses and services of some namespaces
+type AnnotationPolicySpec struct {
+	Namespaces []string          `json:"namespaces,omitempty"`
+	Forbidden  []string          `json:"forbidden,omitempty"`
+	Limits     []AnnotationLimit `json:"limits,omitempty"`
+}
+
+// AnnotationLimit is the maximum value of an annotation,
+// either a time, a size or an integer
+type AnnotationLimit struct {
+	Annotation string `json:"annotation"`
+	MaxTime    string `json:"maxTime,omitempty"`
+	MaxSize    string `json:"maxSize,omitempty"`
+	MaxValue   *int64 `json:"maxValue,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AnnotationPolicyList is a list of AnnotationPolicy resources
+type AnnotationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []AnnotationPolicy `json:"items"`
+}
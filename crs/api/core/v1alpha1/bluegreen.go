@@ -0,0 +1,62 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BlueGreen is a specification for a BlueGreen resource
+type BlueGreen struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BlueGreenSpec `json:"spec"`
+}
+
+// BlueGreenSpec defines the desired state of BlueGreen
+type BlueGreenSpec struct {
+	Host     string           `json:"host,omitempty"`
+	Path     string           `json:"path,omitempty"`
+	PathType string           `json:"pathType,omitempty"`
+	Blue     BlueGreenService `json:"blue"`
+	Green    BlueGreenService `json:"green"`
+	// Active is the deployment ("blue" or "green") receiving traffic,
+	// promoting the standby deployment is done by switching it.
+	Active string `json:"active"`
+	// StandbyWeight is the percentage of requests sent to the standby deployment.
+	StandbyWeight int64 `json:"standbyWeight,omitempty"`
+}
+
+// BlueGreenService is the service exposing one of the deployments
+type BlueGreenService struct {
+	Name string             `json:"name"`
+	Port intstr.IntOrString `json:"port"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BlueGreenList is a list of BlueGreen resources
+type BlueGreenList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []BlueGreen `json:"items"`
+}
@@ -34,6 +34,9 @@ type Defaults struct {
 // DefaultsSpec defines the desired state of Defaults
 type DefaultsSpec struct {
 	Config *models.Defaults `json:"config"`
+	// Scope is "global" by default, with "namespace" the timeouts, log tag and balance
+	// of the config apply to the backends of the services of the CR namespace.
+	Scope string `json:"scope,omitempty"`
 }
 
 // DeepCopyInto deepcopying  the receiver into out. in must be non-nil.
@@ -0,0 +1,54 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Denylist is a specification for a Denylist resource
+type Denylist struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DenylistSpec `json:"spec"`
+}
+
+// DenylistSpec defines the desired state of Denylist
+type DenylistSpec struct {
+	Entries []DenylistEntry `json:"entries"`
+}
+
+// DenylistEntry is an IP address or CIDR range to deny.
+// When Expires is set the entry is no longer denied after this date,
+// an absolute date so that it survives controller restarts.
+type DenylistEntry struct {
+	Address string       `json:"address"`
+	Expires *metav1.Time `json:"expires,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DenylistList is a list of Denylist resources
+type DenylistList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Denylist `json:"items"`
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 HAProxy Technologies
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficSplit is a specification for a TrafficSplit resource
+type TrafficSplit struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TrafficSplitSpec `json:"spec"`
+}
+
+// TrafficSplitSpec defines the desired state of TrafficSplit
+type TrafficSplitSpec struct {
+	Host     string                `json:"host,omitempty"`
+	Path     string                `json:"path,omitempty"`
+	PathType string                `json:"pathType,omitempty"`
+	Backends []TrafficSplitBackend `json:"backends"`
+}
+
+// TrafficSplitBackend is a service receiving a share of the traffic
+// proportional to its weight
+type TrafficSplitBackend struct {
+	Name   string             `json:"name"`
+	Port   intstr.IntOrString `json:"port"`
+	Weight int64              `json:"weight"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficSplitList is a list of TrafficSplit resources
+type TrafficSplitList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TrafficSplit `json:"items"`
+}
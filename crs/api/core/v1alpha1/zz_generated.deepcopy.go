@@ -23,6 +23,215 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnnotationLimit) DeepCopyInto(out *AnnotationLimit) {
+	*out = *in
+	if in.MaxValue != nil {
+		in, out := &in.MaxValue, &out.MaxValue
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnnotationLimit.
+func (in *AnnotationLimit) DeepCopy() *AnnotationLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(AnnotationLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnnotationPolicy) DeepCopyInto(out *AnnotationPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnnotationPolicy.
+func (in *AnnotationPolicy) DeepCopy() *AnnotationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AnnotationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AnnotationPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnnotationPolicyList) DeepCopyInto(out *AnnotationPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AnnotationPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnnotationPolicyList.
+func (in *AnnotationPolicyList) DeepCopy() *AnnotationPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(AnnotationPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AnnotationPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnnotationPolicySpec) DeepCopyInto(out *AnnotationPolicySpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Forbidden != nil {
+		in, out := &in.Forbidden, &out.Forbidden
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make([]AnnotationLimit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnnotationPolicySpec.
+func (in *AnnotationPolicySpec) DeepCopy() *AnnotationPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AnnotationPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreen) DeepCopyInto(out *BlueGreen) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreen.
+func (in *BlueGreen) DeepCopy() *BlueGreen {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreen)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlueGreen) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreenList) DeepCopyInto(out *BlueGreenList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BlueGreen, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenList.
+func (in *BlueGreenList) DeepCopy() *BlueGreenList {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreenList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlueGreenList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreenService) DeepCopyInto(out *BlueGreenService) {
+	*out = *in
+	out.Port = in.Port
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenService.
+func (in *BlueGreenService) DeepCopy() *BlueGreenService {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreenService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreenSpec) DeepCopyInto(out *BlueGreenSpec) {
+	*out = *in
+	out.Blue = in.Blue
+	out.Green = in.Green
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenSpec.
+func (in *BlueGreenSpec) DeepCopy() *BlueGreenSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreenSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Defaults) DeepCopyInto(out *Defaults) {
 	*out = *in
@@ -93,6 +302,109 @@ func (in *DefaultsSpec) DeepCopy() *DefaultsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Denylist) DeepCopyInto(out *Denylist) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Denylist.
+func (in *Denylist) DeepCopy() *Denylist {
+	if in == nil {
+		return nil
+	}
+	out := new(Denylist)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Denylist) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DenylistEntry) DeepCopyInto(out *DenylistEntry) {
+	*out = *in
+	if in.Expires != nil {
+		in, out := &in.Expires, &out.Expires
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DenylistEntry.
+func (in *DenylistEntry) DeepCopy() *DenylistEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(DenylistEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DenylistList) DeepCopyInto(out *DenylistList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Denylist, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DenylistList.
+func (in *DenylistList) DeepCopy() *DenylistList {
+	if in == nil {
+		return nil
+	}
+	out := new(DenylistList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DenylistList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DenylistSpec) DeepCopyInto(out *DenylistSpec) {
+	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]DenylistEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DenylistSpec.
+func (in *DenylistSpec) DeepCopy() *DenylistSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DenylistSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Global) DeepCopyInto(out *Global) {
 	*out = *in
@@ -162,3 +474,101 @@ func (in *GlobalSpec) DeepCopy() *GlobalSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficSplit) DeepCopyInto(out *TrafficSplit) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficSplit.
+func (in *TrafficSplit) DeepCopy() *TrafficSplit {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficSplit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrafficSplit) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficSplitBackend) DeepCopyInto(out *TrafficSplitBackend) {
+	*out = *in
+	out.Port = in.Port
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficSplitBackend.
+func (in *TrafficSplitBackend) DeepCopy() *TrafficSplitBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficSplitBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficSplitList) DeepCopyInto(out *TrafficSplitList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TrafficSplit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficSplitList.
+func (in *TrafficSplitList) DeepCopy() *TrafficSplitList {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficSplitList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrafficSplitList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficSplitSpec) DeepCopyInto(out *TrafficSplitSpec) {
+	*out = *in
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]TrafficSplitBackend, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficSplitSpec.
+func (in *TrafficSplitSpec) DeepCopy() *TrafficSplitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficSplitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -57,10 +57,18 @@ func init() {
 // Adds the list of known types to Scheme.
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
+		&AnnotationPolicy{},
+		&AnnotationPolicyList{},
+		&BlueGreen{},
+		&BlueGreenList{},
 		&Defaults{},
 		&DefaultsList{},
+		&Denylist{},
+		&DenylistList{},
 		&Global{},
 		&GlobalList{},
+		&TrafficSplit{},
+		&TrafficSplitList{},
 	)
 	// AddToGroupVersion allows the serialization of client types like ListOptions.
 	v1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -0,0 +1,177 @@
+//
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/haproxytech/kubernetes-ingress/crs/api/core/v1alpha1"
+	scheme "github.com/haproxytech/kubernetes-ingress/crs/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// AnnotationPoliciesGetter has a method to return a AnnotationPolicyInterface.
+// A group's client should implement this interface.
+type AnnotationPoliciesGetter interface {
+	AnnotationPolicies(namespace string) AnnotationPolicyInterface
+}
+
+// AnnotationPolicyInterface has methods to work with AnnotationPolicy resources.
+type AnnotationPolicyInterface interface {
+	Create(ctx context.Context, annotationPolicy *v1alpha1.AnnotationPolicy, opts v1.CreateOptions) (*v1alpha1.AnnotationPolicy, error)
+	Update(ctx context.Context, annotationPolicy *v1alpha1.AnnotationPolicy, opts v1.UpdateOptions) (*v1alpha1.AnnotationPolicy, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.AnnotationPolicy, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.AnnotationPolicyList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.AnnotationPolicy, err error)
+	AnnotationPolicyExpansion
+}
+
+// annotationPolicies implements AnnotationPolicyInterface
+type annotationPolicies struct {
+	client rest.Interface
+	ns     string
+}
+
+// newAnnotationPolicies returns a AnnotationPolicies
+func newAnnotationPolicies(c *CoreV1alpha1Client, namespace string) *annotationPolicies {
+	return &annotationPolicies{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the annotationPolicy, and returns the corresponding annotationPolicy object, and an error if there is any.
+func (c *annotationPolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.AnnotationPolicy, err error) {
+	result = &v1alpha1.AnnotationPolicy{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("annotationpolicies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of AnnotationPolicies that match those selectors.
+func (c *annotationPolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.AnnotationPolicyList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.AnnotationPolicyList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("annotationpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested annotationPolicies.
+func (c *annotationPolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("annotationpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a annotationPolicy and creates it.  Returns the server's representation of the annotationPolicy, and an error, if there is any.
+func (c *annotationPolicies) Create(ctx context.Context, annotationPolicy *v1alpha1.AnnotationPolicy, opts v1.CreateOptions) (result *v1alpha1.AnnotationPolicy, err error) {
+	result = &v1alpha1.AnnotationPolicy{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("annotationpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(annotationPolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a annotationPolicy and updates it. Returns the server's representation of the annotationPolicy, and an error, if there is any.
+func (c *annotationPolicies) Update(ctx context.Context, annotationPolicy *v1alpha1.AnnotationPolicy, opts v1.UpdateOptions) (result *v1alpha1.AnnotationPolicy, err error) {
+	result = &v1alpha1.AnnotationPolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("annotationpolicies").
+		Name(annotationPolicy.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(annotationPolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the annotationPolicy and deletes it. Returns an error if one occurs.
+func (c *annotationPolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("annotationpolicies").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *annotationPolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("annotationpolicies").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched annotationPolicy.
+func (c *annotationPolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.AnnotationPolicy, err error) {
+	result = &v1alpha1.AnnotationPolicy{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("annotationpolicies").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -25,8 +25,12 @@ import (
 
 type CoreV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	AnnotationPoliciesGetter
+	BlueGreensGetter
 	DefaultsGetter
+	DenylistsGetter
 	GlobalsGetter
+	TrafficSplitsGetter
 }
 
 // CoreV1alpha1Client is used to interact with features provided by the core.haproxy.org group.
@@ -34,14 +38,30 @@ type CoreV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *CoreV1alpha1Client) AnnotationPolicies(namespace string) AnnotationPolicyInterface {
+	return newAnnotationPolicies(c, namespace)
+}
+
+func (c *CoreV1alpha1Client) BlueGreens(namespace string) BlueGreenInterface {
+	return newBlueGreens(c, namespace)
+}
+
 func (c *CoreV1alpha1Client) Defaults(namespace string) DefaultsInterface {
 	return newDefaults(c, namespace)
 }
 
+func (c *CoreV1alpha1Client) Denylists(namespace string) DenylistInterface {
+	return newDenylists(c, namespace)
+}
+
 func (c *CoreV1alpha1Client) Globals(namespace string) GlobalInterface {
 	return newGlobals(c, namespace)
 }
 
+func (c *CoreV1alpha1Client) TrafficSplits(namespace string) TrafficSplitInterface {
+	return newTrafficSplits(c, namespace)
+}
+
 // NewForConfig creates a new CoreV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*CoreV1alpha1Client, error) {
 	config := *c
@@ -0,0 +1,177 @@
+//
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/haproxytech/kubernetes-ingress/crs/api/core/v1alpha1"
+	scheme "github.com/haproxytech/kubernetes-ingress/crs/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// DenylistsGetter has a method to return a DenylistInterface.
+// A group's client should implement this interface.
+type DenylistsGetter interface {
+	Denylists(namespace string) DenylistInterface
+}
+
+// DenylistInterface has methods to work with Denylist resources.
+type DenylistInterface interface {
+	Create(ctx context.Context, denylist *v1alpha1.Denylist, opts v1.CreateOptions) (*v1alpha1.Denylist, error)
+	Update(ctx context.Context, denylist *v1alpha1.Denylist, opts v1.UpdateOptions) (*v1alpha1.Denylist, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.Denylist, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.DenylistList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.Denylist, err error)
+	DenylistExpansion
+}
+
+// denylists implements DenylistInterface
+type denylists struct {
+	client rest.Interface
+	ns     string
+}
+
+// newDenylists returns a Denylists
+func newDenylists(c *CoreV1alpha1Client, namespace string) *denylists {
+	return &denylists{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the denylist, and returns the corresponding denylist object, and an error if there is any.
+func (c *denylists) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.Denylist, err error) {
+	result = &v1alpha1.Denylist{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("denylists").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of Denylists that match those selectors.
+func (c *denylists) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.DenylistList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.DenylistList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("denylists").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested denylists.
+func (c *denylists) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("denylists").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a denylist and creates it.  Returns the server's representation of the denylist, and an error, if there is any.
+func (c *denylists) Create(ctx context.Context, denylist *v1alpha1.Denylist, opts v1.CreateOptions) (result *v1alpha1.Denylist, err error) {
+	result = &v1alpha1.Denylist{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("denylists").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(denylist).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a denylist and updates it. Returns the server's representation of the denylist, and an error, if there is any.
+func (c *denylists) Update(ctx context.Context, denylist *v1alpha1.Denylist, opts v1.UpdateOptions) (result *v1alpha1.Denylist, err error) {
+	result = &v1alpha1.Denylist{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("denylists").
+		Name(denylist.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(denylist).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the denylist and deletes it. Returns an error if one occurs.
+func (c *denylists) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("denylists").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *denylists) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("denylists").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched denylist.
+func (c *denylists) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.Denylist, err error) {
+	result = &v1alpha1.Denylist{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("denylists").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
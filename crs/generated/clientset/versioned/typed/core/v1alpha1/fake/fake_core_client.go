@@ -27,14 +27,30 @@ type FakeCoreV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeCoreV1alpha1) AnnotationPolicies(namespace string) v1alpha1.AnnotationPolicyInterface {
+	return &FakeAnnotationPolicies{c, namespace}
+}
+
+func (c *FakeCoreV1alpha1) BlueGreens(namespace string) v1alpha1.BlueGreenInterface {
+	return &FakeBlueGreens{c, namespace}
+}
+
 func (c *FakeCoreV1alpha1) Defaults(namespace string) v1alpha1.DefaultsInterface {
 	return &FakeDefaults{c, namespace}
 }
 
+func (c *FakeCoreV1alpha1) Denylists(namespace string) v1alpha1.DenylistInterface {
+	return &FakeDenylists{c, namespace}
+}
+
 func (c *FakeCoreV1alpha1) Globals(namespace string) v1alpha1.GlobalInterface {
 	return &FakeGlobals{c, namespace}
 }
 
+func (c *FakeCoreV1alpha1) TrafficSplits(namespace string) v1alpha1.TrafficSplitInterface {
+	return &FakeTrafficSplits{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeCoreV1alpha1) RESTClient() rest.Interface {
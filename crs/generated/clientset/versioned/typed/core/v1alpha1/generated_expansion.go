@@ -17,6 +17,14 @@
 
 package v1alpha1
 
+type AnnotationPolicyExpansion interface{}
+
+type BlueGreenExpansion interface{}
+
 type DefaultsExpansion interface{}
 
+type DenylistExpansion interface{}
+
 type GlobalExpansion interface{}
+
+type TrafficSplitExpansion interface{}
@@ -23,10 +23,18 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// AnnotationPolicies returns a AnnotationPolicyInformer.
+	AnnotationPolicies() AnnotationPolicyInformer
+	// BlueGreens returns a BlueGreenInformer.
+	BlueGreens() BlueGreenInformer
 	// Defaults returns a DefaultsInformer.
 	Defaults() DefaultsInformer
+	// Denylists returns a DenylistInformer.
+	Denylists() DenylistInformer
 	// Globals returns a GlobalInformer.
 	Globals() GlobalInformer
+	// TrafficSplits returns a TrafficSplitInformer.
+	TrafficSplits() TrafficSplitInformer
 }
 
 type version struct {
@@ -40,12 +48,32 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// AnnotationPolicies returns a AnnotationPolicyInformer.
+func (v *version) AnnotationPolicies() AnnotationPolicyInformer {
+	return &annotationPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// BlueGreens returns a BlueGreenInformer.
+func (v *version) BlueGreens() BlueGreenInformer {
+	return &blueGreenInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Defaults returns a DefaultsInformer.
 func (v *version) Defaults() DefaultsInformer {
 	return &defaultsInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// Denylists returns a DenylistInformer.
+func (v *version) Denylists() DenylistInformer {
+	return &denylistInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Globals returns a GlobalInformer.
 func (v *version) Globals() GlobalInformer {
 	return &globalInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// TrafficSplits returns a TrafficSplitInformer.
+func (v *version) TrafficSplits() TrafficSplitInformer {
+	return &trafficSplitInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,98 @@
+//
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/haproxytech/kubernetes-ingress/crs/api/core/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// AnnotationPolicyLister helps list AnnotationPolicies.
+// All objects returned here must be treated as read-only.
+type AnnotationPolicyLister interface {
+	// List lists all AnnotationPolicies in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.AnnotationPolicy, err error)
+	// AnnotationPolicies returns an object that can list and get AnnotationPolicies.
+	AnnotationPolicies(namespace string) AnnotationPolicyNamespaceLister
+	AnnotationPolicyListerExpansion
+}
+
+// annotationPolicyLister implements the AnnotationPolicyLister interface.
+type annotationPolicyLister struct {
+	indexer cache.Indexer
+}
+
+// NewAnnotationPolicyLister returns a new AnnotationPolicyLister.
+func NewAnnotationPolicyLister(indexer cache.Indexer) AnnotationPolicyLister {
+	return &annotationPolicyLister{indexer: indexer}
+}
+
+// List lists all AnnotationPolicies in the indexer.
+func (s *annotationPolicyLister) List(selector labels.Selector) (ret []*v1alpha1.AnnotationPolicy, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.AnnotationPolicy))
+	})
+	return ret, err
+}
+
+// AnnotationPolicies returns an object that can list and get AnnotationPolicies.
+func (s *annotationPolicyLister) AnnotationPolicies(namespace string) AnnotationPolicyNamespaceLister {
+	return annotationPolicyNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// AnnotationPolicyNamespaceLister helps list and get AnnotationPolicies.
+// All objects returned here must be treated as read-only.
+type AnnotationPolicyNamespaceLister interface {
+	// List lists all AnnotationPolicies in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.AnnotationPolicy, err error)
+	// Get retrieves the AnnotationPolicy from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.AnnotationPolicy, error)
+	AnnotationPolicyNamespaceListerExpansion
+}
+
+// annotationPolicyNamespaceLister implements the AnnotationPolicyNamespaceLister
+// interface.
+type annotationPolicyNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all AnnotationPolicies in the indexer for a given namespace.
+func (s annotationPolicyNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.AnnotationPolicy, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.AnnotationPolicy))
+	})
+	return ret, err
+}
+
+// Get retrieves the AnnotationPolicy from the indexer for a given namespace and name.
+func (s annotationPolicyNamespaceLister) Get(name string) (*v1alpha1.AnnotationPolicy, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("annotationPolicy"), name)
+	}
+	return obj.(*v1alpha1.AnnotationPolicy), nil
+}
@@ -0,0 +1,98 @@
+//
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/haproxytech/kubernetes-ingress/crs/api/core/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DenylistLister helps list Denylists.
+// All objects returned here must be treated as read-only.
+type DenylistLister interface {
+	// List lists all Denylists in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.Denylist, err error)
+	// Denylists returns an object that can list and get Denylists.
+	Denylists(namespace string) DenylistNamespaceLister
+	DenylistListerExpansion
+}
+
+// denylistLister implements the DenylistLister interface.
+type denylistLister struct {
+	indexer cache.Indexer
+}
+
+// NewDenylistLister returns a new DenylistLister.
+func NewDenylistLister(indexer cache.Indexer) DenylistLister {
+	return &denylistLister{indexer: indexer}
+}
+
+// List lists all Denylists in the indexer.
+func (s *denylistLister) List(selector labels.Selector) (ret []*v1alpha1.Denylist, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.Denylist))
+	})
+	return ret, err
+}
+
+// Denylists returns an object that can list and get Denylists.
+func (s *denylistLister) Denylists(namespace string) DenylistNamespaceLister {
+	return denylistNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// DenylistNamespaceLister helps list and get Denylists.
+// All objects returned here must be treated as read-only.
+type DenylistNamespaceLister interface {
+	// List lists all Denylists in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.Denylist, err error)
+	// Get retrieves the Denylist from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.Denylist, error)
+	DenylistNamespaceListerExpansion
+}
+
+// denylistNamespaceLister implements the DenylistNamespaceLister
+// interface.
+type denylistNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all Denylists in the indexer for a given namespace.
+func (s denylistNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.Denylist, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.Denylist))
+	})
+	return ret, err
+}
+
+// Get retrieves the Denylist from the indexer for a given namespace and name.
+func (s denylistNamespaceLister) Get(name string) (*v1alpha1.Denylist, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("denylist"), name)
+	}
+	return obj.(*v1alpha1.Denylist), nil
+}
@@ -17,6 +17,22 @@
 
 package v1alpha1
 
+// AnnotationPolicyListerExpansion allows custom methods to be added to
+// AnnotationPolicyLister.
+type AnnotationPolicyListerExpansion interface{}
+
+// AnnotationPolicyNamespaceListerExpansion allows custom methods to be added to
+// AnnotationPolicyNamespaceLister.
+type AnnotationPolicyNamespaceListerExpansion interface{}
+
+// BlueGreenListerExpansion allows custom methods to be added to
+// BlueGreenLister.
+type BlueGreenListerExpansion interface{}
+
+// BlueGreenNamespaceListerExpansion allows custom methods to be added to
+// BlueGreenNamespaceLister.
+type BlueGreenNamespaceListerExpansion interface{}
+
 // DefaultsListerExpansion allows custom methods to be added to
 // DefaultsLister.
 type DefaultsListerExpansion interface{}
@@ -25,6 +41,14 @@ type DefaultsListerExpansion interface{}
 // DefaultsNamespaceLister.
 type DefaultsNamespaceListerExpansion interface{}
 
+// DenylistListerExpansion allows custom methods to be added to
+// DenylistLister.
+type DenylistListerExpansion interface{}
+
+// DenylistNamespaceListerExpansion allows custom methods to be added to
+// DenylistNamespaceLister.
+type DenylistNamespaceListerExpansion interface{}
+
 // GlobalListerExpansion allows custom methods to be added to
 // GlobalLister.
 type GlobalListerExpansion interface{}
@@ -32,3 +56,11 @@ type GlobalListerExpansion interface{}
 // GlobalNamespaceListerExpansion allows custom methods to be added to
 // GlobalNamespaceLister.
 type GlobalNamespaceListerExpansion interface{}
+
+// TrafficSplitListerExpansion allows custom methods to be added to
+// TrafficSplitLister.
+type TrafficSplitListerExpansion interface{}
+
+// TrafficSplitNamespaceListerExpansion allows custom methods to be added to
+// TrafficSplitNamespaceLister.
+type TrafficSplitNamespaceListerExpansion interface{}
@@ -33,6 +33,8 @@ fi
 
 echo "deploying Ingress Controller ..."
 kubectl apply -f $DIR/config/0.namespace.yaml
+kubectl apply -f $DIR/../../crs/definition/
+kubectl wait --for condition=established --timeout=60s -f $DIR/../../crs/definition/
 kubectl apply -f $DIR/config/1.default-backend.yaml
 kubectl apply -f $DIR/config/2.rbac.yaml
 kubectl apply -f $DIR/config/3.configmap.yaml
@@ -0,0 +1,58 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build e2e_parallel
+
+package basicauth
+
+import (
+	"net/http"
+
+	"github.com/haproxytech/kubernetes-ingress/deploy/tests/e2e"
+)
+
+func (suite *HTTPBasicAuthSuite) Test_AuthExcludePaths() {
+	suite.tmplData.AuthExcludePaths = "/healthz, /webhooks/"
+	defer func() {
+		suite.tmplData.AuthExcludePaths = ""
+		suite.client.Path = ""
+	}()
+	suite.NoError(suite.test.DeployYamlTemplate("config/deploy.yaml.tmpl", suite.test.GetNS(), suite.tmplData))
+	suite.client.Req.Header = map[string][]string{}
+	suite.Require().Eventually(func() bool {
+		suite.client.Path = "/healthz"
+		res, cls, err := suite.client.Do()
+		if res == nil {
+			suite.T().Log(err)
+			return false
+		}
+		defer cls()
+		return res.StatusCode == http.StatusOK
+	}, e2e.WaitDuration, e2e.TickDuration)
+	for path, statusCode := range map[string]int{
+		"/webhooks/github":   http.StatusOK,
+		"/":                  http.StatusUnauthorized,
+		"/healthz/admin":     http.StatusUnauthorized,
+		"/webhooks":          http.StatusUnauthorized,
+		"/webhooks/../admin": http.StatusBadRequest,
+	} {
+		suite.Run(path, func() {
+			suite.client.Path = path
+			res, cls, err := suite.client.Do()
+			suite.Require().NoError(err)
+			defer cls()
+			suite.Equal(statusCode, res.StatusCode)
+		})
+	}
+}
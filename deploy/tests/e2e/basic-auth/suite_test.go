@@ -32,7 +32,8 @@ type HTTPBasicAuthSuite struct {
 }
 
 type tmplData struct {
-	Host string
+	Host             string
+	AuthExcludePaths string
 }
 
 func (suite *HTTPBasicAuthSuite) SetupSuite() {
@@ -0,0 +1,70 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+// +build e2e_parallel
+
+package denylist
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/haproxytech/kubernetes-ingress/deploy/tests/e2e"
+)
+
+// statusCode returns the status code of a request sent from the ip address, via the X-Client-IP header
+func (suite *DenylistSuite) statusCode(ip string) int {
+	suite.client.Req.Header = map[string][]string{
+		"X-Client-IP": {ip},
+	}
+	res, cls, err := suite.client.Do()
+	if err != nil {
+		suite.FailNow(err.Error())
+	}
+	defer cls()
+	return res.StatusCode
+}
+
+func (suite *DenylistSuite) Test_Denylist() {
+	// addresses are from the documentation ranges
+	suite.tmplData.Entries = []entry{
+		{Address: "192.0.2.1"},
+		{Address: "198.51.100.0/24"},
+		{Address: "192.0.2.2", Expires: time.Now().Add(30 * time.Second).UTC().Format(time.RFC3339)},
+	}
+	suite.Require().NoError(suite.test.DeployYamlTemplate("config/denylist.yaml.tmpl", controllerNS, suite.tmplData))
+	// Denylists of other namespaces are ignored
+	suite.tmplData.Entries = []entry{{Address: "192.0.2.3"}}
+	suite.Require().NoError(suite.test.DeployYamlTemplate("config/denylist.yaml.tmpl", suite.test.GetNS(), suite.tmplData))
+
+	suite.Run("Denied", func() {
+		for _, ip := range []string{"192.0.2.1", "198.51.100.7", "192.0.2.2"} {
+			suite.Eventually(func() bool {
+				return suite.statusCode(ip) == http.StatusForbidden
+			}, e2e.WaitDuration, e2e.TickDuration, ip)
+		}
+	})
+	suite.Run("Allowed", func() {
+		for _, ip := range []string{"192.0.2.10", "192.0.2.3"} {
+			suite.Equal(http.StatusOK, suite.statusCode(ip), ip)
+		}
+	})
+	suite.Run("Expired", func() {
+		suite.Eventually(func() bool {
+			return suite.statusCode("192.0.2.2") == http.StatusOK
+		}, e2e.WaitDuration, e2e.TickDuration)
+		suite.Equal(http.StatusForbidden, suite.statusCode("192.0.2.1"))
+	})
+}
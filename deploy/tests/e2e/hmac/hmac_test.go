@@ -0,0 +1,90 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+// +build e2e_parallel
+
+package hmac
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // sha1 signatures are accepted with hmac-algorithm
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/haproxytech/kubernetes-ingress/deploy/tests/e2e"
+)
+
+// key is the "secret" key of the webhook Secret
+const key = "e2e-webhook-secret"
+
+const payload = `{"action":"opened","number":1}`
+
+// post sends the payload with the signature in the header, no header is sent when signature is empty
+func (suite *HMACSuite) post(header, signature string) int {
+	suite.client.Req.Method = http.MethodPost
+	suite.client.Req.Body = ioutil.NopCloser(strings.NewReader(payload))
+	suite.client.Req.ContentLength = int64(len(payload))
+	suite.client.Req.Header = map[string][]string{}
+	if signature != "" {
+		suite.client.Req.Header.Set(header, signature)
+	}
+	res, cls, err := suite.client.Do()
+	if err != nil {
+		suite.FailNow(err.Error())
+	}
+	defer cls()
+	return res.StatusCode
+}
+
+func sign(h func() hash.Hash, data string) string {
+	mac := hmac.New(h, []byte(key))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (suite *HMACSuite) Test_HMAC() {
+	for testName, tc := range map[string]struct {
+		annotations []struct{ Key, Value string }
+		header      string
+		signature   string
+	}{
+		"github": {
+			header:    "X-Hub-Signature-256",
+			signature: "sha256=" + sign(sha256.New, payload),
+		},
+		"sha1": {
+			annotations: []struct{ Key, Value string }{
+				{"hmac-algorithm", "sha1"},
+				{"hmac-header", "X-Signature"},
+			},
+			header:    "X-Signature",
+			signature: sign(sha1.New, payload),
+		},
+	} {
+		suite.Run(testName, func() {
+			suite.tmplData.IngAnnotations = tc.annotations
+			suite.Require().NoError(suite.test.DeployYamlTemplate("config/deploy.yaml.tmpl", suite.test.GetNS(), suite.tmplData))
+			suite.Eventually(func() bool {
+				return suite.post(tc.header, tc.signature) == http.StatusOK
+			}, e2e.WaitDuration, e2e.TickDuration)
+			suite.Equal(http.StatusUnauthorized, suite.post(tc.header, ""), "missing signature")
+			suite.Equal(http.StatusUnauthorized, suite.post(tc.header, sign(sha256.New, payload+" ")), "forged signature")
+		})
+	}
+}
@@ -18,6 +18,7 @@ package ratelimiting
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/haproxytech/kubernetes-ingress/deploy/tests/e2e"
@@ -64,3 +65,48 @@ func (suite *RateLimitingSuite) Test_Rate_Limiting() {
 		})
 	}
 }
+
+func (suite *RateLimitingSuite) Test_Rate_Limiting_Key() {
+	suite.tmplData.Host = "apikey.test"
+	suite.tmplData.IngAnnotations = []struct{ Key, Value string }{
+		{"rate-limit-period", "10s"},
+		{"rate-limit-requests", "2"},
+		{"rate-limit-status-code", "429"},
+		{"rate-limit-key", "req.hdr(X-Api-Key)"},
+	}
+	suite.Require().NoError(suite.test.DeployYamlTemplate("config/ingress.yaml.tmpl", suite.test.GetNS(), suite.tmplData))
+	suite.client.Host = suite.tmplData.Host
+	suite.Run("Limited", func() {
+		var attempt int
+		suite.Require().Eventually(func() bool {
+			var counter int
+			// a new key for each attempt, requests of failed attempts are counted too
+			attempt++
+			suite.client.Req.Header = map[string][]string{
+				"X-Api-Key": {fmt.Sprintf("key-%d", attempt)},
+			}
+			for {
+				res, cls, err := suite.client.Do()
+				if err != nil {
+					suite.FailNow(err.Error())
+				}
+				cls()
+				if res.StatusCode != http.StatusOK {
+					return res.StatusCode == http.StatusTooManyRequests &&
+						res.Header.Get("Retry-After") == "10" &&
+						counter == 2
+				}
+				counter++
+			}
+		}, e2e.WaitDuration, e2e.TickDuration)
+	})
+	suite.Run("OtherKey", func() {
+		suite.client.Req.Header = map[string][]string{
+			"X-Api-Key": {"key-other"},
+		}
+		res, cls, err := suite.client.Do()
+		suite.Require().NoError(err)
+		defer cls()
+		suite.Equal(http.StatusOK, res.StatusCode)
+	})
+}
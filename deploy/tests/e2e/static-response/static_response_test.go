@@ -0,0 +1,75 @@
+// Copyright 2019 HAProxy Technologies LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+// +build e2e_parallel
+
+package staticresponse
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/haproxytech/kubernetes-ingress/deploy/tests/e2e"
+)
+
+func (suite *StaticResponseSuite) Test_Static_Response() {
+	for testName, tc := range map[string]struct {
+		annotation  string
+		statusCode  int
+		contentType string
+		body        string
+	}{
+		"body":        {`200 text/plain\nUser-agent: *\nDisallow: /`, http.StatusOK, "text/plain", "User-agent: *\nDisallow: /"},
+		"contenttype": {`503 text/html\n<h1>Maintenance</h1>`, http.StatusServiceUnavailable, "text/html", "<h1>Maintenance</h1>"},
+		"nobody":      {`204`, http.StatusNoContent, "", ""},
+	} {
+		suite.Run(testName, func() {
+			suite.tmplData.StaticResponse = tc.annotation
+			suite.Require().NoError(suite.test.DeployYamlTemplate("config/deploy.yaml.tmpl", suite.test.GetNS(), suite.tmplData))
+			suite.Eventually(func() bool {
+				res, cls, err := suite.client.Do()
+				if err != nil {
+					suite.FailNow(err.Error())
+				}
+				defer cls()
+				body, err := ioutil.ReadAll(res.Body)
+				if err != nil {
+					return false
+				}
+				return res.StatusCode == tc.statusCode &&
+					res.Header.Get("Content-Type") == tc.contentType &&
+					string(body) == tc.body
+			}, e2e.WaitDuration, e2e.TickDuration)
+		})
+	}
+}
+
+func (suite *StaticResponseSuite) Test_Static_Response_File_Refused() {
+	// files out of the pattern files directory are not served, requests reach the service
+	suite.tmplData.StaticResponse = "200 text/plain patterns/../../etc/passwd"
+	suite.Require().NoError(suite.test.DeployYamlTemplate("config/deploy.yaml.tmpl", suite.test.GetNS(), suite.tmplData))
+	suite.Eventually(func() bool {
+		res, cls, err := suite.client.Do()
+		if err != nil {
+			suite.FailNow(err.Error())
+		}
+		defer cls()
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return false
+		}
+		return res.StatusCode == http.StatusOK && len(body) != 0 && string(body) != "User-agent: *\nDisallow: /"
+	}, e2e.WaitDuration, e2e.TickDuration)
+}
@@ -24,6 +24,10 @@ This is autogenerated from [doc.yaml](doc.yaml). Description can be found in [ge
 >
 > Example: ` + "haproxy.com/ssl-redirect` and `haproxy.org/ssl-redirect`" + ` are same annotation
 
+> :information_source: Ingress and service annotation values, including the ConfigMap values applied to them, can reference ` + "`$NAMESPACE`, `$INGRESS_NAME`, `$SERVICE_NAME` (service annotations only) and `${secret:<name>/<key>}`" + `, a key of a secret of the namespace of the ingress or service, so that generic manifests like the ones of Helm charts can set environment specific values. Secret references are only allowed in ` + "`request-set-header` and `response-set-header`" + `, their values are not written to the HAProxy configuration but looked up by HAProxy in a file readable by the controller user only
+>
+> Example: ` + "`request-set-header: X-Tenant $NAMESPACE`, `set-host: $INGRESS_NAME.internal`, `request-set-header: Authorization Bearer ${secret:api-token/token}`" + `; secret values must fit on a single line, an annotation with a reference which cannot be resolved is reported and ignored
+
 | Annotation | Type | Default | Dependencies | Config map | Ingress | Service |
 | - |:-:|:-:|:-:|:-:|:-:|:-:|
 `
@@ -39,6 +43,12 @@ var tableFooter = `
 > - global  annotations can only be used in Configmap
 > - ingress annotations can be used in Ingress and ConfigMap (to configure all ingress resources in use)
 > - service annotations can be used in Service, Ingress (to configure all services used in Ingress) and ConfigMap (to configure all services in use)
+>
+> A distinct backend is generated for each service port referenced by ingresses. Service annotations prefixed by the port name or number, ex: ` + "`haproxy.org/http.timeout-server` or `haproxy.org/8080.timeout-server`" + `, only apply to the backend of that port and override the unprefixed service annotation.
+>
+> Time values are integers with an optional unit: ` + "`ms`, `s`, `m`, `h` or `d`" + `, milliseconds being the default unit. Negative values and values above 24 days (HAProxy limit of 2147483647ms) are rejected.
+>
+> An invalid annotation value is logged and reported by a Warning Event on the resource setting it, ex: ` + "`kubectl describe ingress <name>`" + `. The annotation default value is then used, as if the annotation was not set.
 
 
 ### Options
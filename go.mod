@@ -3,6 +3,7 @@ module github.com/haproxytech/kubernetes-ingress
 go 1.16
 
 require (
+	github.com/go-openapi/strfmt v0.19.5
 	github.com/go-test/deep v1.0.7
 	github.com/google/renameio v1.0.1
 	github.com/haproxytech/client-native/v2 v2.5.1-0.20210920234105-aa3bbe35d9de
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	//nolint:gosec
@@ -40,7 +41,7 @@ var haproxyConf []byte
 func main() {
 	var osArgs utils.OSArgs
 	parser := flags.NewParser(&osArgs, flags.IgnoreUnknown)
-	_, err := parser.Parse()
+	args, err := parser.Parse()
 	exitCode := 0
 	defer func() {
 		if r := recover(); r != nil {
@@ -60,19 +61,21 @@ func main() {
 	defaultCertificate := fmt.Sprint(osArgs.DefaultCertificate)
 
 	if len(osArgs.Version) > 0 {
-		fmt.Printf("HAProxy Ingress Controller %s %s%s", GitTag, GitCommit, GitDirty)
-		fmt.Printf("Build from: %s", GitRepo)
-		fmt.Printf("Build date: %s\n", BuildTime)
-		if len(osArgs.Version) > 1 {
-			fmt.Printf("ConfigMap: %s", osArgs.ConfigMap)
-			fmt.Printf("Ingress class: %s", osArgs.IngressClass)
-			fmt.Printf("Empty Ingress class: %t", osArgs.EmptyIngressClass)
-		}
+		printVersion(osArgs)
 		return
 	}
 
 	if len(osArgs.Help) > 0 && osArgs.Help[0] {
 		parser.WriteHelp(os.Stdout)
+		fmt.Print(commandsHelp)
+		return
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if err = runCommand(osArgs, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
 		return
 	}
 
@@ -84,7 +87,7 @@ func main() {
 	if osArgs.PprofEnabled {
 		logger.Warning("pprof endpoint exposed over https")
 		go func() {
-			logger.Error(http.ListenAndServe("127.0.0.1:6060", nil))
+			logger.Error(http.ListenAndServe(fmt.Sprintf("127.0.0.1:%d", osArgs.PprofPort), nil))
 		}()
 	}
 	logger.Printf("ConfigMap: %s", osArgs.ConfigMap)
@@ -99,6 +102,16 @@ func main() {
 	if !osArgs.DisableHTTPS {
 		logger.Printf("Frontend HTTPS listening on: %s:%d", osArgs.IPV4BindAddr, osArgs.HTTPSBindPort)
 	}
+	if osArgs.InternalIngressClass != "" {
+		logger.Printf("Internal ingress class: %s", osArgs.InternalIngressClass)
+		logger.Printf("Internal publish service: %s", osArgs.InternalPublishService)
+		if !osArgs.DisableHTTP && osArgs.InternalHTTPBindPort != 0 {
+			logger.Printf("Frontend HTTP listening for internal traffic on: %s:%d", osArgs.InternalIPV4BindAddr, osArgs.InternalHTTPBindPort)
+		}
+		if !osArgs.DisableHTTPS && osArgs.InternalHTTPSBindPort != 0 {
+			logger.Printf("Frontend HTTPS listening for internal traffic on: %s:%d", osArgs.InternalIPV4BindAddr, osArgs.InternalHTTPSBindPort)
+		}
+	}
 	if osArgs.DisableHTTP {
 		logger.Printf("Disabling HTTP frontend")
 	}
@@ -120,8 +133,24 @@ func main() {
 	if osArgs.ConfigMapPatternFiles.Name != "" {
 		logger.Printf("Pattern files provided in '%s'", osArgs.ConfigMapPatternFiles)
 	}
+	if osArgs.WildcardCertsNamespace != "" {
+		logger.Printf("Wildcard certificates provided in namespace '%s'", osArgs.WildcardCertsNamespace)
+	}
+	if osArgs.RestrictCrossNsSecrets {
+		logger.Printf("Restricting cross namespace TLS secrets")
+	}
+	if osArgs.AdminPort != 0 {
+		logger.Printf("Admin API listening on port: %d", osArgs.AdminPort)
+	}
 	logger.Debugf("Kubernetes Informers resync period: %s", osArgs.CacheResyncPeriod.String())
+	logger.Debugf("Kubernetes API QPS: %v, burst: %d", osArgs.KubeAPIQPS, osArgs.KubeAPIBurst)
 	logger.Printf("Controller sync period: %s\n", osArgs.SyncPeriod.String())
+	if osArgs.DriftCheckPeriod != 0 {
+		logger.Printf("Drift check period: %s, correction enabled: %t", osArgs.DriftCheckPeriod.String(), osArgs.DriftCorrection)
+	}
+	if osArgs.MaxReloadsPerMinute > 0 {
+		logger.Printf("HAProxy reloads limited to %d per minute", osArgs.MaxReloadsPerMinute)
+	}
 
 	hostname, err := os.Hostname()
 	logger.Error(err)
@@ -139,6 +168,7 @@ func main() {
 	if osArgs.External {
 		cfg = setupHAProxyEnv(osArgs)
 	}
+	setupEnvDirs(osArgs, &cfg.Env)
 	err = renameio.WriteFile(cfg.Env.MainCFGFile, haproxyConf, 0755)
 	if err != nil {
 		logger.Panic(err)
@@ -149,24 +179,66 @@ func main() {
 	logger.Error(os.Chdir(cfg.Env.CfgDir))
 
 	controller := c.HAProxyController{
-		Cfg:    cfg,
-		OSArgs: osArgs,
+		Cfg:     cfg,
+		OSArgs:  osArgs,
+		Version: fmt.Sprintf("%s %s%s", GitTag, GitCommit, GitDirty),
 	}
 	logger.FileName = true
-	// K8s Store
+	controller.Store = newStore(osArgs)
+	controller.Start()
+	signalC := make(chan os.Signal, 1)
+	signal.Notify(signalC, os.Interrupt, syscall.SIGTERM, syscall.SIGUSR1)
+	<-signalC
+	controller.Stop()
+}
+
+// setupEnvDirs sets the HAProxy directories and files provided via arguments, so that with
+// a read-only root filesystem all the files written at runtime can be kept in mounted volumes.
+func setupEnvDirs(osArgs utils.OSArgs, env *config.Env) {
+	if osArgs.RuntimeDir != "" {
+		env.RuntimeDir = osArgs.RuntimeDir
+	}
+	if osArgs.RuntimeSocket != "" {
+		env.RuntimeSocket = osArgs.RuntimeSocket
+	}
+	if osArgs.MasterSocket != "" {
+		env.MasterSocket = osArgs.MasterSocket
+	}
+	if osArgs.StateDir != "" {
+		env.StateDir = osArgs.StateDir
+	}
+	if osArgs.CertDir != "" {
+		env.CertDir = osArgs.CertDir
+	}
+	if osArgs.MapDir != "" {
+		env.MapDir = osArgs.MapDir
+	}
+	if osArgs.TransactionDir != "" {
+		env.TransactionDir = osArgs.TransactionDir
+	}
+}
+
+func printVersion(osArgs utils.OSArgs) {
+	fmt.Printf("HAProxy Ingress Controller %s %s%s", GitTag, GitCommit, GitDirty)
+	fmt.Printf("Build from: %s", GitRepo)
+	fmt.Printf("Build date: %s\n", BuildTime)
+	if len(osArgs.Version) > 1 {
+		fmt.Printf("ConfigMap: %s", osArgs.ConfigMap)
+		fmt.Printf("Ingress class: %s", osArgs.IngressClass)
+		fmt.Printf("Empty Ingress class: %t", osArgs.EmptyIngressClass)
+	}
+}
+
+// newStore returns the K8s store configured from command line arguments
+func newStore(osArgs utils.OSArgs) store.K8s {
 	s := store.NewK8sStore(osArgs)
-	annotations.SetDefaultValue("default-backend-service", defaultBackendSvc)
-	annotations.SetDefaultValue("ssl-certificate", defaultCertificate)
+	annotations.SetDefaultValue("default-backend-service", fmt.Sprint(osArgs.DefaultBackendService))
+	annotations.SetDefaultValue("ssl-certificate", fmt.Sprint(osArgs.DefaultCertificate))
 	for _, namespace := range osArgs.NamespaceWhitelist {
 		s.NamespacesAccess.Whitelist[namespace] = struct{}{}
 	}
 	for _, namespace := range osArgs.NamespaceBlacklist {
 		s.NamespacesAccess.Blacklist[namespace] = struct{}{}
 	}
-	controller.Store = s
-	controller.Start()
-	signalC := make(chan os.Signal, 1)
-	signal.Notify(signalC, os.Interrupt, syscall.SIGTERM, syscall.SIGUSR1)
-	<-signalC
-	controller.Stop()
+	return s
 }